@@ -0,0 +1,53 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// setAbsent 记录启用的类型中添加了 state=absent 的域名
+func (domains *Domains) setAbsent(dnsConf *DnsConfig) {
+	domains.absent = map[string][]*Domain{}
+	if dnsConf.Ipv4.Enable {
+		domains.absent["A"] = absentDomains(domains.Ipv4Domains)
+	}
+	if dnsConf.Ipv6.Enable {
+		domains.absent["AAAA"] = absentDomains(domains.Ipv6Domains)
+	}
+}
+
+// GetAbsentDomains 返回添加了 state=absent 的域名, 这些域名不更新, 存在该类型的记录时删除
+func (domains *Domains) GetAbsentDomains(recordType string) []*Domain {
+	return domains.absent[recordType]
+}
+
+func absentDomains(list []*Domain) (result []*Domain) {
+	for _, domain := range list {
+		if domain.absent {
+			result = append(result, domain)
+		}
+	}
+	return
+}
+
+// filterAbsent 去掉 state=absent 的域名
+func filterAbsent(list []*Domain) (result []*Domain) {
+	for _, domain := range list {
+		if !domain.absent {
+			result = append(result, domain)
+		}
+	}
+	return
+}
+
+// HasAbsentDomains 是否有添加了 state=absent 的域名
+func (dnsConf *DnsConfig) HasAbsentDomains() bool {
+	for _, domainStr := range append(append([]string{}, dnsConf.Ipv4.Domains...), dnsConf.Ipv6.Domains...) {
+		if _, rawQuery, ok := strings.Cut(domainStr, "?"); ok {
+			if query, err := url.ParseQuery(rawQuery); err == nil && query.Get("state") == "absent" {
+				return true
+			}
+		}
+	}
+	return false
+}
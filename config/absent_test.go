@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestAbsentDomains 测试 state=absent 的域名不更新, 只在启用的类型中删除
+func TestAbsentDomains(t *testing.T) {
+	dnsConf := &DnsConfig{}
+	dnsConf.Ipv4.Enable = true
+	dnsConf.Ipv4.ManualIP = "1.2.3.4"
+	dnsConf.Ipv4.Domains = []string{"www.example.com", "old.example.com?state=absent"}
+	dnsConf.Ipv6.Domains = []string{"old.example.com?state=absent"}
+
+	ResetPushedTargets()
+	domains := &Domains{Ipv4Cache: &util.IpCache{}, Ipv6Cache: &util.IpCache{}}
+	domains.GetNewIp(dnsConf)
+
+	absent := domains.GetAbsentDomains("A")
+	if len(absent) != 1 || absent[0].String() != "old.example.com" || absent[0].CustomParams != "" {
+		t.Errorf("期待删除 old.example.com 的A记录, 得到 %v", absent)
+	}
+	if len(domains.GetAbsentDomains("AAAA")) != 0 {
+		t.Error("未启用IPv6时不应删除AAAA记录")
+	}
+	if _, list := domains.GetNewIpResult("A"); len(list) != 1 || list[0].String() != "www.example.com" {
+		t.Errorf("state=absent 的域名不应更新, 得到 %v", list)
+	}
+}
+
+// TestHasAbsentDomains 测试只有添加了 state=absent 的域名时才需要服务商支持删除
+func TestHasAbsentDomains(t *testing.T) {
+	tests := []struct {
+		ipv4     []string
+		ipv6     []string
+		expected bool
+	}{
+		{[]string{"www.example.com"}, nil, false},
+		{[]string{"www.example.com?state=present"}, nil, false},
+		{nil, []string{"www.example.com?missing=delete&state=absent"}, true},
+		{[]string{"old.example.com?state=absent"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		dnsConf := &DnsConfig{}
+		dnsConf.Ipv4.Domains = tt.ipv4
+		dnsConf.Ipv6.Domains = tt.ipv6
+		if got := dnsConf.HasAbsentDomains(); got != tt.expected {
+			t.Errorf("%v %v 期待 %t, 得到 %t", tt.ipv4, tt.ipv6, tt.expected, got)
+		}
+	}
+}
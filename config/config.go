@@ -1,13 +1,11 @@
 package config
 
 import (
+	"context"
 	"errors"
-	"io"
 	"log"
 	"os"
-	"os/exec"
 	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,7 +31,15 @@ type DnsConfig struct {
 		URL          string
 		NetInterface string
 		Cmd          string
-		Domains      []string
+		// ManualIP 手动设置的IP, 不为空时不再获取IP
+		ManualIP string
+		Domains  []string
+		// Transform 更新前转换IP, 如 map:1.2.3.4=10.0.0.4 / offset:1 / host:0.0.0.10/24
+		Transform string
+		// Consensus 同时请求所有接口, 超过半数的接口结果相同时才使用该IP
+		Consensus bool
+		// GraceIP 启动时先发布的IP, 之后立即获取真实的IP并更正, 用于缩短重启后记录不正确的时间
+		GraceIP string
 	}
 	Ipv6 struct {
 		Enable bool
@@ -43,10 +49,88 @@ type DnsConfig struct {
 		NetInterface string
 		Cmd          string
 		Ipv6Reg      string // ipv6匹配正则表达式
-		Domains      []string
+		// ManualIP 手动设置的IP, 不为空时不再获取IP
+		ManualIP string
+		Domains  []string
+		// Transform 更新前转换IP, 如 host:::1234/64 保留获取到的/64前缀
+		Transform string
+		// AllowULA 网卡没有全局IPv6地址时使用ULA(fc00::/7)地址
+		AllowULA bool
+		// FromRA 网卡没有全局IPv6地址时(如PPP/WAN口), 使用路由通告的前缀加上链路本地地址的接口标识, 默认关闭.
+		// 该地址未分配给网卡, 只在上游按此方式分配地址时正确
+		FromRA bool
+		// Select 网卡有多个IPv6地址时的选择方式 first(默认)/lowest/highest, 避免每次选择的地址不同
+		Select string
+		// Consensus 同时请求所有接口, 超过半数的接口结果相同时才使用该IP
+		Consensus bool
+		// GraceIP 启动时先发布的IP, 之后立即获取真实的IP并更正, 用于缩短重启后记录不正确的时间
+		GraceIP string
+	}
+	// Combined 一次请求同时获得IPv4和IPv6, 获取IP方式为 url 时代替 Ipv4.URL/Ipv6.URL
+	Combined struct {
+		URL string
+		// JSON路径如 ip.v4 / data.0.ip, 或 regex:正则表达式(有分组时取第一个分组), 为空时匹配第一个IPv4/IPv6
+		Ipv4Path string
+		Ipv6Path string
 	}
 	DNS DNS
 	TTL string
+	// ipv6LocalOnly 网卡只有链路本地/ULA的IPv6地址, 此时跳过IPv6更新, 不算失败
+	ipv6LocalOnly bool
+	// ipv4NoConsensus/ipv6NoConsensus 本轮接口的结果未达成多数一致
+	ipv4NoConsensus bool
+	ipv6NoConsensus bool
+	// combined 本轮通过 Combined.URL 获得的结果, 保证IPv4/IPv6为同一时刻且只请求一次
+	combined *combinedAddr
+	// ctx 本轮运行的 context, 附加了该配置的 util.HTTPCounter, 见 SetContext
+	ctx context.Context
+	// ExpandSubDomains 以 + 开头的域名同时更新的子域名, 默认 www
+	ExpandSubDomains []string
+	// RecordIdCache 缓存记录ID, 之后直接按ID更新, 不再每次查询记录列表
+	RecordIdCache bool
+	// Retry 阿里云API被限流或返回5xx时最多请求的次数, 默认3
+	Retry int
+	// RetryDelay 第一次重试前等待的毫秒数, 之后每次加倍, 默认1000
+	RetryDelay int
+	// HTTPTimeout 每次请求DNS服务商API的超时秒数, 为0时使用全局的 HTTPTimeout, 目前支持阿里云DNS/ESA
+	HTTPTimeout int
+	// ProviderTTL 未设置TTL时使用服务商的默认TTL, 默认关闭, 使用固定的TTL. 目前支持阿里云ESA(TTL为自动, 按站点的设置)
+	ProviderTTL bool
+	// RecordName 转换记录名称, 如添加前后缀、转为小写
+	RecordName RecordName
+	// Partial 同时启用IPv4/IPv6但只获取到其中一个时的处理方式 keep/delete/skip, 为空时视为失败
+	Partial string
+	// DryRun 只查询记录, 不新增/更新/删除, 只输出将要进行的修改, 目前支持阿里云ESA
+	DryRun bool
+	// Failover 主DNS配置的名称, 设置后本配置为备用配置, 只在主配置本轮更新失败时更新对应的IPv4/IPv6
+	Failover string
+	// VerifyRecord 新增/更新成功后再查询一次记录, 值不是新的IP时视为失败, 多一次API请求, 目前支持阿里云ESA
+	VerifyRecord bool
+	// Callback Callback的成功条件, 都为空时HTTP状态码小于300即成功
+	Callback struct {
+		// SuccessCodes 视为成功的HTTP状态码, 如 [200, 409]
+		SuccessCodes []int
+		// SuccessBody 返回数据需满足的条件: JSON路径=值 如 code=0, 或 regex:正则表达式
+		SuccessBody string
+	}
+	// DomainConcurrency 同时更新的域名数, 默认4, 目前支持阿里云ESA
+	DomainConcurrency int
+	// RecentIP 不再发布最近发布过的IP
+	RecentIP RecentIP
+	// Cleanup 删除同名同类型的重复记录, 只保留更新的那一条, 目前支持阿里云ESA
+	Cleanup struct {
+		// Tag 由ddns-go管理的记录的备注, 新增/更新时写入, 只删除备注相同的记录, 为空时不清理
+		Tag string
+		// Confirm 确认删除, 为 false 时只输出将要删除的记录
+		Confirm bool
+	}
+	// Propagation 更新成功后等待解析生效再发送通知
+	Propagation struct {
+		// Timeout 最多等待的秒数, 超时后通知的结果为 UpdatedPending, 为0时不等待
+		Timeout int
+		// Resolver 查询的DNS服务器, 如 8.8.8.8, 为空时使用根域名的权威DNS服务器
+		Resolver string
+	}
 }
 
 // DNS DNS配置
@@ -57,16 +141,57 @@ type DNS struct {
 	Secret string
 	// ExtParam 扩展参数，用于某些DNS提供商的特殊需求（如Vercel的teamId）
 	ExtParam string
+	// Endpoint API地址, 为空时使用默认地址, 页面中可选择各服务商已知的地址
+	Endpoint string
 }
 
 type Config struct {
 	DnsConf []DnsConfig
 	User
 	Webhook
+	MQTT
 	// 禁止公网访问
 	NotAllowWanAccess bool
 	// 语言
 	Lang string
+	// QPS 按DNS服务商覆盖默认的每秒请求数, 如 cloudflare: 2
+	QPS map[string]float64
+	// Concurrency 同时运行的DNS服务商个数, 同一服务商的配置仍按顺序运行, 为0或1时全部按顺序运行
+	Concurrency int
+	IPOutput
+	// Resolver 解析获取IP的接口和DNS服务商API的DNS服务器, 多个用逗号分隔, 如 tcp://8.8.8.8, 1.1.1.1
+	// 用于内外网解析不同的网络, 启动时生效, 命令行参数 -dns 优先
+	Resolver string
+	// Proxy 请求DNS服务商API和Webhook等使用的代理, 如 socks5://127.0.0.1:1080, 为空时使用环境变量 HTTP_PROXY/HTTPS_PROXY
+	// 获取IP的请求不使用代理, 保存配置后生效
+	Proxy string
+	// ConnectOverride 按域名覆盖连接的地址, 如 alidns.aliyuncs.com: 10.0.0.1:8443
+	// 用于经过内网网关访问API, 请求的 Host/TLS SNI 与签名仍使用原域名
+	ConnectOverride map[string]string
+	// TriggerDebounce 外部触发(SIGUSR1 或 /trigger 接口)后等待的秒数, 期间再次触发则重新计时, 默认5秒
+	TriggerDebounce int
+	LogFile
+	// LogRepeatWindow 相同的日志在该秒数内只输出一次, 之后再输出时附带重复的次数, 为0时不合并
+	LogRepeatWindow int
+	// IPRevertWindow IP改变为该小时数内使用过的IP时记录日志, Webhook的事件为 reverted, 为0时不检查
+	IPRevertWindow int
+	// ExcludeInterfaces 自动选择网卡时跳过的网卡名或通配符(如 br-*), 在 DefaultExcludeInterfaces 之外增加, 以 ! 开头时不排除该网卡
+	ExcludeInterfaces []string
+	// HTTPTimeout 每次请求阿里云DNS/ESA API的超时秒数, 默认10, 可在DNS配置中覆盖, 其它服务商固定为30秒
+	HTTPTimeout int
+	PTR
+}
+
+// LogFile 日志写入文件, 启动时生效
+type LogFile struct {
+	// 日志文件路径, 为空时输出到 stdout
+	LogFilePath string
+	// 文件超过该大小(MB)时切分, 默认10
+	LogMaxSize int
+	// 文件写入超过该小时数时切分, 为0时不按时间切分
+	LogRotateHours int
+	// 保留的旧日志文件个数, 默认5
+	LogMaxBackups int
 }
 
 // ConfigCache ConfigCache
@@ -116,6 +241,13 @@ func GetConfigCached() (conf Config, err error) {
 		cache.ConfigSingle.NotAllowWanAccess = true
 	}
 
+	// 读取配置后即在日志中隐藏密钥
+	util.SetLogSecrets(cache.ConfigSingle.GetSecrets())
+	// 保存、恢复或远程配置修改代理后立即生效, 不正确时继续使用之前的代理
+	if err := util.SetProxy(cache.ConfigSingle.Proxy); err != nil {
+		util.Log("异常信息: %s", err)
+	}
+
 	// remove err
 	cache.Err = nil
 	return *cache.ConfigSingle, err
@@ -179,6 +311,7 @@ func (conf *Config) SaveConfig() (err error) {
 		return
 	}
 
+	util.SetLogSecrets(conf.GetSecrets())
 	util.Log("配置文件已保存在: %s", configFilePath)
 
 	// 清空配置缓存
@@ -187,6 +320,38 @@ func (conf *Config) SaveConfig() (err error) {
 	return
 }
 
+// GetSecrets 需要在日志中隐藏的密钥, 包括Webhook和 -config-header 请求头的值, 见 secretFields
+func (conf *Config) GetSecrets() (secrets []string) {
+	for _, dc := range conf.DnsConf {
+		secrets = append(secrets, dc.DNS.Secret)
+	}
+	for _, field := range conf.secretFields() {
+		// 请求头只隐藏值, 见 headerSecrets
+		if field != &conf.WebhookHeaders {
+			secrets = append(secrets, *field)
+		}
+	}
+	secrets = append(secrets, proxyPassword(conf.Proxy))
+	secrets = append(secrets, headerSecrets(util.SplitLines(conf.WebhookHeaders))...)
+	return append(secrets, getRemoteHeaderSecrets()...)
+}
+
+// headerSecrets 请求头 "Name: Value" 的值, 值为 "Bearer xxx" 时同时返回 xxx
+func headerSecrets(headers []string) (secrets []string) {
+	for _, header := range headers {
+		_, value, ok := strings.Cut(header, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		secrets = append(secrets, value)
+		if fields := strings.Fields(value); len(fields) > 1 {
+			secrets = append(secrets, fields[len(fields)-1])
+		}
+	}
+	return
+}
+
 // 重置密码
 func (conf *Config) ResetPassword(newPassword string) {
 	// 初始化语言
@@ -224,6 +389,19 @@ func (conf *Config) CheckPassword(newPassword string) (hashedPwd string, err err
 	return
 }
 
+// SetContext 设置本轮运行的 context
+func (conf *DnsConfig) SetContext(ctx context.Context) {
+	conf.ctx = ctx
+}
+
+// Context 返回本轮运行的 context, 未设置时为 context.Background()
+func (conf *DnsConfig) Context() context.Context {
+	if conf.ctx == nil {
+		return context.Background()
+	}
+	return conf.ctx
+}
+
 func (conf *DnsConfig) getIpv4AddrFromInterface() string {
 	ipv4, _, err := GetNetInterface()
 	if err != nil {
@@ -231,6 +409,13 @@ func (conf *DnsConfig) getIpv4AddrFromInterface() string {
 		return ""
 	}
 
+	if conf.Ipv4.NetInterface == "" {
+		if netInterface, ok := autoNetInterface(ipv4); ok {
+			util.Log("未填写网卡名, 将使用网卡 %s", netInterface.Name)
+			return netInterface.Address[0]
+		}
+	}
+
 	for _, netInterface := range ipv4 {
 		if netInterface.Name == conf.Ipv4.NetInterface && len(netInterface.Address) > 0 {
 			return netInterface.Address[0]
@@ -241,31 +426,31 @@ func (conf *DnsConfig) getIpv4AddrFromInterface() string {
 	return ""
 }
 
-func (conf *DnsConfig) getIpv4AddrFromUrl() string {
+func (conf *DnsConfig) getIpv4AddrFromUrl() (result string, source string) {
 	client := util.CreateNoProxyHTTPClient("tcp4")
-	urls := strings.Split(conf.Ipv4.URL, ",")
+	urls, authority := splitUrls(conf.Ipv4.URL)
+	if conf.Ipv4.Consensus {
+		result, source = fetchConsensus(urls, func(url string) string {
+			result, _ := getIpFromUrl(client, url, Ipv4Reg, "IPv4")
+			return result
+		})
+		conf.ipv4NoConsensus = result == ""
+		return
+	}
+	if authority >= 0 {
+		return fetchWithAuthority(urls, authority, func(url string) string {
+			result, _ := getIpFromUrl(client, url, Ipv4Reg, "IPv4")
+			return result
+		})
+	}
 	for _, url := range urls {
-		url = strings.TrimSpace(url)
-		resp, err := client.Get(url)
+		result, err := getIpFromUrl(client, url, Ipv4Reg, "IPv4")
 		if err != nil {
-			util.Log("通过接口获取IPv4失败! 接口地址: %s", url)
-			util.Log("异常信息: %s", err)
 			continue
 		}
-		defer resp.Body.Close()
-		lr := io.LimitReader(resp.Body, 1024000)
-		body, err := io.ReadAll(lr)
-		if err != nil {
-			util.Log("异常信息: %s", err)
-			continue
-		}
-		result := Ipv4Reg.FindString(string(body))
-		if result == "" {
-			util.Log("获取IPv4结果失败! 接口: %s ,返回值: %s", url, string(body))
-		}
-		return result
+		return result, url
 	}
-	return ""
+	return "", ""
 }
 
 func (conf *DnsConfig) getAddrFromCmd(addrType string) string {
@@ -283,18 +468,7 @@ func (conf *DnsConfig) getAddrFromCmd(addrType string) string {
 		return ""
 	}
 	// run cmd with proper shell
-	var execCmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		execCmd = exec.Command("powershell", "-Command", cmd)
-	} else {
-		// If Bash does not exist, use sh
-		_, err := exec.LookPath("bash")
-		if err != nil {
-			execCmd = exec.Command("sh", "-c", cmd)
-		} else {
-			execCmd = exec.Command("bash", "-c", cmd)
-		}
-	}
+	execCmd := util.ShellCommand(cmd)
 	// run cmd
 	out, err := execCmd.CombinedOutput()
 	if err != nil {
@@ -310,45 +484,67 @@ func (conf *DnsConfig) getAddrFromCmd(addrType string) string {
 	return result
 }
 
-// GetIpv4Addr 获得IPv4地址
-func (conf *DnsConfig) GetIpv4Addr() string {
+// GetIpv4Addr 获得IPv4地址, source 为获取IP的来源, 如 url:https://api.ipify.org
+func (conf *DnsConfig) GetIpv4Addr() (result string, source string) {
 	// 判断从哪里获取IP
 	switch conf.Ipv4.GetType {
 	case "netInterface":
 		// 从网卡获取 IP
-		return conf.getIpv4AddrFromInterface()
+		return conf.getIpv4AddrFromInterface(), "netInterface:" + conf.Ipv4.NetInterface
 	case "url":
 		// 从 URL 获取 IP
-		return conf.getIpv4AddrFromUrl()
+		if conf.Combined.URL != "" {
+			ipv4, _ := conf.getCombinedAddr()
+			return ipv4, "combined:" + conf.Combined.URL
+		}
+		result, url := conf.getIpv4AddrFromUrl()
+		return result, "url:" + url
 	case "cmd":
 		// 从命令行获取 IP
-		return conf.getAddrFromCmd("IPv4")
+		return conf.getAddrFromCmd("IPv4"), "cmd:" + conf.Ipv4.Cmd
 	default:
 		log.Println("IPv4's get IP method is unknown")
-		return "" // unknown type
+		return "", "" // unknown type
 	}
 }
 
 func (conf *DnsConfig) getIpv6AddrFromInterface() string {
+	conf.ipv6LocalOnly = false
 	_, ipv6, err := GetNetInterface()
 	if err != nil {
 		util.Log("从网卡获得IPv6失败")
 		return ""
 	}
 
+	ifaceName := conf.Ipv6.NetInterface
+	switch ifaceName {
+	case DefaultRouteInterface:
+		ifaceName, err = getDefaultRouteIface()
+		if err != nil {
+			util.Log("获取默认路由所在的网卡失败! 异常信息: %s", err)
+			return ""
+		}
+	case "":
+		if netInterface, ok := autoNetInterface(ipv6); ok {
+			util.Log("未填写网卡名, 将使用网卡 %s", netInterface.Name)
+			ifaceName = netInterface.Name
+		}
+	}
+
 	for _, netInterface := range ipv6 {
-		if netInterface.Name == conf.Ipv6.NetInterface && len(netInterface.Address) > 0 {
+		if netInterface.Name == ifaceName && len(netInterface.Address) > 0 {
+			addrs := orderIpv6(netInterface.Address, netInterface.stable, conf.Ipv6.Select)
 			if conf.Ipv6.Ipv6Reg != "" {
 				// 匹配第几个IPv6
 				if match, err := regexp.MatchString("@\\d", conf.Ipv6.Ipv6Reg); err == nil && match {
 					num, err := strconv.Atoi(conf.Ipv6.Ipv6Reg[1:])
 					if err == nil {
 						if num > 0 {
-							if num <= len(netInterface.Address) {
-								return netInterface.Address[num-1]
+							if num <= len(addrs) {
+								return addrs[num-1]
 							}
 							util.Log("未找到第 %d 个IPv6地址! 将使用第一个IPv6地址", num)
-							return netInterface.Address[0]
+							return addrs[0]
 						}
 						util.Log("IPv6匹配表达式 %s 不正确! 最小从1开始", conf.Ipv6.Ipv6Reg)
 						return ""
@@ -356,66 +552,87 @@ func (conf *DnsConfig) getIpv6AddrFromInterface() string {
 				}
 				// 正则表达式匹配
 				util.Log("IPv6将使用正则表达式 %s 进行匹配", conf.Ipv6.Ipv6Reg)
-				for i := 0; i < len(netInterface.Address); i++ {
-					matched, err := regexp.MatchString(conf.Ipv6.Ipv6Reg, netInterface.Address[i])
+				for i := 0; i < len(addrs); i++ {
+					matched, err := regexp.MatchString(conf.Ipv6.Ipv6Reg, addrs[i])
 					if matched && err == nil {
-						util.Log("匹配成功! 匹配到地址: %s", netInterface.Address[i])
-						return netInterface.Address[i]
+						util.Log("匹配成功! 匹配到地址: %s", addrs[i])
+						return addrs[i]
 					}
 				}
 				util.Log("没有匹配到任何一个IPv6地址, 将使用第一个地址")
 			}
-			return netInterface.Address[0]
+			return addrs[0]
 		}
 	}
 
-	util.Log("从网卡中获得IPv6失败! 网卡名: %s", conf.Ipv6.NetInterface)
+	// 网卡没有全局IPv6地址时(如PPP/WAN口), 设置后使用路由通告的前缀
+	if conf.Ipv6.FromRA {
+		if addr, err := getIpv6AddrFromRA(ifaceName); err == nil {
+			util.Log("网卡 %s 未分配全局IPv6地址, 将使用路由通告前缀得到的地址: %s", ifaceName, addr)
+			return addr
+		}
+	}
+
+	ula, localOnly := getLocalIpv6(ifaceName)
+	if conf.Ipv6.AllowULA && len(ula) > 0 {
+		return ula[0]
+	}
+	if localOnly {
+		conf.ipv6LocalOnly = true
+		return ""
+	}
+
+	util.Log("从网卡中获得IPv6失败! 网卡名: %s", ifaceName)
 	return ""
 }
 
-func (conf *DnsConfig) getIpv6AddrFromUrl() string {
+func (conf *DnsConfig) getIpv6AddrFromUrl() (result string, source string) {
 	client := util.CreateNoProxyHTTPClient("tcp6")
-	urls := strings.Split(conf.Ipv6.URL, ",")
+	urls, authority := splitUrls(conf.Ipv6.URL)
+	if conf.Ipv6.Consensus {
+		result, source = fetchConsensus(urls, func(url string) string {
+			result, _ := getIpFromUrl(client, url, Ipv6Reg, "IPv6")
+			return result
+		})
+		conf.ipv6NoConsensus = result == ""
+		return
+	}
+	if authority >= 0 {
+		return fetchWithAuthority(urls, authority, func(url string) string {
+			result, _ := getIpFromUrl(client, url, Ipv6Reg, "IPv6")
+			return result
+		})
+	}
 	for _, url := range urls {
-		url = strings.TrimSpace(url)
-		resp, err := client.Get(url)
+		result, err := getIpFromUrl(client, url, Ipv6Reg, "IPv6")
 		if err != nil {
-			util.Log("通过接口获取IPv6失败! 接口地址: %s", url)
-			util.Log("异常信息: %s", err)
 			continue
 		}
-
-		defer resp.Body.Close()
-		lr := io.LimitReader(resp.Body, 1024000)
-		body, err := io.ReadAll(lr)
-		if err != nil {
-			util.Log("异常信息: %s", err)
-			continue
-		}
-		result := Ipv6Reg.FindString(string(body))
-		if result == "" {
-			util.Log("获取IPv6结果失败! 接口: %s ,返回值: %s", url, result)
-		}
-		return result
+		return result, url
 	}
-	return ""
+	return "", ""
 }
 
-// GetIpv6Addr 获得IPv6地址
-func (conf *DnsConfig) GetIpv6Addr() (result string) {
+// GetIpv6Addr 获得IPv6地址, source 为获取IP的来源, 如 url:https://api.ipify.org
+func (conf *DnsConfig) GetIpv6Addr() (result string, source string) {
 	// 判断从哪里获取IP
 	switch conf.Ipv6.GetType {
 	case "netInterface":
 		// 从网卡获取 IP
-		return conf.getIpv6AddrFromInterface()
+		return conf.getIpv6AddrFromInterface(), "netInterface:" + conf.Ipv6.NetInterface
 	case "url":
 		// 从 URL 获取 IP
-		return conf.getIpv6AddrFromUrl()
+		if conf.Combined.URL != "" {
+			_, ipv6 := conf.getCombinedAddr()
+			return ipv6, "combined:" + conf.Combined.URL
+		}
+		result, url := conf.getIpv6AddrFromUrl()
+		return result, "url:" + url
 	case "cmd":
 		// 从命令行获取 IP
-		return conf.getAddrFromCmd("IPv6")
+		return conf.getAddrFromCmd("IPv6"), "cmd:" + conf.Ipv6.Cmd
 	default:
 		log.Println("IPv6's get IP method is unknown")
-		return "" // unknown type
+		return "", "" // unknown type
 	}
 }
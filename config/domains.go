@@ -1,8 +1,12 @@
 package config
 
 import (
+	"context"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jeessy2/ddns-go/v6/util"
 	"golang.org/x/net/idna"
@@ -17,6 +21,23 @@ type Domains struct {
 	Ipv6Addr    string
 	Ipv6Cache   *util.IpCache
 	Ipv6Domains []*Domain
+	// 获取IP的来源, 如 netInterface:eth0 / url:https://api.ipify.org / cmd:xxx
+	Ipv4Source string
+	Ipv6Source string
+	// IP恢复为之前使用过的值, 见 CheckIpReverted
+	Ipv4Reverted bool
+	Ipv6Reverted bool
+	// DNS服务商名称和帐号(ID/Endpoint), 用于去重
+	provider string
+	account  string
+	// deleteType 需要删除记录的类型 A/AAAA, 见 applyPartial
+	deleteType string
+	// recentIP 见 DnsConfig.RecentIP
+	recentIP RecentIP
+	// absent 启用的类型中 state=absent 的域名, key 为 A/AAAA, 见 GetAbsentDomains
+	absent map[string][]*Domain
+	// ctx 见 DnsConfig.Context
+	ctx context.Context
 }
 
 // Domain 域名实体
@@ -27,6 +48,32 @@ type Domain struct {
 	SubDomain    string
 	CustomParams string
 	UpdateStatus updateStatusType // 更新状态
+	// OldIP 更新前记录的值, 服务商未提供时为空
+	OldIP string
+	// UpdateError 更新失败的原因, 服务商未提供时为空
+	UpdateError string
+	// FailurePermanent 更新失败的原因需要用户处理(如AccessKey错误), 为 false 时为网络/限流等暂时的失败, 下次运行重试
+	FailurePermanent bool
+	// PropagationPending 更新成功但等待解析生效超时, 见 DnsConfig.Propagation
+	PropagationPending bool
+	// ipFamily 只更新的IP类型 4/6, 来自参数 ipfamily, 为空时都更新
+	ipFamily string
+	// healthCheck 发布前检查新IP的端口, 来自参数 healthcheck, 为空时不检查
+	healthCheck string
+	// schedule 允许更新的时间, cron 表达式, 来自参数 cron, 为空时不限制
+	schedule string
+	// updateAll 存在多条同名同类型记录时全部更新, 来自参数 records=all, 默认只更新第一条
+	updateAll bool
+	// forceUpdate IP相同时也更新记录, 来自参数 forceupdate=true
+	forceUpdate bool
+	// deleteMissing 多次未获取到该类型的IP时删除记录, 来自参数 missing=delete, 见 GetDeleteDomains
+	deleteMissing bool
+	// absent 不更新, 存在记录时删除, 来自参数 state=absent
+	absent bool
+	// ipv6Suffix 替换获取到的IPv6地址前缀之后的位, 来自参数 ipv6suffix, 见 Ipv6Addr
+	ipv6Suffix string
+	// ipv6PrefixLen 保留的前缀长度, 来自参数 ipv6prefixlen, 为空时为64
+	ipv6PrefixLen string
 }
 
 // nontransitionalLookup implements the nontransitional processing as specified in
@@ -74,6 +121,16 @@ func (d Domain) GetCustomParams() url.Values {
 	return url.Values{}
 }
 
+// UpdateAllRecords 存在多条同名同类型记录(如轮询的A记录)时是否全部更新为新的IP
+func (d Domain) UpdateAllRecords() bool {
+	return d.updateAll
+}
+
+// ForceUpdate 记录的值与IP相同时是否仍更新, 用于重新写入TTL、备注等被修改的属性
+func (d Domain) ForceUpdate() bool {
+	return d.forceUpdate
+}
+
 // ToASCII converts [Domain] to its ASCII form,
 // using non-transitional process specified in UTS 46.
 //
@@ -84,20 +141,59 @@ func (d Domain) ToASCII() string {
 	return name
 }
 
+// Context 返回所属配置本轮运行的 context
+func (domains *Domains) Context() context.Context {
+	if domains.ctx == nil {
+		return context.Background()
+	}
+	return domains.ctx
+}
+
+// HTTPClient 返回请求DNS服务商的 http.Client, 请求计入所属配置的 util.HTTPCounter
+func (domains *Domains) HTTPClient() *http.Client {
+	return util.CreateCountedHTTPClient(util.HTTPCounterFrom(domains.ctx))
+}
+
 // GetNewIp 接口/网卡/命令获得 ip 并校验用户输入的域名
 func (domains *Domains) GetNewIp(dnsConf *DnsConfig) {
-	domains.Ipv4Domains = checkParseDomains(dnsConf.Ipv4.Domains)
-	domains.Ipv6Domains = checkParseDomains(dnsConf.Ipv6.Domains)
+	domains.provider = dnsConf.DNS.Name
+	domains.account = dnsConf.DNS.ID + "@" + dnsConf.DNS.Endpoint
+	domains.ctx = dnsConf.Context()
+	domains.recentIP = dnsConf.RecentIP
+	// 每轮重新请求 Combined.URL
+	dnsConf.combined = nil
+	dnsConf.ipv4NoConsensus, dnsConf.ipv6NoConsensus = false, false
+	domains.Ipv4Domains = transformRecordName(filterIpFamily(checkParseDomains(expandDomains(dnsConf.Ipv4.Domains, dnsConf.ExpandSubDomains)), "4"), dnsConf.RecordName)
+	domains.Ipv6Domains = transformRecordName(filterIpFamily(checkParseDomains(expandDomains(dnsConf.Ipv6.Domains, dnsConf.ExpandSubDomains)), "6"), dnsConf.RecordName)
+	domains.setAbsent(dnsConf)
+
+	if graceRun.Load() {
+		domains.useGraceIp(dnsConf)
+		return
+	}
 
 	// IPv4
 	if dnsConf.Ipv4.Enable && len(domains.Ipv4Domains) > 0 {
-		ipv4Addr := dnsConf.GetIpv4Addr()
+		var ipv4Addr, source string
+		if dnsConf.Ipv4.ManualIP != "" {
+			// 手动设置的IP, 不再获取IP
+			ipv4Addr, source = dnsConf.Ipv4.ManualIP, "manual"
+			util.Log("IPv4使用手动设置的IP: %s", ipv4Addr)
+		} else {
+			ipv4Addr, source = dnsConf.GetIpv4Addr()
+			ipv4Addr = transformIp(dnsConf.Ipv4.Transform, ipv4Addr)
+		}
 		if ipv4Addr != "" {
 			domains.Ipv4Addr = ipv4Addr
+			domains.Ipv4Source = source
 			domains.Ipv4Cache.TimesFailedIP = 0
 		} else {
 			// 启用IPv4 & 未获取到IP & 填写了域名 & 失败刚好3次，防止偶尔的网络连接失败，并且只发一次
 			domains.Ipv4Cache.TimesFailedIP++
+			// 接口结果不一致时可能被劫持, 立即通知
+			if dnsConf.ipv4NoConsensus && domains.Ipv4Cache.TimesFailedIP < 3 {
+				domains.Ipv4Cache.TimesFailedIP = 3
+			}
 			if domains.Ipv4Cache.TimesFailedIP == 3 {
 				domains.Ipv4Domains[0].UpdateStatus = UpdatedFailed
 			}
@@ -107,13 +203,34 @@ func (domains *Domains) GetNewIp(dnsConf *DnsConfig) {
 
 	// IPv6
 	if dnsConf.Ipv6.Enable && len(domains.Ipv6Domains) > 0 {
-		ipv6Addr := dnsConf.GetIpv6Addr()
+		var ipv6Addr, source string
+		if dnsConf.Ipv6.ManualIP != "" {
+			// 手动设置的IP, 不再获取IP
+			ipv6Addr, source = dnsConf.Ipv6.ManualIP, "manual"
+			util.Log("IPv6使用手动设置的IP: %s", ipv6Addr)
+		} else {
+			ipv6Addr, source = dnsConf.GetIpv6Addr()
+			ipv6Addr = transformIp(dnsConf.Ipv6.Transform, ipv6Addr)
+		}
 		if ipv6Addr != "" {
 			domains.Ipv6Addr = ipv6Addr
+			domains.Ipv6Source = source
+			domains.Ipv6Cache.TimesFailedIP = 0
+			domains.Ipv6Cache.LocalOnly = false
+		} else if dnsConf.ipv6LocalOnly {
+			// 只有链路本地/ULA地址, 不是获取失败, 只在第一次时输出日志
+			if !domains.Ipv6Cache.LocalOnly {
+				util.Log("网卡 %s 只有链路本地/ULA的IPv6地址, 将跳过IPv6更新", dnsConf.Ipv6.NetInterface)
+			}
+			domains.Ipv6Cache.LocalOnly = true
 			domains.Ipv6Cache.TimesFailedIP = 0
 		} else {
 			// 启用IPv6 & 未获取到IP & 填写了域名 & 失败刚好3次，防止偶尔的网络连接失败，并且只发一次
 			domains.Ipv6Cache.TimesFailedIP++
+			// 接口结果不一致时可能被劫持, 立即通知
+			if dnsConf.ipv6NoConsensus && domains.Ipv6Cache.TimesFailedIP < 3 {
+				domains.Ipv6Cache.TimesFailedIP = 3
+			}
 			if domains.Ipv6Cache.TimesFailedIP == 3 {
 				domains.Ipv6Domains[0].UpdateStatus = UpdatedFailed
 			}
@@ -121,6 +238,53 @@ func (domains *Domains) GetNewIp(dnsConf *DnsConfig) {
 		}
 	}
 
+	domains.applyPartial(dnsConf)
+}
+
+// defaultExpandSubDomains 未配置时 + 开头的域名同时更新的子域名
+var defaultExpandSubDomains = []string{"www"}
+
+// expandDomains 展开以 + 开头的域名, 同时更新配置的子域名
+// 如 +example.com?q=1 => [example.com?q=1, www.example.com?q=1], +home:example.com => [home:example.com, www.home:example.com]
+func expandDomains(domainArr []string, subDomains []string) (result []string) {
+	if len(subDomains) == 0 {
+		subDomains = defaultExpandSubDomains
+	}
+	for _, domainStr := range domainArr {
+		domainStr = strings.TrimSpace(domainStr)
+		if !strings.HasPrefix(domainStr, "+") {
+			result = append(result, domainStr)
+			continue
+		}
+
+		domainStr = strings.TrimPrefix(domainStr, "+")
+		result = append(result, domainStr)
+		for _, sub := range subDomains {
+			sub = strings.TrimSpace(sub)
+			if sub == "" {
+				continue
+			}
+			if before, after, ok := strings.Cut(domainStr, ":"); ok {
+				if before != "" {
+					sub += "." + before
+				}
+				result = append(result, sub+":"+after)
+			} else {
+				result = append(result, sub+"."+domainStr)
+			}
+		}
+	}
+	return
+}
+
+// filterIpFamily 去掉通过 ?ipfamily= 指定只更新另一种IP类型的域名
+func filterIpFamily(domains []*Domain, family string) (result []*Domain) {
+	for _, domain := range domains {
+		if domain.ipFamily == "" || domain.ipFamily == family {
+			result = append(result, domain)
+		}
+	}
+	return
 }
 
 // checkParseDomains 校验并解析用户输入的域名
@@ -174,18 +338,119 @@ func checkParseDomains(domainArr []string) (domains []*Domain) {
 				util.Log("域名: %s 解析失败", domainStr)
 				continue
 			}
-			domain.CustomParams = u.Query().Encode()
+			query := u.Query()
+			// ipfamily 不传递给DNS服务商
+			if query.Has("ipfamily") {
+				domain.ipFamily = strings.TrimPrefix(strings.ToLower(query.Get("ipfamily")), "ipv")
+				query.Del("ipfamily")
+			}
+			// healthcheck 不传递给DNS服务商
+			if query.Has("healthcheck") {
+				domain.healthCheck = query.Get("healthcheck")
+				query.Del("healthcheck")
+			}
+			// cron 不传递给DNS服务商
+			if query.Has("cron") {
+				domain.schedule = query.Get("cron")
+				query.Del("cron")
+			}
+			// records 不传递给DNS服务商
+			if query.Has("records") {
+				domain.updateAll = query.Get("records") == "all"
+				query.Del("records")
+			}
+			// forceupdate 不传递给DNS服务商
+			if query.Has("forceupdate") {
+				domain.forceUpdate = query.Get("forceupdate") == "true"
+				query.Del("forceupdate")
+			}
+			// missing 不传递给DNS服务商
+			if query.Has("missing") {
+				domain.deleteMissing = query.Get("missing") == "delete"
+				query.Del("missing")
+			}
+			// state 不传递给DNS服务商
+			if query.Has("state") {
+				domain.absent = query.Get("state") == "absent"
+				query.Del("state")
+			}
+			// ipv6suffix/ipv6prefixlen 不传递给DNS服务商
+			if query.Has("ipv6suffix") {
+				domain.ipv6Suffix = query.Get("ipv6suffix")
+				query.Del("ipv6suffix")
+			}
+			if query.Has("ipv6prefixlen") {
+				domain.ipv6PrefixLen = query.Get("ipv6prefixlen")
+				query.Del("ipv6prefixlen")
+			}
+			domain.CustomParams = query.Encode()
 		}
 		domains = append(domains, domain)
 	}
 	return
 }
 
+// pushedTargets 本轮已推送成功的记录, 同一记录在一轮中只推送一次
+var pushedTargets = struct {
+	sync.Mutex
+	keys map[string]bool
+}{keys: map[string]bool{}}
+
+// ResetPushedTargets 开始新一轮更新前清空已推送的记录
+func ResetPushedTargets() {
+	pushedTargets.Lock()
+	defer pushedTargets.Unlock()
+	pushedTargets.keys = map[string]bool{}
+}
+
+// pushedKey 记录的 (服务商, 帐号, 根域名, 子域名, 类型, IP)
+func (domains *Domains) pushedKey(domain *Domain, recordType string, ipAddr string) string {
+	return strings.Join([]string{domains.provider, domains.account, domain.DomainName, domain.SubDomain, recordType, ipAddr, domain.CustomParams}, "|")
+}
+
+// dedupDomains 过滤本轮已推送成功过的相同记录, 以及列表中重复的域名
+func (domains *Domains) dedupDomains(recordType string, ipAddr string, list []*Domain) (result []*Domain) {
+	pushedTargets.Lock()
+	defer pushedTargets.Unlock()
+
+	seen := map[string]bool{}
+	for _, domain := range list {
+		key := domains.pushedKey(domain, recordType, ipAddr)
+		if pushedTargets.keys[key] || seen[key] {
+			util.Log("域名 %s 的 %s 记录本轮已推送过, 将跳过重复的更新", domain, recordType)
+			continue
+		}
+		seen[key] = true
+		result = append(result, domain)
+	}
+	return
+}
+
+// MarkPushed 记录本轮更新成功或无需更新的域名, 之后相同的记录不再推送
+// 更新失败的域名不记录, 以便其它配置(如故障转移的备用配置)继续推送
+func (domains *Domains) MarkPushed() {
+	pushedTargets.Lock()
+	defer pushedTargets.Unlock()
+
+	mark := func(list []*Domain, recordType string, ipAddr string) {
+		if ipAddr == "" {
+			return
+		}
+		for _, domain := range list {
+			if domain.UpdateStatus == UpdatedSuccess || domain.UpdateStatus == UpdatedNothing {
+				pushedTargets.keys[domains.pushedKey(domain, recordType, ipAddr)] = true
+			}
+		}
+	}
+	mark(domains.Ipv4Domains, "A", domains.Ipv4Addr)
+	mark(domains.Ipv6Domains, "AAAA", domains.Ipv6Addr)
+}
+
 // GetNewIpResult 获得GetNewIp结果
 func (domains *Domains) GetNewIpResult(recordType string) (ipAddr string, retDomains []*Domain) {
 	if recordType == "AAAA" {
 		if domains.Ipv6Cache.Check(domains.Ipv6Addr) {
-			return domains.Ipv6Addr, domains.Ipv6Domains
+			return domains.Ipv6Addr, domains.publishable(recordType, domains.Ipv6Addr, domains.Ipv6Cache, domains.Ipv6Domains)
 		} else {
 			util.Log("IPv6未改变, 将等待 %d 次后与DNS服务商进行比对", domains.Ipv6Cache.Times)
 			return "", domains.Ipv6Domains
@@ -193,9 +458,18 @@ func (domains *Domains) GetNewIpResult(recordType string) (ipAddr string, retDom
 	}
 	// IPv4
 	if domains.Ipv4Cache.Check(domains.Ipv4Addr) {
-		return domains.Ipv4Addr, domains.Ipv4Domains
+		return domains.Ipv4Addr, domains.publishable(recordType, domains.Ipv4Addr, domains.Ipv4Cache, domains.Ipv4Domains)
 	} else {
 		util.Log("IPv4未改变, 将等待 %d 次后与DNS服务商进行比对", domains.Ipv4Cache.Times)
 		return "", domains.Ipv4Domains
 	}
 }
+
+// publishable 去掉本轮已推送过、不在允许更新的时间内、未通过健康检查的域名
+func (domains *Domains) publishable(recordType string, ipAddr string, cache *util.IpCache, list []*Domain) []*Domain {
+	list = filterAbsent(list)
+	list = domains.filterRecentIP(recordType, ipAddr, list, time.Now())
+	list = domains.dedupDomains(recordType, ipAddr, list)
+	list = filterSchedule(ipAddr, cache, list, time.Now())
+	return filterHealthCheck(ipAddr, cache, list)
+}
@@ -1,6 +1,15 @@
 package config
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
 
 // TestToASCII test converts the name of [Domain] to its ASCII form.
 //
@@ -83,3 +92,124 @@ func TestParseDomainArr(t *testing.T) {
 	}
 
 }
+
+// TestDedupDomains 测试同一轮中相同记录更新成功后只推送一次
+func TestDedupDomains(t *testing.T) {
+	ResetPushedTargets()
+	d1 := &Domains{provider: "alidns", account: "a@"}
+	d2 := &Domains{provider: "alidns", account: "a@"}
+	d3 := &Domains{provider: "cloudflare", account: "a@"}
+	d4 := &Domains{provider: "alidns", account: "b@"}
+	list := checkParseDomains([]string{"www.example.com", "www.example.com", "example.com"})
+
+	result := d1.dedupDomains("A", "1.1.1.1", list)
+	if len(result) != 2 {
+		t.Errorf("期待 2 个域名, 得到 %d 个", len(result))
+	}
+	if result := d2.dedupDomains("A", "1.1.1.1", list); len(result) != 2 {
+		t.Errorf("未推送成功时期待 2 个域名, 得到 %d 个", len(result))
+	}
+
+	result[0].UpdateStatus = UpdatedSuccess
+	result[1].UpdateStatus = UpdatedFailed
+	d1.Ipv4Addr, d1.Ipv4Domains = "1.1.1.1", result
+	d1.MarkPushed()
+
+	if result := d2.dedupDomains("A", "1.1.1.1", list); len(result) != 1 || result[0].String() != "example.com" {
+		t.Errorf("相同服务商期待只推送更新失败的 example.com, 得到 %v", result)
+	}
+	if result := d2.dedupDomains("AAAA", "::1", list); len(result) != 2 {
+		t.Errorf("不同记录类型期待 2 个域名, 得到 %d 个", len(result))
+	}
+	if result := d3.dedupDomains("A", "1.1.1.1", list); len(result) != 2 {
+		t.Errorf("不同服务商期待 2 个域名, 得到 %d 个", len(result))
+	}
+	if result := d4.dedupDomains("A", "1.1.1.1", list); len(result) != 2 {
+		t.Errorf("不同帐号期待 2 个域名, 得到 %d 个", len(result))
+	}
+}
+
+// TestExpandDomains 测试 + 开头的域名展开
+func TestExpandDomains(t *testing.T) {
+	domains := []string{"+example.com?Line=oversea", "+home:example.cn.eu.org", "test.example.com"}
+	want := []string{
+		"example.com?Line=oversea", "www.example.com?Line=oversea",
+		"home:example.cn.eu.org", "www.home:example.cn.eu.org",
+		"test.example.com",
+	}
+
+	got := expandDomains(domains, nil)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("期待 %v, 得到 %v", want, got)
+	}
+
+	got = expandDomains([]string{"+:example.com"}, []string{"www", "mail"})
+	want = []string{":example.com", "www:example.com", "mail:example.com"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("期待 %v, 得到 %v", want, got)
+	}
+}
+
+// TestFilterIpFamily 测试 ?ipfamily= 指定只更新的IP类型
+func TestFilterIpFamily(t *testing.T) {
+	domains := checkParseDomains([]string{"ipv6only.example.com?ipfamily=6", "both.example.com", "ipv4only.example.com?ipfamily=ipv4&Line=oversea"})
+
+	if domains[2].CustomParams != "Line=oversea" {
+		t.Errorf("ipfamily 不应传递给服务商, 得到 %s", domains[2].CustomParams)
+	}
+
+	ipv4 := filterIpFamily(domains, "4")
+	if len(ipv4) != 2 || ipv4[0].SubDomain != "both" || ipv4[1].SubDomain != "ipv4only" {
+		t.Errorf("IPv4 期待 both, ipv4only, 得到 %v", ipv4)
+	}
+	ipv6 := filterIpFamily(domains, "6")
+	if len(ipv6) != 2 || ipv6[0].SubDomain != "ipv6only" || ipv6[1].SubDomain != "both" {
+		t.Errorf("IPv6 期待 ipv6only, both, 得到 %v", ipv6)
+	}
+}
+
+// TestUpdateAllRecords 测试 ?records=all 全部更新同名同类型的记录
+func TestUpdateAllRecords(t *testing.T) {
+	domains := checkParseDomains([]string{"rr.example.com?records=all&Line=oversea", "first.example.com?records=first", "www.example.com"})
+
+	if !domains[0].UpdateAllRecords() || domains[0].CustomParams != "Line=oversea" {
+		t.Errorf("records=all 应全部更新且不传递给服务商, 得到 %s", domains[0].CustomParams)
+	}
+	if domains[1].UpdateAllRecords() || domains[2].UpdateAllRecords() {
+		t.Error("默认只更新第一条记录")
+	}
+}
+
+// TestDomainsHTTPClient 测试并发运行的配置的请求只计入各自的 counter
+func TestDomainsHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	counters := []*util.HTTPCounter{{}, {}}
+	var wg sync.WaitGroup
+	for i, counter := range counters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dnsConf := &DnsConfig{}
+			dnsConf.SetContext(util.WithHTTPCounter(context.Background(), counter))
+			domains := &Domains{}
+			domains.GetNewIp(dnsConf)
+			for range i + 1 {
+				resp, err := domains.HTTPClient().Get(server.URL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, counter := range counters {
+		if got := counter.Stats().Requests; got != i+1 {
+			t.Errorf("配置 %d 期待 %d 次请求, 得到 %d 次", i, i+1, got)
+		}
+	}
+}
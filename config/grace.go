@@ -0,0 +1,37 @@
+package config
+
+import (
+	"sync/atomic"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// graceRun 启动后先发布 GraceIP 的一次运行, 此时不获取IP
+var graceRun atomic.Bool
+
+// SetGraceRun 设置本次运行是否只发布 GraceIP
+func SetGraceRun(grace bool) {
+	graceRun.Store(grace)
+}
+
+// HasGraceIP 是否有配置了 GraceIP 的DNS配置
+func (conf *Config) HasGraceIP() bool {
+	for _, dc := range conf.DnsConf {
+		if (dc.Ipv4.Enable && dc.Ipv4.GraceIP != "") || (dc.Ipv6.Enable && dc.Ipv6.GraceIP != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// useGraceIp 启动时使用配置的 GraceIP, 未配置的类型本次不更新, 获取到真实的IP后再更正
+func (domains *Domains) useGraceIp(dnsConf *DnsConfig) {
+	if dnsConf.Ipv4.Enable && len(domains.Ipv4Domains) > 0 && dnsConf.Ipv4.GraceIP != "" {
+		domains.Ipv4Addr, domains.Ipv4Source = dnsConf.Ipv4.GraceIP, "grace"
+		util.Log("启动时IPv4先使用临时IP %s, 获取到IP后将更正", dnsConf.Ipv4.GraceIP)
+	}
+	if dnsConf.Ipv6.Enable && len(domains.Ipv6Domains) > 0 && dnsConf.Ipv6.GraceIP != "" {
+		domains.Ipv6Addr, domains.Ipv6Source = dnsConf.Ipv6.GraceIP, "grace"
+		util.Log("启动时IPv6先使用临时IP %s, 获取到IP后将更正", dnsConf.Ipv6.GraceIP)
+	}
+}
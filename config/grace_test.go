@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestGraceIp 测试启动时只发布配置的 GraceIP
+func TestGraceIp(t *testing.T) {
+	conf := &DnsConfig{}
+	conf.Ipv4.Enable, conf.Ipv6.Enable = true, true
+	conf.Ipv4.GetType, conf.Ipv4.URL = "url", "http://127.0.0.1:0"
+	conf.Ipv4.Domains = []string{"www.example.com"}
+	conf.Ipv6.Domains = []string{"www.example.com"}
+	conf.Ipv4.GraceIP = "1.2.3.4"
+
+	if !(&Config{DnsConf: []DnsConfig{*conf}}).HasGraceIP() {
+		t.Error("配置了 GraceIP 时应返回 true")
+	}
+
+	SetGraceRun(true)
+	defer SetGraceRun(false)
+	domains := &Domains{Ipv4Cache: &util.IpCache{}, Ipv6Cache: &util.IpCache{}}
+	domains.GetNewIp(conf)
+	if domains.Ipv4Addr != "1.2.3.4" || domains.Ipv4Source != "grace" {
+		t.Errorf("IPv4 应使用 GraceIP, 实际为 %s", domains.Ipv4Addr)
+	}
+	if domains.Ipv6Addr != "" || domains.Ipv6Cache.TimesFailedIP != 0 {
+		t.Error("未配置 GraceIP 的IPv6不应更新, 也不视为失败")
+	}
+}
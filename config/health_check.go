@@ -0,0 +1,59 @@
+package config
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// healthCheckTimeout 健康检查的连接超时时间
+const healthCheckTimeout = 5 * time.Second
+
+// healthCheckDial 健康检查, 连接 address 成功即通过
+var healthCheckDial = func(address string) error {
+	conn, err := net.DialTimeout("tcp", address, healthCheckTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// filterHealthCheck 发布前通过 ?healthcheck=端口 检查新IP的该端口能否连接, 去掉未通过的域名
+// 有域名未通过时下次运行重新检查, 不等待IP缓存次数
+func filterHealthCheck(ipAddr string, cache *util.IpCache, domains []*Domain) (result []*Domain) {
+	if ipAddr == "" {
+		return domains
+	}
+
+	// 相同端口只检查一次
+	checked := map[string]error{}
+	for _, domain := range domains {
+		if domain.healthCheck == "" {
+			result = append(result, domain)
+			continue
+		}
+
+		err, ok := checked[domain.healthCheck]
+		if !ok {
+			err = checkPort(ipAddr, domain.healthCheck)
+			checked[domain.healthCheck] = err
+		}
+		if err != nil {
+			util.Log("新IP %s 未通过健康检查, 不发布域名 %s 的记录. 异常信息: %s", ipAddr, domain, err)
+			cache.Times = 0
+			continue
+		}
+		result = append(result, domain)
+	}
+	return
+}
+
+// checkPort 检查 ipAddr 的 TCP 端口 port
+func checkPort(ipAddr string, port string) error {
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return &net.AddrError{Err: "invalid port", Addr: port}
+	}
+	return healthCheckDial(net.JoinHostPort(ipAddr, port))
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestFilterHealthCheck 测试未通过健康检查的域名不发布, 并在下次运行重新检查
+func TestFilterHealthCheck(t *testing.T) {
+	defer func(dial func(string) error) { healthCheckDial = dial }(healthCheckDial)
+	var dialed []string
+	healthCheckDial = func(address string) error {
+		dialed = append(dialed, address)
+		if address == "[2001:db8::1]:443" {
+			return nil
+		}
+		return errors.New("connection refused")
+	}
+
+	domains := checkParseDomains([]string{
+		"a.example.com?healthcheck=443",
+		"b.example.com?healthcheck=8443&Line=oversea",
+		"c.example.com?healthcheck=443",
+		"d.example.com",
+	})
+	if domains[1].CustomParams != "Line=oversea" {
+		t.Fatalf("healthcheck 不应传递给DNS服务商: %s", domains[1].CustomParams)
+	}
+
+	cache := &util.IpCache{}
+	cache.Check("2001:db8::1")
+	result := filterHealthCheck("2001:db8::1", cache, domains)
+
+	var names []string
+	for _, d := range result {
+		names = append(names, d.String())
+	}
+	if len(names) != 3 || names[0] != "a.example.com" || names[1] != "c.example.com" || names[2] != "d.example.com" {
+		t.Errorf("期待发布 a/c/d, 实际: %v", names)
+	}
+	if len(dialed) != 2 {
+		t.Errorf("相同端口应只检查一次, 实际: %v", dialed)
+	}
+	if !cache.Check("2001:db8::1") {
+		t.Error("有域名未通过健康检查时下次运行应重新检查")
+	}
+}
+
+// TestCheckPortInvalid 测试无效的端口
+func TestCheckPortInvalid(t *testing.T) {
+	for _, port := range []string{"abc", "0", "70000"} {
+		if checkPort("1.2.3.4", port) == nil {
+			t.Errorf("端口 %s 应无效", port)
+		}
+	}
+}
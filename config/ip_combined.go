@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// combinedAddr 一次请求获得的IPv4/IPv6
+type combinedAddr struct {
+	ipv4 string
+	ipv6 string
+}
+
+// getCombinedAddr 请求 Combined.URL 同时获得IPv4和IPv6, 本轮只请求一次
+func (conf *DnsConfig) getCombinedAddr() (ipv4 string, ipv6 string) {
+	if conf.combined != nil {
+		return conf.combined.ipv4, conf.combined.ipv6
+	}
+	conf.combined = &combinedAddr{}
+
+	client := util.CreateNoProxyHTTPClient("tcp")
+	resp, err := client.Get(conf.Combined.URL)
+	if err != nil {
+		util.Log("通过接口获取IP失败! 接口地址: %s", conf.Combined.URL)
+		util.Log("异常信息: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024000))
+	if err != nil {
+		util.Log("异常信息: %s", err)
+		return
+	}
+
+	if conf.Ipv4.Enable && conf.Ipv4.GetType == "url" {
+		ipv4, err = extractIp(body, conf.Combined.Ipv4Path, Ipv4Reg)
+		if err != nil {
+			util.Log("获取IPv4结果失败! 接口: %s ,返回值: %s", conf.Combined.URL, string(body))
+		}
+	}
+	if conf.Ipv6.Enable && conf.Ipv6.GetType == "url" {
+		ipv6, err = extractIp(body, conf.Combined.Ipv6Path, Ipv6Reg)
+		if err != nil {
+			util.Log("获取IPv6结果失败! 接口: %s ,返回值: %s", conf.Combined.URL, string(body))
+		}
+	}
+	conf.combined.ipv4, conf.combined.ipv6 = ipv4, ipv6
+	return
+}
+
+// extractIp 从返回值中取出IP
+// path 为空时匹配第一个IP, regex: 开头时为正则表达式(有分组时取第一个分组), 否则为 . 分隔的JSON路径
+func extractIp(body []byte, path string, ipReg *regexp.Regexp) (string, error) {
+	text := string(body)
+	switch {
+	case path == "":
+	case strings.HasPrefix(path, "regex:"):
+		reg, err := regexp.Compile(strings.TrimPrefix(path, "regex:"))
+		if err != nil {
+			return "", err
+		}
+		match := reg.FindStringSubmatch(text)
+		if match == nil {
+			return "", fmt.Errorf("%s not matched", path)
+		}
+		text = match[0]
+		if len(match) > 1 {
+			text = match[1]
+		}
+	default:
+		value, err := util.JSONPath(body, path)
+		if err != nil {
+			return "", err
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s is not a string", path)
+		}
+		text = s
+	}
+
+	ip := ipReg.FindString(text)
+	if ip == "" {
+		return "", fmt.Errorf("no IP found")
+	}
+	return ip, nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+// TestExtractIp 测试从同时包含IPv4/IPv6的返回值中取出IP
+func TestExtractIp(t *testing.T) {
+	body := []byte(`{"data":{"v4":"1.2.3.4","v6":"2001:db8::1"},"list":[{"ip":"5.6.7.8"}],"port":80}`)
+	tests := []struct {
+		path    string
+		ipv6    bool
+		want    string
+		wantErr bool
+	}{
+		{"", false, "1.2.3.4", false},
+		{"", true, "2001:db8::1", false},
+		{"data.v4", false, "1.2.3.4", false},
+		{"data.v6", true, "2001:db8::1", false},
+		{"list.0.ip", false, "5.6.7.8", false},
+		{"list.1.ip", false, "", true},
+		{"data.v5", false, "", true},
+		{"port", false, "", true},
+		{`regex:"ip":"([^"]+)"`, false, "5.6.7.8", false},
+		{`regex:\d+\.\d+\.\d+\.8`, false, "5.6.7.8", false},
+		{`regex:"v6":"([^"]+)"`, false, "", true},
+	}
+
+	for _, tt := range tests {
+		reg := Ipv4Reg
+		if tt.ipv6 {
+			reg = Ipv6Reg
+		}
+		got, err := extractIp(body, tt.path, reg)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("extractIp(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// ipHistoryMax 每种IP类型保留的历史IP个数
+const ipHistoryMax = 16
+
+// ipHistory 获取到的IP的历史, 用于发现IP恢复为之前使用过的值(如重新拨号)
+type ipHistory struct {
+	// 最近获取到的IP及最后一次获取到的时间
+	seen    map[string]time.Time
+	current string
+	// current 是否为恢复的IP
+	reverted bool
+}
+
+var ipHistories = struct {
+	sync.Mutex
+	family map[string]*ipHistory
+}{family: map[string]*ipHistory{}}
+
+// observe 记录本次获取到的IP, 返回当前IP是否为 window 内使用过的IP
+// 只有IP改变时才会变为恢复, 之后保持到IP再次改变
+func (h *ipHistory) observe(addr string, now time.Time, window time.Duration) (reverted bool, lastSeen time.Time, changed bool) {
+	if addr == h.current {
+		h.seen[addr] = now
+		return h.reverted, time.Time{}, false
+	}
+
+	lastSeen, ok := h.seen[addr]
+	h.reverted = ok && now.Sub(lastSeen) <= window
+	h.current = addr
+	h.seen[addr] = now
+
+	// 删除最早的记录
+	if len(h.seen) > ipHistoryMax {
+		oldest := addr
+		for a, t := range h.seen {
+			if t.Before(h.seen[oldest]) {
+				oldest = a
+			}
+		}
+		delete(h.seen, oldest)
+	}
+	return h.reverted, lastSeen, true
+}
+
+// CheckIpReverted IP改变为 IPRevertWindow 小时内使用过的IP时记录日志, 并在Webhook中使用 reverted 事件
+func CheckIpReverted(domains *Domains, conf *Config) {
+	if conf.IPRevertWindow <= 0 {
+		return
+	}
+	window := time.Duration(conf.IPRevertWindow) * time.Hour
+	now := time.Now()
+
+	ipHistories.Lock()
+	defer ipHistories.Unlock()
+	for _, f := range []struct {
+		family   string
+		addr     string
+		reverted *bool
+	}{
+		{"IPv4", domains.Ipv4Addr, &domains.Ipv4Reverted},
+		{"IPv6", domains.Ipv6Addr, &domains.Ipv6Reverted},
+	} {
+		if f.addr == "" {
+			continue
+		}
+		h := ipHistories.family[f.family]
+		if h == nil {
+			h = &ipHistory{seen: map[string]time.Time{}}
+			ipHistories.family[f.family] = h
+		}
+		reverted, lastSeen, changed := h.observe(f.addr, now, window)
+		*f.reverted = reverted
+		if reverted && changed {
+			util.Log("%s已恢复为之前使用过的 %s, 上次使用时间 %s, 可能是重新拨号而不是新的地址", f.family, f.addr, lastSeen.Format("2006-01-02 15:04:05"))
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestIpHistoryObserve 测试IP恢复为时间窗口内使用过的值
+func TestIpHistoryObserve(t *testing.T) {
+	h := &ipHistory{seen: map[string]time.Time{}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 24 * time.Hour
+
+	steps := []struct {
+		addr     string
+		after    time.Duration
+		reverted bool
+		changed  bool
+	}{
+		{"1.1.1.1", 0, false, true},
+		{"1.1.1.1", time.Hour, false, false},
+		{"2.2.2.2", 2 * time.Hour, false, true},
+		// 1小时前使用过
+		{"1.1.1.1", 2 * time.Hour, true, true},
+		// IP未改变时保持恢复状态
+		{"1.1.1.1", 3 * time.Hour, true, false},
+		{"3.3.3.3", 4 * time.Hour, false, true},
+		// 超过时间窗口
+		{"2.2.2.2", 30 * time.Hour, false, true},
+	}
+	for i, s := range steps {
+		reverted, _, changed := h.observe(s.addr, start.Add(s.after), window)
+		if reverted != s.reverted || changed != s.changed {
+			t.Errorf("第 %d 步 %s: reverted = %v, changed = %v, want %v, %v", i+1, s.addr, reverted, changed, s.reverted, s.changed)
+		}
+	}
+}
+
+// TestIpHistoryMax 测试只保留最近的历史IP
+func TestIpHistoryMax(t *testing.T) {
+	h := &ipHistory{seen: map[string]time.Time{}}
+	start := time.Now()
+	for i := 0; i < ipHistoryMax+5; i++ {
+		h.observe(string(rune('a'+i)), start.Add(time.Duration(i)*time.Minute), time.Hour)
+	}
+	if len(h.seen) != ipHistoryMax {
+		t.Errorf("期待保留 %d 个, 实际 %d 个", ipHistoryMax, len(h.seen))
+	}
+	if _, ok := h.seen["a"]; ok {
+		t.Error("最早的记录应被删除")
+	}
+}
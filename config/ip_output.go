@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// IPOutput IP改变时写入文件/执行命令, 供防火墙、VPN等其它程序使用
+type IPOutput struct {
+	// 写入的文件路径, 为空不写入
+	IPFilePath string
+	// 文件内容模板, 支持 #{ipv4Addr} #{ipv6Addr}, 为空时每行一个IP
+	IPFileTemplate string
+	// IP改变时执行的命令, 支持的变量同上, 也可通过环境变量 DDNS_IPV4/DDNS_IPV6 获得
+	IPHookCmd string
+}
+
+const defaultIPFileTemplate = "#{ipv4Addr}\n#{ipv6Addr}\n"
+
+// ipHookTimeout IP改变时执行的命令的超时时间
+const ipHookTimeout = 60 * time.Second
+
+// 上次输出的IP
+var lastOutput = struct {
+	sync.Mutex
+	ipv4Addr string
+	ipv6Addr string
+}{}
+
+// ExecIPOutput 当IP改变时写入文件/执行命令
+func ExecIPOutput(ipv4Addr, ipv6Addr string, conf *Config) {
+	if conf.IPFilePath == "" && conf.IPHookCmd == "" {
+		return
+	}
+
+	lastOutput.Lock()
+
+	// 未获取到的IP沿用上次的值
+	if ipv4Addr == "" {
+		ipv4Addr = lastOutput.ipv4Addr
+	}
+	if ipv6Addr == "" {
+		ipv6Addr = lastOutput.ipv6Addr
+	}
+	if ipv4Addr == lastOutput.ipv4Addr && ipv6Addr == lastOutput.ipv6Addr {
+		lastOutput.Unlock()
+		return
+	}
+	lastOutput.ipv4Addr = ipv4Addr
+	lastOutput.ipv6Addr = ipv6Addr
+
+	replacer := strings.NewReplacer(
+		"#{ipv4Addr}", ipv4Addr,
+		"#{ipv6Addr}", ipv6Addr,
+	)
+
+	if conf.IPFilePath != "" {
+		template := conf.IPFileTemplate
+		if template == "" {
+			template = defaultIPFileTemplate
+		}
+		err := os.WriteFile(conf.IPFilePath, []byte(replacer.Replace(template)), 0644)
+		if err != nil {
+			util.Log("写入IP到文件 %s 失败! 异常信息: %s", conf.IPFilePath, err)
+		} else {
+			util.Log("已写入IP到文件 %s", conf.IPFilePath)
+		}
+	}
+	lastOutput.Unlock()
+
+	// 命令可能较慢, 不阻塞其它更新
+	if conf.IPHookCmd != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), ipHookTimeout)
+		defer cancel()
+		execCmd := util.ShellCommandContext(ctx, replacer.Replace(conf.IPHookCmd))
+		execCmd.Env = append(os.Environ(), "DDNS_IPV4="+ipv4Addr, "DDNS_IPV6="+ipv6Addr)
+		// 超时后子进程仍占用输出时不再等待
+		execCmd.WaitDelay = time.Second
+		out, err := execCmd.CombinedOutput()
+		if err != nil {
+			util.Log("执行IP改变命令失败! 命令: %s, 错误: %s, 输出: %q", execCmd.String(), err, out)
+		} else {
+			util.Log("执行IP改变命令成功! 输出: %q", out)
+		}
+	}
+}
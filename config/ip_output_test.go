@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestExecIPOutput 测试IP改变时写入文件并执行命令, IP未改变时不再执行
+func TestExecIPOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("命令使用 sh 语法")
+	}
+	dir := t.TempDir()
+	ipFile := filepath.Join(dir, "ip.txt")
+	hookFile := filepath.Join(dir, "hook.txt")
+	conf := &Config{IPOutput: IPOutput{
+		IPFilePath:     ipFile,
+		IPFileTemplate: "v4=#{ipv4Addr} v6=#{ipv6Addr}",
+		IPHookCmd:      `echo "#{ipv4Addr} $DDNS_IPV6" >> ` + hookFile,
+	}}
+	resetLastOutput := func() {
+		lastOutput.Lock()
+		lastOutput.ipv4Addr, lastOutput.ipv6Addr = "", ""
+		lastOutput.Unlock()
+	}
+	resetLastOutput()
+	t.Cleanup(resetLastOutput)
+
+	readFile := func(path string) string {
+		byt, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(byt)
+	}
+
+	ExecIPOutput("1.1.1.1", "2001:db8::1", conf)
+	if got := readFile(ipFile); got != "v4=1.1.1.1 v6=2001:db8::1" {
+		t.Errorf("写入的文件内容不正确: %q", got)
+	}
+
+	// IP未改变时不再写入和执行命令, 未获取到的IP沿用上次的值
+	os.Remove(ipFile)
+	ExecIPOutput("1.1.1.1", "", conf)
+	if _, err := os.Stat(ipFile); !os.IsNotExist(err) {
+		t.Error("IP未改变时不应写入文件")
+	}
+
+	ExecIPOutput("", "2001:db8::2", conf)
+	if got := readFile(ipFile); got != "v4=1.1.1.1 v6=2001:db8::2" {
+		t.Errorf("写入的文件内容不正确: %q", got)
+	}
+
+	lines := strings.Split(strings.TrimSpace(readFile(hookFile)), "\n")
+	want := []string{"1.1.1.1 2001:db8::1", "1.1.1.1 2001:db8::2"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("期待执行命令 %q, 得到 %q", want, lines)
+	}
+}
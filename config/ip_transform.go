@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// transformIp 更新前按规则转换获取到的IP, 规则为空或转换失败时分别返回原IP/空
+func transformIp(rule, ipAddr string) string {
+	if rule == "" || ipAddr == "" {
+		return ipAddr
+	}
+	result, err := applyTransform(rule, ipAddr)
+	if err != nil {
+		util.Log("转换IP %s 失败! 规则: %s, 异常信息: %s", ipAddr, rule, err)
+		return ""
+	}
+	if result != ipAddr {
+		util.Log("IP %s 已转换为 %s", ipAddr, result)
+	}
+	return result
+}
+
+// applyTransform 支持的规则:
+//
+//	map:1.2.3.4=10.0.0.4,1.2.3.5=10.0.0.5  查表, 未匹配时不转换
+//	offset:-1                              加上偏移量
+//	host:::1234/64                         保留前64位, 其余使用 ::1234 的对应位
+func applyTransform(rule, ipAddr string) (string, error) {
+	addr, err := netip.ParseAddr(ipAddr)
+	if err != nil {
+		return "", err
+	}
+
+	kind, value, _ := strings.Cut(strings.TrimSpace(rule), ":")
+	switch kind {
+	case "map":
+		for _, pair := range strings.Split(value, ",") {
+			from, to, ok := strings.Cut(pair, "=")
+			if !ok {
+				return "", fmt.Errorf("invalid map entry: %s", pair)
+			}
+			fromAddr, err := netip.ParseAddr(strings.TrimSpace(from))
+			if err != nil {
+				return "", err
+			}
+			if fromAddr == addr {
+				toAddr, err := netip.ParseAddr(strings.TrimSpace(to))
+				if err != nil {
+					return "", err
+				}
+				return toAddr.String(), nil
+			}
+		}
+		return addr.String(), nil
+	case "offset":
+		offset, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", err
+		}
+		n := new(big.Int).SetBytes(addr.AsSlice())
+		n.Add(n, big.NewInt(offset))
+		size := addr.BitLen() / 8
+		if n.Sign() < 0 || n.BitLen() > addr.BitLen() {
+			return "", fmt.Errorf("offset %d out of range", offset)
+		}
+		byt := n.FillBytes(make([]byte, size))
+		result, _ := netip.AddrFromSlice(byt)
+		return result.String(), nil
+	case "host":
+		hostPrefix, err := netip.ParsePrefix(strings.TrimSpace(value))
+		if err != nil {
+			return "", err
+		}
+		host := hostPrefix.Addr()
+		if host.BitLen() != addr.BitLen() {
+			return "", fmt.Errorf("%s and %s are not the same address family", host, addr)
+		}
+		return replaceHostBits(addr, host, hostPrefix.Bits()).String(), nil
+	default:
+		return "", fmt.Errorf("unknown transform: %s", kind)
+	}
+}
+
+// replaceHostBits 前 bits 位使用 addr, 其余使用 host 的对应位, 两者须为相同的地址类型
+func replaceHostBits(addr netip.Addr, host netip.Addr, bits int) netip.Addr {
+	byt := addr.AsSlice()
+	hostByt := host.AsSlice()
+	for i := range byt {
+		// 前缀内的位使用获取到的IP, 其余使用规则中的IP
+		n := bits - i*8
+		var mask byte
+		switch {
+		case n >= 8:
+			mask = 0xff
+		case n > 0:
+			mask = ^byte(0xff >> n)
+		}
+		byt[i] = byt[i]&mask | hostByt[i]&^mask
+	}
+	result, _ := netip.AddrFromSlice(byt)
+	return result
+}
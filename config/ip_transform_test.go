@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+// TestApplyTransform 测试IP转换规则
+func TestApplyTransform(t *testing.T) {
+	tests := []struct {
+		rule    string
+		ip      string
+		want    string
+		wantErr bool
+	}{
+		{"map:1.2.3.4=10.0.0.4,1.2.3.5=10.0.0.5", "1.2.3.5", "10.0.0.5", false},
+		{"map:1.2.3.4=10.0.0.4", "1.2.3.6", "1.2.3.6", false},
+		{"map:1.2.3.4", "1.2.3.4", "", true},
+		{"offset:2", "1.2.3.254", "1.2.4.0", false},
+		{"offset:-1", "2001:db8::1", "2001:db8::", false},
+		{"offset:1", "255.255.255.255", "", true},
+		{"host:::1234/64", "2001:db8:1:2:a:b:c:d", "2001:db8:1:2::1234", false},
+		{"host:0.0.0.10/24", "1.2.3.4", "1.2.3.10", false},
+		{"host:0.0.0.10/20", "1.2.19.4", "1.2.16.10", false},
+		{"host:::1/64", "1.2.3.4", "", true},
+		{"unknown:1", "1.2.3.4", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := applyTransform(tt.rule, tt.ip)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("applyTransform(%q, %q) error = %v, wantErr %v", tt.rule, tt.ip, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("applyTransform(%q, %q) = %q, want %q", tt.rule, tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// authoritativePrefix 以 ! 开头的接口为权威接口
+const authoritativePrefix = "!"
+
+// splitUrls 分割逗号分隔的接口, 返回去掉标记后的接口以及权威接口的序号, 没有时为 -1
+func splitUrls(urlStr string) (urls []string, authority int) {
+	authority = -1
+	for _, url := range strings.Split(urlStr, ",") {
+		url = strings.TrimSpace(url)
+		if strings.HasPrefix(url, authoritativePrefix) {
+			url = strings.TrimSpace(strings.TrimPrefix(url, authoritativePrefix))
+			// 只有第一个标记的接口为权威接口
+			if authority < 0 {
+				authority = len(urls)
+			}
+		}
+		urls = append(urls, url)
+	}
+	return
+}
+
+// fetchWithAuthority 同时请求所有接口
+// 权威接口获取成功时总是使用其结果, 即使其它接口先返回; 权威接口失败时使用最先成功的其它接口
+func fetchWithAuthority(urls []string, authority int, fetch func(url string) string) (result string, url string) {
+	type fetchResult struct {
+		index int
+		ip    string
+	}
+	ch := make(chan fetchResult, len(urls))
+	for i, url := range urls {
+		go func() {
+			ch <- fetchResult{index: i, ip: fetch(url)}
+		}()
+	}
+
+	authorityFailed := false
+	fallback := -1
+	fallbackIp := ""
+	for range urls {
+		r := <-ch
+		if r.index == authority {
+			if r.ip != "" {
+				return r.ip, urls[r.index]
+			}
+			authorityFailed = true
+		} else if r.ip != "" && fallback < 0 {
+			fallback, fallbackIp = r.index, r.ip
+		}
+		if authorityFailed && fallback >= 0 {
+			util.Log("权威接口 %s 获取IP失败, 将使用接口 %s 的结果", urls[authority], urls[fallback])
+			return fallbackIp, urls[fallback]
+		}
+	}
+	return "", ""
+}
+
+// fetchConsensus 同时请求所有接口, 超过半数的接口结果相同时才使用该IP, 防止单个接口被劫持
+// 请求失败的接口也计入总数, 未达成多数一致时返回空
+func fetchConsensus(urls []string, fetch func(url string) string) (result string, source string) {
+	ips := make([]string, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ips[i] = fetch(url)
+		}()
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	for _, ip := range ips {
+		if ip != "" {
+			votes[ip]++
+			if votes[ip] > votes[result] {
+				result = ip
+			}
+		}
+	}
+
+	if votes[result]*2 <= len(urls) {
+		var answers []string
+		for i, ip := range ips {
+			answers = append(answers, urls[i]+"="+ip)
+		}
+		util.Log("未有超过半数的接口返回相同的IP, 将不会更新! 结果: %s", strings.Join(answers, ", "))
+		return "", ""
+	}
+
+	var agreed []string
+	for i, ip := range ips {
+		if ip == result {
+			agreed = append(agreed, urls[i])
+		} else if ip != "" {
+			util.Log("接口 %s 返回的IP %s 与多数接口的结果 %s 不一致", urls[i], ip, result)
+		}
+	}
+	return result, strings.Join(agreed, ",")
+}
+
+// getIpFromUrl 通过接口获取IP, 请求失败时返回 err, 返回值中没有IP时 result 为空
+func getIpFromUrl(client *http.Client, url string, ipReg *regexp.Regexp, ipType string) (result string, err error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		if ipType == "IPv4" {
+			util.Log("通过接口获取IPv4失败! 接口地址: %s", url)
+		} else {
+			util.Log("通过接口获取IPv6失败! 接口地址: %s", url)
+		}
+		util.Log("异常信息: %s", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	lr := io.LimitReader(resp.Body, 1024000)
+	body, err := io.ReadAll(lr)
+	if err != nil {
+		util.Log("异常信息: %s", err)
+		return "", err
+	}
+	result = ipReg.FindString(string(body))
+	if result == "" {
+		if ipType == "IPv4" {
+			util.Log("获取IPv4结果失败! 接口: %s ,返回值: %s", url, string(body))
+		} else {
+			util.Log("获取IPv6结果失败! 接口: %s ,返回值: %s", url, string(body))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSplitUrls 测试权威接口的标记
+func TestSplitUrls(t *testing.T) {
+	urls, authority := splitUrls("https://a, !https://b ,! https://c")
+	if len(urls) != 3 || urls[1] != "https://b" || urls[2] != "https://c" || authority != 1 {
+		t.Errorf("splitUrls = %v, %d", urls, authority)
+	}
+	if _, authority = splitUrls("https://a, https://b"); authority != -1 {
+		t.Errorf("没有标记时 authority = %d, want -1", authority)
+	}
+}
+
+// TestFetchWithAuthority 测试权威接口优先于更快返回的接口, 失败时使用最先成功的其它接口
+func TestFetchWithAuthority(t *testing.T) {
+	type response struct {
+		ip    string
+		delay time.Duration
+	}
+	tests := []struct {
+		name      string
+		responses map[string]response
+		authority int
+		want      string
+		wantUrl   string
+	}{
+		{
+			"权威接口较慢时仍使用其结果",
+			map[string]response{"a": {"1.1.1.1", 0}, "b": {"2.2.2.2", 50 * time.Millisecond}},
+			1, "2.2.2.2", "b",
+		},
+		{
+			"权威接口失败时使用最先成功的接口",
+			map[string]response{"a": {"1.1.1.1", 30 * time.Millisecond}, "b": {"", 0}, "c": {"3.3.3.3", 0}},
+			1, "3.3.3.3", "c",
+		},
+		{
+			"全部失败",
+			map[string]response{"a": {"", 0}, "b": {"", 0}},
+			0, "", "",
+		},
+	}
+
+	for _, tt := range tests {
+		urls := []string{"a", "b", "c"}[:len(tt.responses)]
+		got, url := fetchWithAuthority(urls, tt.authority, func(url string) string {
+			r := tt.responses[url]
+			time.Sleep(r.delay)
+			return r.ip
+		})
+		if got != tt.want || url != tt.wantUrl {
+			t.Errorf("%s: fetchWithAuthority = %q, %q, want %q, %q", tt.name, got, url, tt.want, tt.wantUrl)
+		}
+	}
+}
+
+// TestFetchConsensus 测试超过半数的接口结果相同时才使用该IP
+func TestFetchConsensus(t *testing.T) {
+	tests := []struct {
+		name       string
+		ips        []string
+		want       string
+		wantSource string
+	}{
+		{"全部一致", []string{"1.1.1.1", "1.1.1.1", "1.1.1.1"}, "1.1.1.1", "a,b,c"},
+		{"多数一致", []string{"1.1.1.1", "6.6.6.6", "1.1.1.1"}, "1.1.1.1", "a,c"},
+		{"失败的接口计入总数", []string{"1.1.1.1", "", ""}, "", ""},
+		{"刚好一半", []string{"1.1.1.1", "1.1.1.1", "6.6.6.6", ""}, "", ""},
+		{"各不相同", []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		urls := []string{"a", "b", "c", "d"}[:len(tt.ips)]
+		got, source := fetchConsensus(urls, func(url string) string {
+			return tt.ips[url[0]-'a']
+		})
+		if got != tt.want || source != tt.wantSource {
+			t.Errorf("%s: fetchConsensus = %q, %q, want %q, %q", tt.name, got, source, tt.want, tt.wantSource)
+		}
+	}
+}
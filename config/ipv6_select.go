@@ -0,0 +1,46 @@
+package config
+
+import (
+	"bytes"
+	"net"
+	"sort"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// 网卡有多个IPv6地址时的选择方式, 见 Ipv6.Select
+const (
+	ipv6SelectFirst   = "first"
+	ipv6SelectLowest  = "lowest"
+	ipv6SelectHighest = "highest"
+)
+
+// orderIpv6 按 order 对网卡的IPv6地址排序, 使每次选择的地址相同
+// 前 stable 个稳定地址和之后的临时地址分别排序, 稳定地址仍然优先
+func orderIpv6(addrs []string, stable int, order string) []string {
+	switch order {
+	case "", ipv6SelectFirst:
+		return addrs
+	case ipv6SelectLowest, ipv6SelectHighest:
+	default:
+		util.Log("IPv6地址的选择方式 %s 不正确, 将使用第一个地址", order)
+		return addrs
+	}
+
+	if stable < 0 || stable > len(addrs) {
+		stable = len(addrs)
+	}
+	sorted := append([]string{}, addrs...)
+	less := func(group []string) func(i, j int) bool {
+		return func(i, j int) bool {
+			c := bytes.Compare(net.ParseIP(group[i]).To16(), net.ParseIP(group[j]).To16())
+			if order == ipv6SelectHighest {
+				return c > 0
+			}
+			return c < 0
+		}
+	}
+	sort.SliceStable(sorted[:stable], less(sorted[:stable]))
+	sort.SliceStable(sorted[stable:], less(sorted[stable:]))
+	return sorted
+}
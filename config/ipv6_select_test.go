@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestOrderIpv6 测试按地址大小选择IPv6, 稳定地址仍排在临时地址之前
+func TestOrderIpv6(t *testing.T) {
+	// 前2个为稳定地址
+	addrs := []string{"2001:db8::20", "2001:db8::3", "2001:db8::ff", "2001:db8::1"}
+
+	tests := []struct {
+		order  string
+		stable int
+		want   []string
+	}{
+		{"", 2, addrs},
+		{"first", 2, addrs},
+		{"lowest", 2, []string{"2001:db8::3", "2001:db8::20", "2001:db8::1", "2001:db8::ff"}},
+		{"highest", 2, []string{"2001:db8::20", "2001:db8::3", "2001:db8::ff", "2001:db8::1"}},
+		{"lowest", 4, []string{"2001:db8::1", "2001:db8::3", "2001:db8::20", "2001:db8::ff"}},
+		{"unknown", 4, addrs},
+	}
+	for _, tt := range tests {
+		got := orderIpv6(addrs, tt.stable, tt.order)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orderIpv6(%q, %d) = %v, want %v", tt.order, tt.stable, got, tt.want)
+		}
+	}
+
+	// 不同的顺序得到相同的结果
+	shuffled := []string{"2001:db8::ff", "2001:db8::1", "2001:db8::20", "2001:db8::3"}
+	if got := orderIpv6(shuffled, 4, "lowest")[0]; got != "2001:db8::1" {
+		t.Errorf("期待 2001:db8::1, 实际 %s", got)
+	}
+	if addrs[0] != "2001:db8::20" {
+		t.Error("不应修改原切片")
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+)
+
+// defaultIpv6PrefixLen 未设置 ipv6prefixlen 时保留的前缀长度
+const defaultIpv6PrefixLen = 64
+
+// Ipv6Addr 域名设置了 ipv6suffix 时, 使用获取到的IPv6地址的前缀加上后缀, 得到内网中其它设备的地址
+// 如前缀 2408:8200:1234:5678::/64 加上后缀 ::1a2b:3cff:fe4d:5e6f, 未设置时返回 ipAddr
+func (d Domain) Ipv6Addr(ipAddr string) (string, error) {
+	if d.ipv6Suffix == "" {
+		return ipAddr, nil
+	}
+
+	bits := defaultIpv6PrefixLen
+	if d.ipv6PrefixLen != "" {
+		n, err := strconv.Atoi(d.ipv6PrefixLen)
+		if err != nil || n <= 0 || n >= 128 {
+			return "", fmt.Errorf("invalid ipv6prefixlen %s, must be between 1 and 127", d.ipv6PrefixLen)
+		}
+		bits = n
+	}
+
+	suffix, err := netip.ParseAddr(d.ipv6Suffix)
+	if err != nil || !suffix.Is6() || suffix.Is4In6() {
+		return "", fmt.Errorf("invalid ipv6suffix %s, must be an IPv6 address such as ::1234", d.ipv6Suffix)
+	}
+	// 后缀在前缀内有非0的位, 说明前缀长度与后缀不匹配
+	if replaceHostBits(netip.IPv6Unspecified(), suffix, bits) != suffix {
+		return "", fmt.Errorf("ipv6suffix %s has bits within the /%d prefix", d.ipv6Suffix, bits)
+	}
+
+	addr, err := netip.ParseAddr(ipAddr)
+	if err != nil || !addr.Is6() || addr.Is4In6() {
+		return "", fmt.Errorf("%s is not an IPv6 address", ipAddr)
+	}
+	return replaceHostBits(addr, suffix, bits).String(), nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+// TestIpv6Addr 测试使用获取到的前缀加上域名的 ipv6suffix
+func TestIpv6Addr(t *testing.T) {
+	tests := []struct {
+		domain  string
+		ip      string
+		want    string
+		wantErr bool
+	}{
+		{"www.example.com", "2408:8200:1234:5678::1", "2408:8200:1234:5678::1", false},
+		{"www.example.com?ipv6suffix=::1a2b:3cff:fe4d:5e6f", "2408:8200:1234:5678:aaaa:bbbb:cccc:dddd", "2408:8200:1234:5678:1a2b:3cff:fe4d:5e6f", false},
+		{"www.example.com?ipv6suffix=::1a2b:3cff:fe4d:5e6f", "240e:3a1:abcd:ef01::100", "240e:3a1:abcd:ef01:1a2b:3cff:fe4d:5e6f", false},
+		{"www.example.com?ipv6suffix=::12:0:0:0:10&ipv6prefixlen=56", "2408:8200:1234:5678::1", "2408:8200:1234:5612::10", false},
+		{"www.example.com?ipv6suffix=::1234&ipv6prefixlen=120", "2001:db8::1:1", "", true},
+		{"www.example.com?ipv6suffix=1:2::3", "2001:db8::1", "", true},
+		{"www.example.com?ipv6suffix=::1&ipv6prefixlen=128", "2001:db8::1", "", true},
+		{"www.example.com?ipv6suffix=0.0.0.1", "2001:db8::1", "", true},
+		{"www.example.com?ipv6suffix=::1", "1.2.3.4", "", true},
+	}
+
+	for _, tt := range tests {
+		domain := checkParseDomains([]string{tt.domain})[0]
+		if domain.CustomParams != "" {
+			t.Errorf("%s 的参数不应传递给DNS服务商: %s", tt.domain, domain.CustomParams)
+		}
+		got, err := domain.Ipv6Addr(tt.ip)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %s error = %v, wantErr %v", tt.domain, tt.ip, err, tt.wantErr)
+			continue
+		}
+		// 失败时不应返回地址
+		if got != tt.want {
+			t.Errorf("%s %s = %s, want %s", tt.domain, tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// MQTT IP改变/更新失败时发布消息到 MQTT broker, 触发条件与Webhook相同
+type MQTT struct {
+	// MQTTURL broker 地址, 如 tcp://192.168.1.2:1883 / ssl://broker.example.com:8883, 为空不发布
+	MQTTURL      string
+	MQTTTopic    string
+	MQTTUsername string
+	MQTTPassword string
+	// MQTTPayload 消息内容, 支持的变量与Webhook相同, 为空时使用 defaultMQTTPayload
+	MQTTPayload string
+	// MQTTRetain 保留消息, 新订阅者可立即获得最后的IP
+	MQTTRetain bool
+}
+
+const (
+	defaultMQTTTopic   = "ddns-go/ip"
+	defaultMQTTPayload = `{"event":"#{event}","ipv4Addr":"#{ipv4Addr}","ipv4Result":"#{ipv4Result}","ipv4Domains":"#{ipv4Domains}","ipv6Addr":"#{ipv6Addr}","ipv6Result":"#{ipv6Result}","ipv6Domains":"#{ipv6Domains}"}`
+)
+
+func (m *MQTT) name() string {
+	return "mqtt"
+}
+
+func (m *MQTT) enabled() bool {
+	return m.MQTTURL != ""
+}
+
+// notify 发布消息到 MQTT broker
+func (m *MQTT) notify(domains *Domains, event webhookEvent, v4Status updateStatusType, v6Status updateStatusType) error {
+	topic := m.MQTTTopic
+	if topic == "" {
+		topic = defaultMQTTTopic
+	}
+	payload := replacePara(domains, m.getPayload(), event, v4Status, v6Status)
+	err := util.MQTTPublish(m.MQTTURL, m.MQTTUsername, m.MQTTPassword, topic, []byte(payload), m.MQTTRetain)
+	if err != nil {
+		util.Log("MQTT发布失败! 异常信息：%s", err)
+		return err
+	}
+	util.Log("MQTT发布成功! Topic: %s", topic)
+	return nil
+}
+
+// getPayload 获得消息内容模板
+func (m *MQTT) getPayload() string {
+	if m.MQTTPayload == "" {
+		return defaultMQTTPayload
+	}
+	return m.MQTTPayload
+}
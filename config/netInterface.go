@@ -9,6 +9,8 @@ import (
 type NetInterface struct {
 	Name    string
 	Address []string
+	// stable Address 中排在前面的稳定地址个数, 之后为临时地址
+	stable int
 }
 
 // GetNetInterface 获得网卡地址
@@ -52,12 +54,27 @@ func GetNetInterface() (ipv4NetInterfaces []NetInterface, ipv6NetInterfaces []Ne
 				)
 			}
 
+			// 优先使用稳定的地址, 临时地址(RFC 4941)会频繁变化
+			stable := len(ipv6)
+			if len(ipv6) > 1 {
+				temporary, err := getTemporaryIpv6(allNetInterfaces[i].Name)
+				if err != nil {
+					temporary = nil
+				}
+				ipv6 = sortIpv6ByStability(ipv6, temporary)
+				stable = 0
+				for stable < len(ipv6) && isStableIpv6(ipv6[stable], temporary) {
+					stable++
+				}
+			}
+
 			if len(ipv6) > 0 {
 				ipv6NetInterfaces = append(
 					ipv6NetInterfaces,
 					NetInterface{
 						Name:    allNetInterfaces[i].Name,
 						Address: ipv6,
+						stable:  stable,
 					},
 				)
 			}
@@ -67,3 +84,41 @@ func GetNetInterface() (ipv4NetInterfaces []NetInterface, ipv6NetInterfaces []Ne
 
 	return ipv4NetInterfaces, ipv6NetInterfaces, nil
 }
+
+// getLocalIpv6 获得网卡的ULA(fc00::/7)地址, 以及网卡是否只有链路本地/ULA的IPv6地址
+func getLocalIpv6(ifaceName string) (ula []string, localOnly bool) {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return nil, false
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, false
+	}
+	var ips []net.IP
+	for _, address := range addrs {
+		if ipnet, ok := address.(*net.IPNet); ok {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return classifyLocalIpv6(ips)
+}
+
+// classifyLocalIpv6 返回其中的ULA地址, 以及IPv6地址是否都为链路本地/ULA地址
+func classifyLocalIpv6(ips []net.IP) (ula []string, localOnly bool) {
+	hasIpv6 := false
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			continue
+		}
+		hasIpv6 = true
+		switch {
+		case ip.IsPrivate():
+			ula = append(ula, ip.String())
+		case ip.IsLinkLocalUnicast():
+		default:
+			return ula, false
+		}
+	}
+	return ula, hasIpv6
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultExcludeInterfaces 自动选择网卡时默认跳过的虚拟网卡(容器、网桥、VPN、虚拟机)
+var DefaultExcludeInterfaces = []string{
+	"docker*", "br-*", "veth*", "cni*", "flannel*", "cali*", "podman*",
+	"virbr*", "lxcbr*", "lxdbr*", "vmnet*", "vboxnet*", "vEthernet*",
+	"wg*", "tun*", "tap*", "utun*", "tailscale*", "zt*",
+}
+
+// isExcludedInterface 网卡名是否匹配 patterns 中的名称或通配符(如 br-*), 以 ! 开头的不排除该网卡, 优先于其它规则
+func isExcludedInterface(name string, patterns []string) bool {
+	excluded := false
+	for _, pattern := range patterns {
+		if keep, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matched, _ := path.Match(keep, name); matched {
+				return false
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			excluded = true
+		}
+	}
+	return excluded
+}
+
+// splitNetInterfaces 按 patterns 将网卡分为可自动选择的和被排除的, 保持原来的顺序
+func splitNetInterfaces(list []NetInterface, patterns []string) (kept []NetInterface, excluded []NetInterface) {
+	for _, netInterface := range list {
+		if isExcludedInterface(netInterface.Name, patterns) {
+			excluded = append(excluded, netInterface)
+		} else {
+			kept = append(kept, netInterface)
+		}
+	}
+	return
+}
+
+// excludeInterfacePatterns 默认排除的网卡加上配置文件中的 ExcludeInterfaces
+func (conf *Config) excludeInterfacePatterns() []string {
+	return slices.Concat(DefaultExcludeInterfaces, conf.ExcludeInterfaces)
+}
+
+// getExcludeInterfacePatterns 读取配置文件中的排除规则
+func getExcludeInterfacePatterns() []string {
+	conf, _ := GetConfigCached()
+	return conf.excludeInterfacePatterns()
+}
+
+// PreferNetInterfaces 被排除的网卡排在最后, 页面默认选中第一个网卡时不会选中虚拟网卡, 但仍可手动选择
+func (conf *Config) PreferNetInterfaces(list []NetInterface) []NetInterface {
+	kept, excluded := splitNetInterfaces(list, conf.excludeInterfacePatterns())
+	return append(kept, excluded...)
+}
+
+// autoNetInterface 未填写网卡名时自动选择第一个未被排除的网卡
+func autoNetInterface(list []NetInterface) (NetInterface, bool) {
+	kept, _ := splitNetInterfaces(list, getExcludeInterfacePatterns())
+	if len(kept) == 0 {
+		return NetInterface{}, false
+	}
+	return kept[0], true
+}
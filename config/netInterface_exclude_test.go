@@ -0,0 +1,40 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitNetInterfaces 测试按名称和通配符排除网卡
+func TestSplitNetInterfaces(t *testing.T) {
+	list := []NetInterface{{Name: "docker0"}, {Name: "br-1a2b3c"}, {Name: "eth0"}, {Name: "wg0"}, {Name: "ppp0"}, {Name: "veth12ab"}}
+
+	tests := []struct {
+		patterns []string
+		kept     []string
+		excluded []string
+	}{
+		{DefaultExcludeInterfaces, []string{"eth0", "ppp0"}, []string{"docker0", "br-1a2b3c", "wg0", "veth12ab"}},
+		{append(DefaultExcludeInterfaces, "eth0"), []string{"ppp0"}, []string{"docker0", "br-1a2b3c", "eth0", "wg0", "veth12ab"}},
+		{append(DefaultExcludeInterfaces, "!wg0"), []string{"eth0", "wg0", "ppp0"}, []string{"docker0", "br-1a2b3c", "veth12ab"}},
+		{nil, []string{"docker0", "br-1a2b3c", "eth0", "wg0", "ppp0", "veth12ab"}, nil},
+	}
+
+	names := func(list []NetInterface) (result []string) {
+		for _, netInterface := range list {
+			result = append(result, netInterface.Name)
+		}
+		return
+	}
+	for _, tt := range tests {
+		kept, excluded := splitNetInterfaces(list, tt.patterns)
+		if !reflect.DeepEqual(names(kept), tt.kept) || !reflect.DeepEqual(names(excluded), tt.excluded) {
+			t.Errorf("%v 期待 %v %v, 得到 %v %v", tt.patterns, tt.kept, tt.excluded, names(kept), names(excluded))
+		}
+	}
+
+	conf := &Config{}
+	if preferred := names(conf.PreferNetInterfaces(list)); preferred[0] != "eth0" || len(preferred) != len(list) {
+		t.Errorf("被排除的网卡应排在最后, 得到 %v", preferred)
+	}
+}
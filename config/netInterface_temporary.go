@@ -0,0 +1,51 @@
+package config
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// sortIpv6ByStability 将稳定的IPv6地址排在临时地址(RFC 4941)之前, 同类地址保持原顺序
+// temporary 为系统标记的临时地址, 为 nil 表示无法获得系统标记,
+// 此时使用启发式: EUI-64 地址(接口标识含 ff:fe)由 MAC 生成, 视为稳定地址
+func sortIpv6ByStability(addrs []string, temporary map[string]bool) []string {
+	sorted := append([]string{}, addrs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isStableIpv6(sorted[i], temporary) && !isStableIpv6(sorted[j], temporary)
+	})
+	return sorted
+}
+
+// isStableIpv6 是否为稳定的IPv6地址, temporary 见 sortIpv6ByStability
+func isStableIpv6(addr string, temporary map[string]bool) bool {
+	if temporary != nil {
+		return !temporary[addr]
+	}
+	return isEUI64(net.ParseIP(addr))
+}
+
+// isEUI64 是否为 EUI-64 格式的地址
+func isEUI64(ip net.IP) bool {
+	ip = ip.To16()
+	return ip != nil && ip[11] == 0xff && ip[12] == 0xfe
+}
+
+// parseIfconfigTemporary 解析 ifconfig 的输出, 获得标记为 temporary 的IPv6地址
+func parseIfconfigTemporary(output string) map[string]bool {
+	temporary := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "inet6" {
+			continue
+		}
+		for _, f := range fields[2:] {
+			if f == "temporary" {
+				// 去掉 %en0 这样的区域
+				temporary[strings.Split(fields[1], "%")[0]] = true
+				break
+			}
+		}
+	}
+	return temporary
+}
@@ -0,0 +1,14 @@
+//go:build darwin
+
+package config
+
+import "os/exec"
+
+// getTemporaryIpv6 通过 ifconfig 获得网卡的临时IPv6地址
+func getTemporaryIpv6(ifaceName string) (map[string]bool, error) {
+	out, err := exec.Command("ifconfig", ifaceName).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseIfconfigTemporary(string(out)), nil
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package config
+
+import (
+	"encoding/hex"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ifaFTemporary IFA_F_TEMPORARY, 见 linux/if_addr.h
+const ifaFTemporary = 0x01
+
+// getTemporaryIpv6 通过 /proc/net/if_inet6 获得网卡的临时IPv6地址
+func getTemporaryIpv6(ifaceName string) (map[string]bool, error) {
+	byt, err := os.ReadFile("/proc/net/if_inet6")
+	if err != nil {
+		return nil, err
+	}
+
+	temporary := map[string]bool{}
+	for _, line := range strings.Split(string(byt), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 6 || fields[5] != ifaceName {
+			continue
+		}
+		addr, err := hex.DecodeString(fields[0])
+		if err != nil || len(addr) != net.IPv6len {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[4], 16, 32)
+		if err == nil && flags&ifaFTemporary != 0 {
+			temporary[net.IP(addr).String()] = true
+		}
+	}
+	return temporary, nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package config
+
+// getTemporaryIpv6 其它系统无法获得临时地址标记, 返回 nil 使用启发式判断
+func getTemporaryIpv6(ifaceName string) (map[string]bool, error) {
+	return nil, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSortIpv6ByStability 测试优先选择稳定的IPv6地址
+func TestSortIpv6ByStability(t *testing.T) {
+	addrs := []string{"2001:db8::1234:5678:9abc:def0", "2001:db8::2", "2001:db8::211:22ff:fe33:4455"}
+
+	tests := map[string]struct {
+		temporary map[string]bool
+		expected  []string
+	}{
+		"系统标记了临时地址": {
+			map[string]bool{"2001:db8::1234:5678:9abc:def0": true},
+			[]string{"2001:db8::2", "2001:db8::211:22ff:fe33:4455", "2001:db8::1234:5678:9abc:def0"},
+		},
+		"没有临时地址保持顺序": {
+			map[string]bool{},
+			addrs,
+		},
+		"无法获得标记时优先 EUI-64": {
+			nil,
+			[]string{"2001:db8::211:22ff:fe33:4455", "2001:db8::1234:5678:9abc:def0", "2001:db8::2"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := sortIpv6ByStability(addrs, tt.temporary)
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("期待 %v, 得到 %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// TestParseIfconfigTemporary 测试解析 macOS ifconfig 的临时地址
+func TestParseIfconfigTemporary(t *testing.T) {
+	output := `en0: flags=8863<UP,BROADCAST,SMART,RUNNING,SIMPLEX,MULTICAST> mtu 1500
+	inet6 fe80::1c2d:3e4f:5a6b:7c8d%en0 prefixlen 64 secured scopeid 0xe
+	inet6 2001:db8::1c2d:3e4f:5a6b:7c8d prefixlen 64 autoconf secured
+	inet6 2001:db8::a1b2:c3d4:e5f6:1234 prefixlen 64 autoconf temporary
+	inet 192.168.1.2 netmask 0xffffff00 broadcast 192.168.1.255`
+
+	expected := map[string]bool{"2001:db8::a1b2:c3d4:e5f6:1234": true}
+	if actual := parseIfconfigTemporary(output); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("期待 %v, 得到 %v", expected, actual)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package config
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// getTemporaryIpv6 通过 GetAdaptersAddresses 获得网卡的临时IPv6地址
+// 临时地址的接口标识为随机生成(SuffixOrigin 为 IpSuffixOriginRandom)
+func getTemporaryIpv6(ifaceName string) (map[string]bool, error) {
+	size := uint32(15000)
+	var buf []byte
+	for {
+		buf = make([]byte, size)
+		err := windows.GetAdaptersAddresses(windows.AF_INET6, 0, 0,
+			(*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])), &size)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, windows.ERROR_BUFFER_OVERFLOW) {
+			return nil, err
+		}
+	}
+
+	temporary := map[string]bool{}
+	for aa := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])); aa != nil; aa = aa.Next {
+		if windows.UTF16PtrToString(aa.FriendlyName) != ifaceName {
+			continue
+		}
+		for ua := aa.FirstUnicastAddress; ua != nil; ua = ua.Next {
+			if ua.SuffixOrigin == windows.IpSuffixOriginRandom {
+				temporary[ua.Address.IP().String()] = true
+			}
+		}
+	}
+	return temporary, nil
+}
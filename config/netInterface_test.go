@@ -1,6 +1,7 @@
 package config
 
 import (
+	"net"
 	"testing"
 )
 
@@ -11,3 +12,29 @@ func TestGetNetInterface(t *testing.T) {
 	}
 	t.Log(ipv4NetInterfaces, ipv6NetInterfaces)
 }
+
+func TestClassifyLocalIpv6(t *testing.T) {
+	tests := []struct {
+		ips       []string
+		ula       int
+		localOnly bool
+	}{
+		{[]string{"fe80::1"}, 0, true},
+		{[]string{"192.168.1.2", "fe80::1", "fd00::2"}, 1, true},
+		{[]string{"fe80::1", "2001:db8::1"}, 0, false},
+		{[]string{"fd00::2", "2409:8a00::1"}, 1, false},
+		{[]string{"192.168.1.2"}, 0, false},
+		{nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		var ips []net.IP
+		for _, s := range tt.ips {
+			ips = append(ips, net.ParseIP(s))
+		}
+		ula, localOnly := classifyLocalIpv6(ips)
+		if len(ula) != tt.ula || localOnly != tt.localOnly {
+			t.Errorf("classifyLocalIpv6(%v) = %v, %v, want %d ULA, %v", tt.ips, ula, localOnly, tt.ula, tt.localOnly)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultRouteInterface 网卡名为此值时使用默认路由所在的网卡(WAN口)
+const DefaultRouteInterface = "@default"
+
+// 路由标志, 见 linux/ipv6_route.h
+const (
+	rtfGateway  = 0x0002
+	rtfReject   = 0x0200
+	rtfAddrconf = 0x40000
+	rtfPrefixRT = 0x80000
+)
+
+// ipv6Route /proc/net/ipv6_route 中的一条路由
+type ipv6Route struct {
+	Dst   *net.IPNet
+	Flags uint32
+	Iface string
+}
+
+// parseIpv6Routes 解析 /proc/net/ipv6_route 的内容
+func parseIpv6Routes(content string) (routes []ipv6Route) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 10 {
+			continue
+		}
+		dst, err := hex.DecodeString(fields[0])
+		if err != nil || len(dst) != net.IPv6len {
+			continue
+		}
+		dstLen, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, ipv6Route{
+			Dst:   &net.IPNet{IP: net.IP(dst), Mask: net.CIDRMask(int(dstLen), 128)},
+			Flags: uint32(flags),
+			Iface: fields[9],
+		})
+	}
+	return
+}
+
+// defaultRouteIface 获得IPv6默认路由所在的网卡, 跳过 exclude 中的网卡(如VPN的默认路由)
+func defaultRouteIface(routes []ipv6Route, exclude []string) (string, error) {
+	for _, r := range routes {
+		ones, _ := r.Dst.Mask.Size()
+		if ones == 0 && r.Dst.IP.IsUnspecified() && r.Flags&rtfGateway != 0 && r.Flags&rtfReject == 0 && r.Iface != "lo" &&
+			!isExcludedInterface(r.Iface, exclude) {
+			return r.Iface, nil
+		}
+	}
+	return "", errors.New("no IPv6 default route")
+}
+
+// raPrefixes 获得网卡通过路由通告(RA)得到的全局单播前缀
+func raPrefixes(routes []ipv6Route, iface string) (prefixes []*net.IPNet) {
+	_, ipv6Unicast, _ := net.ParseCIDR("2000::/3")
+	for _, r := range routes {
+		ones, _ := r.Dst.Mask.Size()
+		if r.Iface == iface && r.Flags&(rtfAddrconf|rtfPrefixRT) != 0 &&
+			ones > 0 && ones <= 64 && ipv6Unicast.Contains(r.Dst.IP) {
+			prefixes = append(prefixes, r.Dst)
+		}
+	}
+	return
+}
+
+// addrFromPrefix 使用前缀和链路本地地址的接口标识(后64位)组成全局地址
+func addrFromPrefix(prefix *net.IPNet, linkLocal net.IP) net.IP {
+	addr := make(net.IP, net.IPv6len)
+	copy(addr, prefix.IP.To16())
+	copy(addr[8:], linkLocal.To16()[8:])
+	return addr
+}
+
+// getIpv6AddrFromRA 网卡没有全局IPv6地址时(如PPP/WAN口), 通过路由通告的前缀得到IPv6地址, 该地址未分配给网卡, 需设置 Ipv6.FromRA
+func getIpv6AddrFromRA(ifaceName string) (string, error) {
+	routes, err := readIpv6Routes()
+	if err != nil {
+		return "", err
+	}
+
+	prefixes := raPrefixes(routes, ifaceName)
+	if len(prefixes) == 0 {
+		return "", errors.New("no router advertisement prefix on " + ifaceName)
+	}
+
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+	for _, address := range addrs {
+		if ipnet, ok := address.(*net.IPNet); ok && ipnet.IP.To4() == nil && ipnet.IP.IsLinkLocalUnicast() {
+			return addrFromPrefix(prefixes[0], ipnet.IP).String(), nil
+		}
+	}
+	return "", errors.New("no link-local address on " + ifaceName)
+}
+
+// getDefaultRouteIface 获得IPv6默认路由所在的网卡名
+func getDefaultRouteIface() (string, error) {
+	routes, err := readIpv6Routes()
+	if err != nil {
+		return "", err
+	}
+	return defaultRouteIface(routes, getExcludeInterfacePatterns())
+}
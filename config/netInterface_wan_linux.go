@@ -0,0 +1,14 @@
+//go:build linux
+
+package config
+
+import "os"
+
+// readIpv6Routes 读取内核IPv6路由表
+func readIpv6Routes() ([]ipv6Route, error) {
+	byt, err := os.ReadFile("/proc/net/ipv6_route")
+	if err != nil {
+		return nil, err
+	}
+	return parseIpv6Routes(string(byt)), nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package config
+
+import "errors"
+
+// readIpv6Routes 仅 Linux 支持读取路由表
+func readIpv6Routes() ([]ipv6Route, error) {
+	return nil, errors.New("reading IPv6 routes is only supported on Linux")
+}
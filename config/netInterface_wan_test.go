@@ -0,0 +1,47 @@
+package config
+
+import (
+	"net"
+	"testing"
+)
+
+const testIpv6Routes = `2408820000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00040001     ppp0
+24088200123400000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00440001     ppp0
+fd000000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00040001     ppp0
+fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000002 00000000 00000001     ppp0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00450003     ppp0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo
+`
+
+// TestDefaultRouteIface 测试获取默认路由所在的网卡
+func TestDefaultRouteIface(t *testing.T) {
+	iface, err := defaultRouteIface(parseIpv6Routes(testIpv6Routes), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface != "ppp0" {
+		t.Errorf("期待网卡 ppp0, 得到 %s", iface)
+	}
+
+	if _, err := defaultRouteIface(parseIpv6Routes(testIpv6Routes), []string{"ppp*"}); err == nil {
+		t.Error("默认路由所在的网卡被排除时期待返回错误")
+	}
+
+	if _, err := defaultRouteIface(nil, nil); err == nil {
+		t.Error("没有默认路由时期待返回错误")
+	}
+}
+
+// TestRaPrefixes 测试获取路由通告的前缀
+func TestRaPrefixes(t *testing.T) {
+	prefixes := raPrefixes(parseIpv6Routes(testIpv6Routes), "ppp0")
+	// 第一行格式不正确, fd00::/64 不是全局单播地址
+	if len(prefixes) != 1 || prefixes[0].String() != "2408:8200:1234::/64" {
+		t.Errorf("期待前缀 [2408:8200:1234::/64], 得到 %v", prefixes)
+	}
+
+	addr := addrFromPrefix(prefixes[0], net.ParseIP("fe80::1a2b:3cff:fe4d:5e6f"))
+	if addr.String() != "2408:8200:1234:0:1a2b:3cff:fe4d:5e6f" {
+		t.Errorf("期待地址 2408:8200:1234:0:1a2b:3cff:fe4d:5e6f, 得到 %s", addr)
+	}
+}
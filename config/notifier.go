@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// notifier 通知渠道, 实现后即可用于更新后的通知和页面中的测试
+type notifier interface {
+	// name 渠道名称, 测试时使用
+	name() string
+	// enabled 是否已配置
+	enabled() bool
+	// notify 发送消息, 返回发送结果
+	notify(domains *Domains, event webhookEvent, v4Status updateStatusType, v6Status updateStatusType) error
+}
+
+// eventNotifiers IP改变/更新失败时通知的渠道
+func (conf *Config) eventNotifiers() []notifier {
+	return []notifier{&conf.Webhook, &conf.MQTT}
+}
+
+// shutdownNotifier 进程退出前调用的Webhook, 发送汇总的结果
+type shutdownNotifier struct {
+	*Webhook
+}
+
+func (s shutdownNotifier) name() string {
+	return "shutdown"
+}
+
+func (s shutdownNotifier) enabled() bool {
+	return s.ShutdownWebhookURL != ""
+}
+
+func (s shutdownNotifier) notify(*Domains, webhookEvent, updateStatusType, updateStatusType) error {
+	return sendWebhook(
+		replaceShutdownPara(s.ShutdownWebhookURL),
+		replaceShutdownPara(s.ShutdownWebhookRequestBody),
+		s.WebhookHeaders,
+	)
+}
+
+// SendTestNotification 使用模拟数据通过 channel(webhook/mqtt/shutdown) 发送一条测试消息, 返回发送结果
+func SendTestNotification(conf *Config, channel string) error {
+	// 页面中未保存的密钥也需在日志中隐藏
+	saved, _ := GetConfigCached()
+	util.SetLogSecrets(append(saved.GetSecrets(), conf.GetSecrets()...))
+
+	for _, n := range append(conf.eventNotifiers(), shutdownNotifier{&conf.Webhook}) {
+		if n.name() != channel {
+			continue
+		}
+		if !n.enabled() {
+			return errors.New(util.LogStr("通知渠道 %s 未配置", channel))
+		}
+		return n.notify(testDomains(), WebhookEventChanged, UpdatedSuccess, UpdatedSuccess)
+	}
+	return errors.New(util.LogStr("不支持的通知渠道 %s", channel))
+}
+
+// testDomains 测试通知使用的模拟数据
+func testDomains() *Domains {
+	domains := []*Domain{{DomainName: "example.com", SubDomain: "test", UpdateStatus: UpdatedSuccess}}
+	return &Domains{
+		Ipv4Addr:    "127.0.0.1",
+		Ipv4Domains: domains,
+		Ipv6Addr:    "::1",
+		Ipv6Domains: domains,
+		Ipv4Source:  "url:https://api.ipify.org",
+		Ipv6Source:  "netInterface:eth0",
+	}
+}
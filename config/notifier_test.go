@@ -0,0 +1,37 @@
+package config
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSendTestNotification 测试通过指定渠道发送测试消息并返回结果
+func TestSendTestNotification(t *testing.T) {
+	var body string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	conf := &Config{Webhook: Webhook{WebhookURL: server.URL, WebhookRequestBody: "#{ipv4Addr} #{event}"}}
+	if err := SendTestNotification(conf, "webhook"); err != nil || body != "127.0.0.1 changed" {
+		t.Errorf("期待发送成功, 得到 %q, %v", body, err)
+	}
+
+	status = http.StatusInternalServerError
+	if err := SendTestNotification(conf, "webhook"); err == nil {
+		t.Error("Webhook返回500时应返回错误")
+	}
+
+	if err := SendTestNotification(conf, "mqtt"); err == nil {
+		t.Error("未配置的渠道应返回错误")
+	}
+	if err := SendTestNotification(conf, "unknown"); err == nil {
+		t.Error("不支持的渠道应返回错误")
+	}
+}
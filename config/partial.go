@@ -0,0 +1,76 @@
+package config
+
+import (
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// 同时启用IPv4/IPv6但只获取到其中一个时的处理方式, 为空时未获取到的类型与以前一样视为失败
+const (
+	// PartialKeep 只更新获取到的类型, 保留另一个类型的记录, 不视为失败
+	PartialKeep = "keep"
+	// PartialDelete 只更新获取到的类型, 删除另一个类型的记录
+	PartialDelete = "delete"
+	// PartialSkip 跳过本次更新
+	PartialSkip = "skip"
+)
+
+// applyPartial 同时启用IPv4/IPv6但只获取到其中一个时, 按 Partial 处理
+func (domains *Domains) applyPartial(dnsConf *DnsConfig) {
+	domains.deleteType = ""
+	if dnsConf.Partial == "" ||
+		!dnsConf.Ipv4.Enable || len(domains.Ipv4Domains) == 0 ||
+		!dnsConf.Ipv6.Enable || len(domains.Ipv6Domains) == 0 ||
+		(domains.Ipv4Addr == "") == (domains.Ipv6Addr == "") {
+		return
+	}
+
+	family, recordType, cache, list := "IPv6", "AAAA", domains.Ipv6Cache, domains.Ipv6Domains
+	if domains.Ipv4Addr == "" {
+		family, recordType, cache, list = "IPv4", "A", domains.Ipv4Cache, domains.Ipv4Domains
+	}
+
+	switch dnsConf.Partial {
+	case PartialKeep:
+		util.Log("未获取到%s地址, 将保留%s记录", family, recordType)
+	case PartialDelete:
+		util.Log("未获取到%s地址, 将删除%s记录", family, recordType)
+		domains.deleteType = recordType
+	case PartialSkip:
+		util.Log("未获取到%s地址, 将跳过本次更新", family)
+		domains.Ipv4Addr, domains.Ipv6Addr = "", ""
+		return
+	default:
+		util.Log("只获取到IPv4/IPv6其中一个时的处理方式 %s 不正确, 可选 keep/delete/skip", dnsConf.Partial)
+		return
+	}
+	// 不视为失败
+	cache.TimesFailedIP = 0
+	list[0].UpdateStatus = ""
+}
+
+// GetDeleteDomains 返回需要删除记录的域名:
+// Partial 为 delete 且未获取到该类型的IP时的所有域名; 连续3次未获取到或只有链路本地/ULA地址时, 添加了 missing=delete 的域名
+func (domains *Domains) GetDeleteDomains(recordType string) []*Domain {
+	family, addr, cache, list := "IPv4", domains.Ipv4Addr, domains.Ipv4Cache, domains.Ipv4Domains
+	if recordType == "AAAA" {
+		family, addr, cache, list = "IPv6", domains.Ipv6Addr, domains.Ipv6Cache, domains.Ipv6Domains
+	}
+	if domains.deleteType == recordType {
+		return list
+	}
+	// 偶尔获取失败时不删除
+	if addr != "" || cache == nil || (cache.TimesFailedIP < 3 && !cache.LocalOnly) {
+		return nil
+	}
+
+	var result []*Domain
+	for _, domain := range list {
+		if domain.deleteMissing {
+			result = append(result, domain)
+		}
+	}
+	if len(result) > 0 {
+		util.Log("未获取到%s地址, 将删除添加了 missing=delete 的域名的%s记录", family, recordType)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestApplyPartial 测试只获取到IPv4时按 Partial 处理IPv6
+func TestApplyPartial(t *testing.T) {
+	newDomains := func() (*Domains, *DnsConfig) {
+		conf := &DnsConfig{}
+		conf.Ipv4.Enable, conf.Ipv6.Enable = true, true
+		domains := &Domains{
+			Ipv4Addr:    "1.2.3.4",
+			Ipv4Cache:   &util.IpCache{},
+			Ipv4Domains: []*Domain{{DomainName: "example.com"}},
+			Ipv6Cache:   &util.IpCache{TimesFailedIP: 3},
+			Ipv6Domains: []*Domain{{DomainName: "example.com", UpdateStatus: UpdatedFailed}},
+		}
+		return domains, conf
+	}
+
+	domains, conf := newDomains()
+	domains.applyPartial(conf)
+	if domains.Ipv6Domains[0].UpdateStatus != UpdatedFailed || domains.GetDeleteDomains("AAAA") != nil {
+		t.Error("未配置时应与以前一样视为失败")
+	}
+
+	domains, conf = newDomains()
+	conf.Partial = PartialKeep
+	domains.applyPartial(conf)
+	if domains.Ipv6Domains[0].UpdateStatus != "" || domains.Ipv6Cache.TimesFailedIP != 0 || domains.GetDeleteDomains("AAAA") != nil {
+		t.Error("keep 时不应视为失败, 也不删除记录")
+	}
+
+	domains, conf = newDomains()
+	conf.Partial = PartialDelete
+	domains.applyPartial(conf)
+	if domains.Ipv6Domains[0].UpdateStatus != "" || len(domains.GetDeleteDomains("AAAA")) != 1 || domains.GetDeleteDomains("A") != nil {
+		t.Error("delete 时应只删除AAAA记录")
+	}
+
+	domains, conf = newDomains()
+	conf.Partial = PartialSkip
+	domains.applyPartial(conf)
+	if domains.Ipv4Addr != "" || domains.Ipv6Domains[0].UpdateStatus != UpdatedFailed {
+		t.Error("skip 时应跳过IPv4更新, IPv6仍视为失败")
+	}
+
+	// 只启用了IPv4时不处理
+	domains, conf = newDomains()
+	conf.Partial = PartialSkip
+	conf.Ipv6.Enable = false
+	domains.applyPartial(conf)
+	if domains.Ipv4Addr != "1.2.3.4" {
+		t.Error("只启用一个类型时不应跳过")
+	}
+}
+
+// TestGetDeleteDomainsMissing 测试多次未获取到IP时只删除添加了 missing=delete 的域名的记录
+func TestGetDeleteDomainsMissing(t *testing.T) {
+	list := checkParseDomains([]string{"a.example.com?missing=delete", "b.example.com"})
+	if list[0].CustomParams != "" {
+		t.Errorf("missing 不应传递给DNS服务商, 得到 %s", list[0].CustomParams)
+	}
+
+	tests := map[string]struct {
+		addr   string
+		cache  util.IpCache
+		expect int
+	}{
+		"获取到IP时不删除":       {"2001:db8::1", util.IpCache{}, 0},
+		"偶尔获取失败时不删除":      {"", util.IpCache{TimesFailedIP: 1}, 0},
+		"连续3次获取失败时删除":     {"", util.IpCache{TimesFailedIP: 3}, 1},
+		"只有链路本地/ULA地址时删除": {"", util.IpCache{LocalOnly: true}, 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			domains := &Domains{Ipv6Addr: tt.addr, Ipv6Cache: &tt.cache, Ipv6Domains: list}
+			result := domains.GetDeleteDomains("AAAA")
+			if len(result) != tt.expect || (tt.expect == 1 && result[0] != list[0]) {
+				t.Errorf("期待删除 %d 个域名, 得到 %v", tt.expect, result)
+			}
+		})
+	}
+}
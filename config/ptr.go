@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// PTR IP改变时通过服务商API更新IP的反向解析(rDNS), 如运行邮件服务器
+type PTR struct {
+	// PTRProvider 服务商, 目前支持 vultr, 为空不更新
+	PTRProvider string
+	PTRAPIKey   string
+	// PTRInstanceID 服务器实例ID
+	PTRInstanceID string
+	// PTRHostname 反向解析的域名, 如 mail.example.com
+	PTRHostname string
+}
+
+var vultrAPI = "https://api.vultr.com/v2"
+
+// 上次成功更新反向解析的IP
+var lastPTR = struct {
+	sync.Mutex
+	ipv4Addr string
+	ipv6Addr string
+}{}
+
+// ExecPTR 当IP改变时更新反向解析, 失败时下次运行重试
+func ExecPTR(ipv4Addr, ipv6Addr string, conf *Config) {
+	if conf.PTRProvider == "" {
+		return
+	}
+	if conf.PTRProvider != "vultr" {
+		util.Log("不支持的反向解析服务商 %s", conf.PTRProvider)
+		return
+	}
+
+	lastPTR.Lock()
+	defer lastPTR.Unlock()
+
+	for _, f := range []struct {
+		family string
+		addr   string
+		last   *string
+	}{
+		{"ipv4", ipv4Addr, &lastPTR.ipv4Addr},
+		{"ipv6", ipv6Addr, &lastPTR.ipv6Addr},
+	} {
+		if f.addr == "" || f.addr == *f.last {
+			continue
+		}
+		err := conf.setVultrReverse(f.family, f.addr)
+		if err != nil {
+			util.Log("更新 %s 的反向解析失败! 异常信息: %s", f.addr, err)
+			continue
+		}
+		*f.last = f.addr
+		util.Log("已更新 %s 的反向解析为 %s", f.addr, conf.PTRHostname)
+	}
+}
+
+// setVultrReverse 设置 Vultr 实例IP的反向解析
+// https://www.vultr.com/api/#tag/instances/operation/post-instances-instance-id-ipv4-reverse
+func (ptr *PTR) setVultrReverse(family, addr string) error {
+	body, _ := json.Marshal(map[string]string{
+		"ip":      addr,
+		"reverse": ptr.PTRHostname,
+	})
+	req, err := http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/instances/%s/%s/reverse", vultrAPI, url.PathEscape(ptr.PTRInstanceID), family),
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+ptr.PTRAPIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := util.CreateHTTPClient()
+	_, err = util.GetHTTPResponseOrg(client.Do(req))
+	return err
+}
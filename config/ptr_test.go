@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestExecPTR 测试IP改变时更新 Vultr 的反向解析, 失败时下次重试
+func TestExecPTR(t *testing.T) {
+	var paths []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["reverse"] != "mail.example.com" {
+			t.Errorf("反向解析的域名错误: %v", body)
+		}
+		paths = append(paths, r.URL.Path+" "+body["ip"])
+		if fail && body["ip"] == "2001:db8::1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	oldAPI := vultrAPI
+	vultrAPI = server.URL
+	defer func() {
+		vultrAPI = oldAPI
+		lastPTR.ipv4Addr, lastPTR.ipv6Addr = "", ""
+	}()
+
+	conf := &Config{PTR: PTR{
+		PTRProvider:   "vultr",
+		PTRAPIKey:     "key",
+		PTRInstanceID: "abc",
+		PTRHostname:   "mail.example.com",
+	}}
+	ExecPTR("1.2.3.4", "2001:db8::1", conf)
+	fail = false
+	// IPv4 未改变, 只重试失败的 IPv6
+	ExecPTR("1.2.3.4", "2001:db8::1", conf)
+	ExecPTR("", "2001:db8::1", conf)
+
+	want := []string{
+		"/instances/abc/ipv4/reverse 1.2.3.4",
+		"/instances/abc/ipv6/reverse 2001:db8::1",
+		"/instances/abc/ipv6/reverse 2001:db8::1",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("期待请求 %v, 实际: %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("期待请求 %s, 实际: %s", want[i], paths[i])
+		}
+	}
+}
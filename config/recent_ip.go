@@ -0,0 +1,100 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// RecentIP 不再发布最近发布过的IP, 用于双WAN等IP在几个地址之间来回切换时只发布新的地址
+type RecentIP struct {
+	// Count 检查最近发布过的几个不同的IP(包括当前发布的IP), 为0时不检查
+	Count int
+	// Window 只检查该小时数内发布过的IP, 为0时不限制时间
+	Window int
+}
+
+// publishedIP 更新成功的IP及最后一次发布的时间
+type publishedIP struct {
+	addr string
+	time time.Time
+}
+
+// publishedIPs 每个记录最近发布过的不同的IP, 按时间从早到晚, 最多 ipHistoryMax 个
+var publishedIPs = struct {
+	sync.Mutex
+	records map[string][]publishedIP
+}{records: map[string][]publishedIP{}}
+
+func publishedKey(provider string, domain *Domain, recordType string) string {
+	return strings.Join([]string{provider, domain.String(), recordType}, "|")
+}
+
+// RecordPublishedIP 记录本轮更新成功的域名的IP, 见 RecentIP
+func RecordPublishedIP(provider string, domains *Domains) {
+	now := time.Now()
+
+	publishedIPs.Lock()
+	defer publishedIPs.Unlock()
+
+	record := func(list []*Domain, recordType string, ipAddr string) {
+		for _, d := range list {
+			if d.UpdateStatus != UpdatedSuccess {
+				continue
+			}
+			key := publishedKey(provider, d, recordType)
+			values := publishedIPs.records[key]
+			for i, v := range values {
+				if util.SameIP(v.addr, ipAddr) {
+					values = append(values[:i:i], values[i+1:]...)
+					break
+				}
+			}
+			values = append(values, publishedIP{addr: ipAddr, time: now})
+			if len(values) > ipHistoryMax {
+				values = values[len(values)-ipHistoryMax:]
+			}
+			publishedIPs.records[key] = values
+		}
+	}
+	record(domains.Ipv4Domains, "A", domains.Ipv4Addr)
+	record(domains.Ipv6Domains, "AAAA", domains.Ipv6Addr)
+}
+
+// filterRecentIP 去掉 ipAddr 为最近 Count 个发布过的IP之一(当前发布的IP除外)的域名
+func (domains *Domains) filterRecentIP(recordType string, ipAddr string, list []*Domain, now time.Time) (result []*Domain) {
+	recent := domains.recentIP
+	if ipAddr == "" || recent.Count <= 0 {
+		return list
+	}
+
+	publishedIPs.Lock()
+	defer publishedIPs.Unlock()
+
+	for _, domain := range list {
+		values := publishedIPs.records[publishedKey(domains.provider, domain, recordType)]
+		if len(values) > recent.Count {
+			values = values[len(values)-recent.Count:]
+		}
+		// 与当前发布的IP相同时正常比对
+		if len(values) > 0 && util.SameIP(values[len(values)-1].addr, ipAddr) {
+			result = append(result, domain)
+			continue
+		}
+
+		suppressed := false
+		for _, v := range values {
+			if util.SameIP(v.addr, ipAddr) && (recent.Window <= 0 || now.Sub(v.time) <= time.Duration(recent.Window)*time.Hour) {
+				util.Log("%s 为域名 %s 最近发布过的IP, 上次发布时间 %s, 将不会更新", ipAddr, domain, v.time.Format("2006-01-02 15:04:05"))
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			result = append(result, domain)
+		}
+	}
+	return
+}
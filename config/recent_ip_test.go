@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFilterRecentIP 测试IP在两个地址之间来回切换时不再发布最近发布过的IP
+func TestFilterRecentIP(t *testing.T) {
+	domain := &Domain{DomainName: "example.com", SubDomain: "recent"}
+	domains := &Domains{provider: "recent-test", Ipv4Domains: []*Domain{domain}, recentIP: RecentIP{Count: 2, Window: 1}}
+	publish := func(addr string) {
+		domains.Ipv4Addr = addr
+		domain.UpdateStatus = UpdatedSuccess
+		RecordPublishedIP("recent-test", domains)
+	}
+	publish("1.1.1.1")
+	publish("2.2.2.2")
+
+	tests := map[string]struct {
+		addr    string
+		recent  RecentIP
+		now     time.Time
+		publish bool
+	}{
+		"新的IP":        {"3.3.3.3", RecentIP{Count: 2, Window: 1}, time.Now(), true},
+		"当前发布的IP":     {"2.2.2.2", RecentIP{Count: 2, Window: 1}, time.Now(), true},
+		"最近发布过的IP":    {"1.1.1.1", RecentIP{Count: 2, Window: 1}, time.Now(), false},
+		"超过时间窗口":      {"1.1.1.1", RecentIP{Count: 2, Window: 1}, time.Now().Add(2 * time.Hour), true},
+		"不在最近Count个中": {"1.1.1.1", RecentIP{Count: 1}, time.Now(), true},
+		"未配置":         {"1.1.1.1", RecentIP{}, time.Now(), true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			domains.recentIP = tt.recent
+			result := domains.filterRecentIP("A", tt.addr, domains.Ipv4Domains, tt.now)
+			if (len(result) == 1) != tt.publish {
+				t.Errorf("期待发布 %v, 得到 %v", tt.publish, result)
+			}
+		})
+	}
+
+	// 重新发布后移到最后
+	publish("1.1.1.1")
+	domains.recentIP = RecentIP{Count: 2}
+	if len(domains.filterRecentIP("A", "2.2.2.2", domains.Ipv4Domains, time.Now())) != 0 {
+		t.Error("2.2.2.2 为最近发布过的IP, 不应发布")
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import "strings"
+
+// RecordName 发送给DNS服务商前转换记录名称, 使配置中的名称与实际的DNS记录名称不同
+type RecordName struct {
+	// Prefix 添加到子域名前, 如 prod- : web.example.com => prod-web.example.com
+	Prefix string
+	// Suffix 添加到子域名后, 如 -v6 : web.example.com => web-v6.example.com
+	Suffix string
+	// Lowercase 子域名和根域名转为小写
+	Lowercase bool
+}
+
+// transformRecordName 转换域名的子域名/根域名, 根域名(@)不添加前后缀
+func transformRecordName(domains []*Domain, rn RecordName) []*Domain {
+	for _, domain := range domains {
+		if domain.SubDomain != "" {
+			domain.SubDomain = rn.Prefix + domain.SubDomain + rn.Suffix
+		}
+		if rn.Lowercase {
+			domain.SubDomain = strings.ToLower(domain.SubDomain)
+			domain.DomainName = strings.ToLower(domain.DomainName)
+		}
+	}
+	return domains
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+// TestTransformRecordName 测试记录名称转换
+func TestTransformRecordName(t *testing.T) {
+	tests := []struct {
+		rn     RecordName
+		domain string
+		want   string
+	}{
+		{RecordName{}, "web.example.com", "web.example.com"},
+		{RecordName{Prefix: "prod-"}, "web.example.com", "prod-web.example.com"},
+		{RecordName{Suffix: "-v6"}, "web.example.com", "web-v6.example.com"},
+		{RecordName{Prefix: "a-", Suffix: "-b"}, "x.y:example.com", "a-x.y-b.example.com"},
+		{RecordName{Prefix: "prod-", Suffix: "-v6"}, "example.com", "@.example.com"},
+		{RecordName{Lowercase: true}, "Web.Example.COM", "web.example.com"},
+		{RecordName{Prefix: "Prod-", Lowercase: true}, "WEB:Example.com", "prod-web.example.com"},
+	}
+
+	for _, tt := range tests {
+		domains := transformRecordName(checkParseDomains([]string{tt.domain}), tt.rn)
+		if len(domains) != 1 {
+			t.Fatalf("域名 %s 解析失败", tt.domain)
+		}
+		if got := domains[0].GetFullDomain(); got != tt.want {
+			t.Errorf("transformRecordName(%q, %+v) = %q, want %q", tt.domain, tt.rn, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"net/url"
+)
+
+// secretFields 整个值都是密钥的配置项, 备份和原始配置中清空, 日志中隐藏, 见 GetSecrets 和 Redacted
+// DNS配置的 Secret 和代理地址中的密码另外处理
+func (conf *Config) secretFields() []*string {
+	return []*string{
+		&conf.MQTTPassword,
+		&conf.PTRAPIKey,
+		&conf.WebhookURL,
+		&conf.WebhookHeaders,
+		&conf.ShutdownWebhookURL,
+	}
+}
+
+// proxyPassword 代理地址中的密码
+func proxyPassword(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	password, _ := u.User.Password()
+	return password
+}
+
+// redactProxy 去掉代理地址中的密码, 保留用户名
+func redactProxy(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return proxy
+	}
+	if _, ok := u.User.Password(); !ok {
+		return proxy
+	}
+	u.User = url.User(u.User.Username())
+	return u.String()
+}
+
+// Redacted 返回不包含密钥的配置, 用于备份和原始配置, 隐藏的内容与 GetSecrets 一致
+// 保留DNS配置的 ID, 恢复时按 ID 找回 Secret, 见 KeepSecrets
+func (conf Config) Redacted() Config {
+	conf.Password = ""
+	for _, field := range conf.secretFields() {
+		*field = ""
+	}
+	conf.Proxy = redactProxy(conf.Proxy)
+	// 复制一份, 避免修改缓存中的配置
+	conf.DnsConf = append([]DnsConfig{}, conf.DnsConf...)
+	for i := range conf.DnsConf {
+		conf.DnsConf[i].DNS.Secret = ""
+	}
+	return conf
+}
+
+// KeepSecrets 为空的密钥使用 old 中的值, 用于恢复 Redacted 的配置
+// DNS配置按服务商和 ID 找回 Secret, 找不到时保持为空, 不会使用其它帐号的 Secret
+func (conf *Config) KeepSecrets(old *Config) {
+	oldFields := old.secretFields()
+	for i, field := range conf.secretFields() {
+		if *field == "" {
+			*field = *oldFields[i]
+		}
+	}
+	if conf.Proxy != "" && conf.Proxy == redactProxy(old.Proxy) {
+		conf.Proxy = old.Proxy
+	}
+	for i := range conf.DnsConf {
+		dns := &conf.DnsConf[i].DNS
+		if dns.Secret != "" || dns.ID == "" {
+			continue
+		}
+		for _, oldConf := range old.DnsConf {
+			if oldConf.DNS.Name == dns.Name && oldConf.DNS.ID == dns.ID {
+				dns.Secret = oldConf.DNS.Secret
+				break
+			}
+		}
+	}
+}
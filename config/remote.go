@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+	"gopkg.in/yaml.v3"
+)
+
+// lastRemoteConfig 上次应用的远程配置, 本地配置文件被改写(如密码加密)后不会被当作变化
+var lastRemoteConfig = struct {
+	sync.Mutex
+	byt []byte
+}{}
+
+// remoteHeaderSecrets -config-header 请求头的值, 需要在日志中隐藏
+var remoteHeaderSecrets = struct {
+	sync.Mutex
+	values []string
+}{}
+
+func getRemoteHeaderSecrets() []string {
+	remoteHeaderSecrets.Lock()
+	defer remoteHeaderSecrets.Unlock()
+	return remoteHeaderSecrets.values
+}
+
+// setRemoteHeaderSecrets 在日志中隐藏 headers 的值, 尚未读取配置文件时也生效
+func setRemoteHeaderSecrets(headers []string) {
+	remoteHeaderSecrets.Lock()
+	remoteHeaderSecrets.values = headerSecrets(headers)
+	remoteHeaderSecrets.Unlock()
+
+	cache.Lock.Lock()
+	defer cache.Lock.Unlock()
+	var conf Config
+	if cache.ConfigSingle != nil {
+		conf = *cache.ConfigSingle
+	}
+	util.SetLogSecrets(conf.GetSecrets())
+}
+
+// FetchRemoteConfig 从 configURL 获取配置并校验, 与本地配置文件不同时写入本地配置文件并清空配置缓存。
+// 本地配置文件同时作为缓存, 获取失败时继续使用本地配置文件。headers 为 "Name: Value" 格式的请求头
+func FetchRemoteConfig(configURL string, headers []string) (changed bool, err error) {
+	setRemoteHeaderSecrets(headers)
+	req, err := http.NewRequest(http.MethodGet, configURL, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return false, fmt.Errorf("invalid header %q, expected Name: Value", header)
+		}
+		req.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	client := util.CreateHTTPClient()
+	resp, err := client.Do(req)
+	byt, err := util.GetHTTPResponseOrg(resp, err)
+	if err != nil {
+		return false, err
+	}
+	if err = validateConfig(byt); err != nil {
+		return false, err
+	}
+
+	lastRemoteConfig.Lock()
+	defer lastRemoteConfig.Unlock()
+	if bytes.Equal(byt, lastRemoteConfig.byt) {
+		return false, nil
+	}
+
+	cache.Lock.Lock()
+	defer cache.Lock.Unlock()
+	configFilePath := util.GetConfigFilePath()
+	if local, err := os.ReadFile(configFilePath); err != nil || !bytes.Equal(local, byt) {
+		if err = os.WriteFile(configFilePath, byt, 0600); err != nil {
+			return false, err
+		}
+		// 清空配置缓存
+		cache.ConfigSingle = nil
+		changed = true
+	}
+	lastRemoteConfig.byt = byt
+	return changed, nil
+}
+
+// validateConfig 校验配置文件的内容
+func validateConfig(byt []byte) error {
+	var conf Config
+	if err := yaml.Unmarshal(byt, &conf); err != nil {
+		return err
+	}
+	for i, dc := range conf.DnsConf {
+		if dc.DNS.Name == "" {
+			return fmt.Errorf("dnsconf[%d]: dns.name is empty", i)
+		}
+	}
+	if len(conf.DnsConf) == 0 {
+		return errors.New("dnsconf is empty")
+	}
+	// 代理不正确时保留之前的配置, 避免重启时无法启动
+	if _, err := util.ParseProxy(conf.Proxy); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// filterSchedule 通过 ?cron=表达式 限制域名只在满足表达式的时间内更新, 去掉当前不允许更新的域名
+// 有域名被推迟时下次运行重新检查, 不等待IP缓存次数。表达式不正确时不限制
+func filterSchedule(ipAddr string, cache *util.IpCache, domains []*Domain, now time.Time) (result []*Domain) {
+	if ipAddr == "" {
+		return domains
+	}
+
+	for _, domain := range domains {
+		if domain.schedule == "" {
+			result = append(result, domain)
+			continue
+		}
+
+		cron, err := util.ParseCron(domain.schedule)
+		if err != nil {
+			util.Log("域名 %s 的更新时间不正确, 将不限制更新时间. 异常信息: %s", domain, err)
+			result = append(result, domain)
+			continue
+		}
+		if !cron.Match(now) {
+			util.Log("当前不在域名 %s 允许更新的时间 %s 内, 将推迟更新", domain, domain.schedule)
+			cache.Times = 0
+			continue
+		}
+		result = append(result, domain)
+	}
+	return
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestFilterSchedule 测试不在允许更新的时间内的域名推迟更新
+func TestFilterSchedule(t *testing.T) {
+	domains := checkParseDomains([]string{
+		"a.example.com?cron=* 2-4 * * *",
+		"b.example.com?cron=*+22-23+*+*+*&Line=oversea",
+		"c.example.com?cron=invalid",
+		"d.example.com",
+	})
+	if domains[1].schedule != "* 22-23 * * *" || domains[1].CustomParams != "Line=oversea" {
+		t.Fatalf("解析 cron 参数错误: %q, %q", domains[1].schedule, domains[1].CustomParams)
+	}
+
+	cache := &util.IpCache{}
+	cache.Check("1.2.3.4")
+	now := time.Date(2024, 1, 15, 3, 10, 0, 0, time.Local)
+	result := filterSchedule("1.2.3.4", cache, domains, now)
+
+	var names []string
+	for _, d := range result {
+		names = append(names, d.String())
+	}
+	if len(names) != 3 || names[0] != "a.example.com" || names[1] != "c.example.com" || names[2] != "d.example.com" {
+		t.Errorf("期待更新 a/c/d, 实际: %v", names)
+	}
+	if !cache.Check("1.2.3.4") {
+		t.Error("有域名被推迟时下次运行应重新检查")
+	}
+}
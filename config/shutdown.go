@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// runSummary 本次运行中每个域名的更新结果
+var runSummary = struct {
+	sync.Mutex
+	order   []string
+	results map[string]updateStatusType
+}{results: map[string]updateStatusType{}}
+
+var shutdownOnce sync.Once
+
+// RecordRunResult 记录本轮的更新结果, 保留每个域名最后一次有变化的结果
+func RecordRunResult(domains *Domains) {
+	runSummary.Lock()
+	defer runSummary.Unlock()
+
+	record := func(list []*Domain, recordType string) {
+		for _, d := range list {
+			key := d.String() + " (" + recordType + ")"
+			status, ok := runSummary.results[key]
+			if !ok {
+				runSummary.order = append(runSummary.order, key)
+				status = UpdatedNothing
+			}
+			if d.UpdateStatus != "" && d.UpdateStatus != UpdatedNothing {
+				status = d.UpdateStatus
+			}
+			runSummary.results[key] = status
+		}
+	}
+	record(domains.Ipv4Domains, "A")
+	record(domains.Ipv6Domains, "AAAA")
+}
+
+// ExecShutdownWebhook 进程退出前调用Webhook, 发送本次运行结果的汇总, 只会调用一次
+func ExecShutdownWebhook(conf *Config) {
+	n := shutdownNotifier{&conf.Webhook}
+	if !n.enabled() {
+		return
+	}
+	shutdownOnce.Do(func() {
+		n.notify(nil, "", "", "")
+	})
+}
+
+// replaceShutdownPara 替换汇总结果的参数
+func replaceShutdownPara(orgPara string) string {
+	if orgPara == "" {
+		return ""
+	}
+
+	runSummary.Lock()
+	defer runSummary.Unlock()
+
+	counts := map[updateStatusType]int{}
+	results := make([]string, 0, len(runSummary.order))
+	for _, key := range runSummary.order {
+		status := runSummary.results[key]
+		counts[status]++
+		results = append(results, key+": "+util.LogStr(string(status)))
+	}
+
+	result := UpdatedNothing
+	if counts[UpdatedFailed] > 0 {
+		result = UpdatedFailed
+	} else if counts[UpdatedSuccess] > 0 {
+		result = UpdatedSuccess
+	}
+
+	return strings.NewReplacer(
+		"#{result}", util.LogStr(string(result)), // i18n
+		"#{successCount}", strconv.Itoa(counts[UpdatedSuccess]),
+		"#{failedCount}", strconv.Itoa(counts[UpdatedFailed]),
+		"#{nothingCount}", strconv.Itoa(counts[UpdatedNothing]),
+		"#{deletedCount}", strconv.Itoa(counts[UpdatedDeleted]),
+		"#{results}", strings.Join(results, ","),
+	).Replace(orgPara)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// updateHistoryMax 内存中保留的更新历史条数
+const updateHistoryMax = 1000
+
+// UpdateHistory 一次域名更新的记录
+type UpdateHistory struct {
+	Time       time.Time
+	Provider   string
+	Domain     string
+	RecordType string
+	// OldIP 服务商提供的更新前记录的值, 未提供时为本次启动后该域名上一次更新成功的IP, 都没有时为空
+	OldIP  string
+	NewIP  string
+	Status updateStatusType
+	// HTTP 本次运行该DNS配置时HTTP请求的次数、重试次数和耗时
+	HTTP util.HTTPStats
+}
+
+var updateHistories = struct {
+	sync.Mutex
+	list []UpdateHistory
+	// lastIP 每个域名最后一次更新成功的IP
+	lastIP map[string]string
+}{lastIP: map[string]string{}}
+
+// RecordUpdateHistory 记录本轮有更新(成功/失败等)的域名
+// stats 为本次运行该DNS配置时的HTTP请求统计
+func RecordUpdateHistory(provider string, domains *Domains, stats util.HTTPStats) {
+	now := time.Now()
+
+	updateHistories.Lock()
+	defer updateHistories.Unlock()
+
+	record := func(list []*Domain, recordType string, ipAddr string) {
+		for _, d := range list {
+			if d.UpdateStatus == "" || d.UpdateStatus == UpdatedNothing {
+				continue
+			}
+			key := provider + "|" + d.String() + "|" + recordType
+			oldIP := d.OldIP
+			if oldIP == "" {
+				oldIP = updateHistories.lastIP[key]
+			}
+			newIP := ipAddr
+			if d.UpdateStatus == UpdatedDeleted {
+				// 删除了记录, 没有发布新的IP
+				newIP = ""
+				delete(updateHistories.lastIP, key)
+			}
+			updateHistories.list = append(updateHistories.list, UpdateHistory{
+				Time:       now,
+				Provider:   provider,
+				Domain:     d.String(),
+				RecordType: recordType,
+				OldIP:      oldIP,
+				NewIP:      newIP,
+				Status:     d.UpdateStatus,
+				HTTP:       stats,
+			})
+			if d.UpdateStatus == UpdatedSuccess {
+				updateHistories.lastIP[key] = ipAddr
+			}
+		}
+	}
+	record(domains.Ipv4Domains, "A", domains.Ipv4Addr)
+	record(domains.Ipv6Domains, "AAAA", domains.Ipv6Addr)
+
+	if len(updateHistories.list) > updateHistoryMax {
+		updateHistories.list = updateHistories.list[len(updateHistories.list)-updateHistoryMax:]
+	}
+}
+
+// GetUpdateHistory 返回更新历史, 按时间从早到晚
+func GetUpdateHistory() []UpdateHistory {
+	updateHistories.Lock()
+	defer updateHistories.Unlock()
+	return append([]UpdateHistory{}, updateHistories.list...)
+}
+
+// SetUpdateHistory 替换更新历史, 用于从备份恢复
+func SetUpdateHistory(list []UpdateHistory) {
+	if len(list) > updateHistoryMax {
+		list = list[len(list)-updateHistoryMax:]
+	}
+
+	updateHistories.Lock()
+	defer updateHistories.Unlock()
+
+	updateHistories.list = append([]UpdateHistory{}, list...)
+	updateHistories.lastIP = map[string]string{}
+	for _, h := range list {
+		if h.Status == UpdatedSuccess {
+			updateHistories.lastIP[h.Provider+"|"+h.Domain+"|"+h.RecordType] = h.NewIP
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestRecordUpdateHistory 测试只记录有更新的域名, 并带上一次更新成功的IP
+func TestRecordUpdateHistory(t *testing.T) {
+	domain := &Domain{DomainName: "example.com", SubDomain: "history"}
+	domains := &Domains{Ipv4Addr: "1.1.1.1", Ipv4Domains: []*Domain{domain}}
+	before := len(GetUpdateHistory())
+
+	domain.UpdateStatus = UpdatedSuccess
+	RecordUpdateHistory("test", domains, util.HTTPStats{})
+	domain.UpdateStatus = UpdatedNothing
+	RecordUpdateHistory("test", domains, util.HTTPStats{})
+	domains.Ipv4Addr = "2.2.2.2"
+	domain.UpdateStatus = UpdatedFailed
+	RecordUpdateHistory("test", domains, util.HTTPStats{})
+
+	list := GetUpdateHistory()[before:]
+	if len(list) != 2 {
+		t.Fatalf("期待2条记录, 得到 %d 条", len(list))
+	}
+	if list[0].OldIP != "" || list[0].NewIP != "1.1.1.1" || list[0].RecordType != "A" {
+		t.Errorf("第1条记录不正确: %+v", list[0])
+	}
+	if list[1].OldIP != "1.1.1.1" || list[1].NewIP != "2.2.2.2" || list[1].Status != UpdatedFailed {
+		t.Errorf("第2条记录不正确: %+v", list[1])
+	}
+}
+
+// TestSetUpdateHistory 测试恢复的历史用于之后记录的 OldIP
+func TestSetUpdateHistory(t *testing.T) {
+	old := GetUpdateHistory()
+	t.Cleanup(func() { SetUpdateHistory(old) })
+
+	SetUpdateHistory([]UpdateHistory{
+		{Provider: "test", Domain: "restore.example.com", RecordType: "A", NewIP: "1.1.1.1", Status: UpdatedSuccess},
+		{Provider: "test", Domain: "restore.example.com", RecordType: "A", NewIP: "3.3.3.3", Status: UpdatedFailed},
+	})
+	domain := &Domain{DomainName: "example.com", SubDomain: "restore", UpdateStatus: UpdatedSuccess}
+	RecordUpdateHistory("test", &Domains{Ipv4Addr: "2.2.2.2", Ipv4Domains: []*Domain{domain}}, util.HTTPStats{})
+
+	list := GetUpdateHistory()
+	if len(list) != 3 {
+		t.Fatalf("期待3条记录, 得到 %d 条", len(list))
+	}
+	if list[2].OldIP != "1.1.1.1" {
+		t.Errorf("期待 OldIP 为恢复的历史中最后一次成功的IP, 得到 %s", list[2].OldIP)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/jeessy2/ddns-go/v6/util"
 )
@@ -14,8 +15,31 @@ type Webhook struct {
 	WebhookURL         string
 	WebhookRequestBody string
 	WebhookHeaders     string
+	// 按事件类型的 RequestBody, 为空时使用 WebhookRequestBody
+	WebhookRequestBodyChanged      string
+	WebhookRequestBodyUpdateFailed string
+	WebhookRequestBodyDetectFailed string
+	// 进程退出前调用, 汇总本次运行的结果
+	ShutdownWebhookURL         string
+	ShutdownWebhookRequestBody string
 }
 
+// webhookEvent 触发Webhook的事件
+type webhookEvent string
+
+const (
+	// WebhookEventChanged IP已改变并更新成功
+	WebhookEventChanged webhookEvent = "changed"
+	// WebhookEventUpdateFailed 更新域名解析失败
+	WebhookEventUpdateFailed webhookEvent = "updateFailed"
+	// WebhookEventDetectFailed 获取IP失败
+	WebhookEventDetectFailed webhookEvent = "detectFailed"
+	// WebhookEventReverted IP恢复为之前使用过的值并更新成功, 见 IPRevertWindow
+	WebhookEventReverted webhookEvent = "reverted"
+	// WebhookEventDeleted 没有更新的域名, 只删除了 state=absent 或 missing=delete 的域名的记录
+	WebhookEventDeleted webhookEvent = "deleted"
+)
+
 // updateStatusType 更新状态
 type updateStatusType string
 
@@ -26,10 +50,21 @@ const (
 	UpdatedFailed = "失败"
 	// UpdatedSuccess 更新成功
 	UpdatedSuccess = "成功"
+	// UpdatedMaintenance 服务商正在维护, 暂不算失败
+	UpdatedMaintenance = "维护中"
+	// UpdatedDryRun 预演, 需要修改但未调用服务商的API, 见 DnsConfig.DryRun
+	UpdatedDryRun = "预演"
+	// UpdatedPending 更新成功但等待解析生效超时, 只用于通知, 见 DnsConfig.Propagation
+	UpdatedPending = "已更新, 等待生效"
+	// UpdatedDeleted 删除了记录, 见 state=absent 和 missing=delete, 不是发布了新的IP
+	UpdatedDeleted = "已删除"
 )
 
-// 更新失败次数
-var updatedFailedTimes = 0
+// 更新失败次数, 同时运行多个DNS配置时共用
+var updatedFailed = struct {
+	sync.Mutex
+	times int
+}{}
 
 // hasJSONPrefix returns true if the string starts with a JSON open brace.
 func hasJSONPrefix(s string) bool {
@@ -41,69 +76,95 @@ func ExecWebhook(domains *Domains, conf *Config) (v4Status updateStatusType, v6S
 	v4Status = getDomainsStatus(domains.Ipv4Domains)
 	v6Status = getDomainsStatus(domains.Ipv6Domains)
 
-	if conf.WebhookURL != "" && (v4Status != UpdatedNothing || v6Status != UpdatedNothing) {
+	if (conf.Webhook.enabled() || conf.MQTT.enabled()) && (v4Status != UpdatedNothing || v6Status != UpdatedNothing) {
 		// 第3次失败才触发一次webhook
+		updatedFailed.Lock()
 		if v4Status == UpdatedFailed || v6Status == UpdatedFailed {
-			updatedFailedTimes++
-			if updatedFailedTimes != 3 {
-				util.Log("将不会触发Webhook, 仅在第 3 次失败时触发一次Webhook, 当前失败次数：%d", updatedFailedTimes)
+			updatedFailed.times++
+			if updatedFailed.times != 3 {
+				util.Log("将不会触发Webhook, 仅在第 3 次失败时触发一次Webhook, 当前失败次数：%d", updatedFailed.times)
+				updatedFailed.Unlock()
 				return
 			}
 		} else {
-			updatedFailedTimes = 0
+			updatedFailed.times = 0
 		}
+		updatedFailed.Unlock()
 
 		// 成功和失败都要触发webhook
-		method := "GET"
-		postPara := ""
-		contentType := "application/x-www-form-urlencoded"
-		if conf.WebhookRequestBody != "" {
-			method = "POST"
-			postPara = replacePara(domains, conf.WebhookRequestBody, v4Status, v6Status)
-			if json.Valid([]byte(postPara)) {
-				contentType = "application/json"
-			} else if hasJSONPrefix(postPara) {
-				// 如果 RequestBody 的 JSON 无效但前缀为 JSON，提示无效
-				util.Log("Webhook中的 RequestBody JSON 无效")
+		event := getWebhookEvent(domains, v4Status, v6Status)
+		for _, n := range conf.eventNotifiers() {
+			if n.enabled() {
+				n.notify(domains, event, v4Status, v6Status)
 			}
 		}
-		requestURL := replacePara(domains, conf.WebhookURL, v4Status, v6Status)
-		u, err := url.Parse(requestURL)
-		if err != nil {
-			util.Log("Webhook配置中的URL不正确")
-			return
-		}
+	}
+	return
+}
 
-		q, _ := url.ParseQuery(u.RawQuery)
-		u.RawQuery = q.Encode()
+func (webhook *Webhook) name() string {
+	return "webhook"
+}
 
-		req, err := http.NewRequest(method, u.String(), strings.NewReader(postPara))
-		if err != nil {
-			util.Log("Webhook调用失败! 异常信息：%s", err)
-			return
-		}
+func (webhook *Webhook) enabled() bool {
+	return webhook.WebhookURL != ""
+}
 
-		headers := extractHeaders(conf.WebhookHeaders)
-		for key, value := range headers {
-			req.Header.Add(key, value)
-		}
-		req.Header.Add("content-type", contentType)
+// notify 调用Webhook
+func (webhook *Webhook) notify(domains *Domains, event webhookEvent, v4Status updateStatusType, v6Status updateStatusType) error {
+	postPara := replacePara(domains, webhook.getRequestBody(event), event, v4Status, v6Status)
+	requestURL := replacePara(domains, webhook.WebhookURL, event, v4Status, v6Status)
+	return sendWebhook(requestURL, postPara, webhook.WebhookHeaders)
+}
 
-		clt := util.CreateHTTPClient()
-		resp, err := clt.Do(req)
-		body, err := util.GetHTTPResponseOrg(resp, err)
-		if err == nil {
-			util.Log("Webhook调用成功! 返回数据：%s", string(body))
-		} else {
-			util.Log("Webhook调用失败! 异常信息：%s", err)
+// sendWebhook 调用Webhook, RequestBody 为空时为 GET 请求, 否则为 POST 请求, 返回调用结果
+func sendWebhook(requestURL string, postPara string, webhookHeaders string) error {
+	method := "GET"
+	contentType := "application/x-www-form-urlencoded"
+	if postPara != "" {
+		method = "POST"
+		if json.Valid([]byte(postPara)) {
+			contentType = "application/json"
+		} else if hasJSONPrefix(postPara) {
+			// 如果 RequestBody 的 JSON 无效但前缀为 JSON，提示无效
+			util.Log("Webhook中的 RequestBody JSON 无效")
 		}
 	}
-	return
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		util.Log("Webhook配置中的URL不正确")
+		return err
+	}
+
+	q, _ := url.ParseQuery(u.RawQuery)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(method, u.String(), strings.NewReader(postPara))
+	if err != nil {
+		util.Log("Webhook调用失败! 异常信息：%s", err)
+		return err
+	}
+
+	headers := extractHeaders(webhookHeaders)
+	for key, value := range headers {
+		req.Header.Add(key, value)
+	}
+	req.Header.Add("content-type", contentType)
+
+	clt := util.CreateHTTPClient()
+	resp, err := clt.Do(req)
+	body, err := util.GetHTTPResponseOrg(resp, err)
+	if err != nil {
+		util.Log("Webhook调用失败! 异常信息：%s", err)
+		return err
+	}
+	util.Log("Webhook调用成功! 返回数据：%s", string(body))
+	return nil
 }
 
 // getDomainsStatus 获取域名状态
 func getDomainsStatus(domains []*Domain) updateStatusType {
-	successNum := 0
+	successNum, pendingNum, deletedNum := 0, 0, 0
 	for _, v46 := range domains {
 		switch v46.UpdateStatus {
 		case UpdatedFailed:
@@ -111,28 +172,96 @@ func getDomainsStatus(domains []*Domain) updateStatusType {
 			return UpdatedFailed
 		case UpdatedSuccess:
 			successNum++
+			if v46.PropagationPending {
+				pendingNum++
+			}
+		case UpdatedDeleted:
+			deletedNum++
 		}
 	}
 
+	if pendingNum > 0 {
+		// 有未生效的域名时不通知成功
+		return UpdatedPending
+	}
 	if successNum > 0 {
 		// 迭代完成后一个成功，就成功
 		return UpdatedSuccess
 	}
+	if deletedNum > 0 {
+		// 只删除了记录
+		return UpdatedDeleted
+	}
 	return UpdatedNothing
 }
 
+// getWebhookEvent 根据本次更新结果获得事件类型
+func getWebhookEvent(domains *Domains, v4Status updateStatusType, v6Status updateStatusType) webhookEvent {
+	if v4Status != UpdatedFailed && v6Status != UpdatedFailed {
+		if (v4Status == UpdatedSuccess && domains.Ipv4Reverted) || (v6Status == UpdatedSuccess && domains.Ipv6Reverted) {
+			return WebhookEventReverted
+		}
+		if v4Status != UpdatedSuccess && v6Status != UpdatedSuccess && (v4Status == UpdatedDeleted || v6Status == UpdatedDeleted) {
+			return WebhookEventDeleted
+		}
+		return WebhookEventChanged
+	}
+	// 获取IP失败时不会设置地址
+	if (v4Status == UpdatedFailed && domains.Ipv4Addr == "") || (v6Status == UpdatedFailed && domains.Ipv6Addr == "") {
+		return WebhookEventDetectFailed
+	}
+	return WebhookEventUpdateFailed
+}
+
+// getRequestBody 获得事件对应的 RequestBody, 未设置时使用通用的 RequestBody
+func (webhook *Webhook) getRequestBody(event webhookEvent) string {
+	var body string
+	switch event {
+	case WebhookEventChanged, WebhookEventReverted:
+		body = webhook.WebhookRequestBodyChanged
+	case WebhookEventUpdateFailed:
+		body = webhook.WebhookRequestBodyUpdateFailed
+	case WebhookEventDetectFailed:
+		body = webhook.WebhookRequestBodyDetectFailed
+	}
+	if body == "" {
+		return webhook.WebhookRequestBody
+	}
+	return body
+}
+
 // replacePara 替换参数
-func replacePara(domains *Domains, orgPara string, ipv4Result updateStatusType, ipv6Result updateStatusType) string {
+func replacePara(domains *Domains, orgPara string, event webhookEvent, ipv4Result updateStatusType, ipv6Result updateStatusType) string {
 	return strings.NewReplacer(
+		"#{event}", string(event),
 		"#{ipv4Addr}", domains.Ipv4Addr,
 		"#{ipv4Result}", util.LogStr(string(ipv4Result)), // i18n
 		"#{ipv4Domains}", getDomainsStr(domains.Ipv4Domains),
+		"#{ipv4OldAddr}", getOldIPStr(domains.Ipv4Domains),
+		"#{ipv4Error}", getErrorStr(domains.Ipv4Domains),
 		"#{ipv6Addr}", domains.Ipv6Addr,
 		"#{ipv6Result}", util.LogStr(string(ipv6Result)), // i18n
 		"#{ipv6Domains}", getDomainsStr(domains.Ipv6Domains),
+		"#{ipv6OldAddr}", getOldIPStr(domains.Ipv6Domains),
+		"#{ipv6Error}", getErrorStr(domains.Ipv6Domains),
+		"#{source}", getSourceStr(domains),
+		"#{ipv4Source}", domains.Ipv4Source,
+		"#{ipv6Source}", domains.Ipv6Source,
 	).Replace(orgPara)
 }
 
+// getSourceStr 获取IP的来源, 如 IPv4: url:https://api.ipify.org,IPv6: netInterface:eth0
+func getSourceStr(domains *Domains) string {
+	var sources []string
+	if domains.Ipv4Source != "" && domains.Ipv4Addr != "" {
+		sources = append(sources, "IPv4: "+domains.Ipv4Source)
+	}
+	if domains.Ipv6Source != "" && domains.Ipv6Addr != "" {
+		sources = append(sources, "IPv6: "+domains.Ipv6Source)
+	}
+	return strings.Join(sources, ",")
+}
+
 // getDomainsStr 用逗号分割域名
 func getDomainsStr(domains []*Domain) string {
 	str := ""
@@ -146,6 +275,28 @@ func getDomainsStr(domains []*Domain) string {
 	return str
 }
 
+// getOldIPStr 第一个有更新前的值的域名的旧IP, 服务商未提供时为空
+func getOldIPStr(domains []*Domain) string {
+	for _, d := range domains {
+		if d.OldIP != "" {
+			return d.OldIP
+		}
+	}
+	return ""
+}
+
+// getErrorStr 更新失败的域名及原因, 如 www.example.com: xxx, 多个以`,`分割
+// 去掉引号和换行, 避免破坏JSON格式的 RequestBody
+func getErrorStr(domains []*Domain) string {
+	var errs []string
+	for _, d := range domains {
+		if d.UpdateStatus == UpdatedFailed && d.UpdateError != "" {
+			errs = append(errs, d.String()+": "+d.UpdateError)
+		}
+	}
+	return strings.NewReplacer(`"`, "'", `\`, "/", "\r", " ", "\n", " ", "\t", " ").Replace(strings.Join(errs, ","))
+}
+
 // extractHeaders converts s into a map of headers.
 //
 // See also: https://github.com/appleboy/gorush/blob/v1.17.0/notify/feedback.go#L15
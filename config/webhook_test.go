@@ -1,8 +1,13 @@
 package config
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/util"
 )
 
 // TestExtractHeaders 测试 parseHeaderArr
@@ -20,3 +25,145 @@ b: bar`
 		t.Errorf("Expected %v, got %v", expected, parsedHeaders)
 	}
 }
+
+// TestGetWebhookEvent 测试按事件类型选择 RequestBody
+func TestGetWebhookEvent(t *testing.T) {
+	webhook := &Webhook{
+		WebhookRequestBody:             "generic",
+		WebhookRequestBodyUpdateFailed: "updateFailed",
+	}
+
+	tests := []struct {
+		domains  *Domains
+		v4Status updateStatusType
+		v6Status updateStatusType
+		event    webhookEvent
+		body     string
+	}{
+		{&Domains{Ipv4Addr: "1.1.1.1"}, UpdatedSuccess, UpdatedNothing, WebhookEventChanged, "generic"},
+		{&Domains{Ipv4Addr: "1.1.1.1"}, UpdatedFailed, UpdatedNothing, WebhookEventUpdateFailed, "updateFailed"},
+		{&Domains{Ipv4Addr: "1.1.1.1"}, UpdatedSuccess, UpdatedFailed, WebhookEventDetectFailed, "generic"},
+		{&Domains{Ipv4Addr: "1.1.1.1", Ipv4Reverted: true}, UpdatedSuccess, UpdatedNothing, WebhookEventReverted, "generic"},
+		{&Domains{Ipv4Addr: "1.1.1.1", Ipv4Reverted: true}, UpdatedNothing, UpdatedSuccess, WebhookEventChanged, "generic"},
+		{&Domains{Ipv4Addr: "1.1.1.1"}, UpdatedDeleted, UpdatedNothing, WebhookEventDeleted, "generic"},
+		{&Domains{Ipv4Addr: "1.1.1.1"}, UpdatedSuccess, UpdatedDeleted, WebhookEventChanged, "generic"},
+	}
+
+	for _, tt := range tests {
+		event := getWebhookEvent(tt.domains, tt.v4Status, tt.v6Status)
+		if event != tt.event {
+			t.Errorf("期待事件 %s, 得到 %s", tt.event, event)
+		}
+		if body := webhook.getRequestBody(event); body != tt.body {
+			t.Errorf("期待 RequestBody %s, 得到 %s", tt.body, body)
+		}
+	}
+}
+
+// TestGetDomainsStatus 测试未改变的域名不视为更新成功
+func TestGetDomainsStatus(t *testing.T) {
+	tests := []struct {
+		statuses []updateStatusType
+		expected updateStatusType
+	}{
+		{[]updateStatusType{UpdatedNothing, UpdatedNothing}, UpdatedNothing},
+		{[]updateStatusType{UpdatedNothing, UpdatedSuccess}, UpdatedSuccess},
+		{[]updateStatusType{UpdatedSuccess, UpdatedFailed}, UpdatedFailed},
+		{[]updateStatusType{""}, UpdatedNothing},
+		{[]updateStatusType{UpdatedNothing, UpdatedDeleted}, UpdatedDeleted},
+		{[]updateStatusType{UpdatedDeleted, UpdatedSuccess}, UpdatedSuccess},
+		{[]updateStatusType{UpdatedDeleted, UpdatedFailed}, UpdatedFailed},
+	}
+
+	for _, tt := range tests {
+		var domains []*Domain
+		for _, status := range tt.statuses {
+			domains = append(domains, &Domain{UpdateStatus: status})
+		}
+		if status := getDomainsStatus(domains); status != tt.expected {
+			t.Errorf("%v 期待 %s, 得到 %s", tt.statuses, tt.expected, status)
+		}
+	}
+}
+
+// TestGetDomainsStatusPending 测试有等待解析生效超时的域名时不通知成功
+func TestGetDomainsStatusPending(t *testing.T) {
+	domains := []*Domain{{UpdateStatus: UpdatedSuccess}, {UpdateStatus: UpdatedSuccess, PropagationPending: true}}
+	if status := getDomainsStatus(domains); status != UpdatedPending {
+		t.Errorf("期待 %s, 得到 %s", UpdatedPending, status)
+	}
+	domains = append(domains, &Domain{UpdateStatus: UpdatedFailed})
+	if status := getDomainsStatus(domains); status != UpdatedFailed {
+		t.Errorf("期待 %s, 得到 %s", UpdatedFailed, status)
+	}
+}
+
+// TestGetSourceStr 测试 #{source} 只包含已获取到IP的来源
+func TestGetSourceStr(t *testing.T) {
+	domains := &Domains{
+		Ipv4Addr:   "1.1.1.1",
+		Ipv4Source: "url:https://api.ipify.org",
+		Ipv6Source: "netInterface:eth0",
+	}
+	if got := replacePara(domains, "#{source}", WebhookEventChanged, UpdatedSuccess, UpdatedNothing); got != "IPv4: url:https://api.ipify.org" {
+		t.Errorf("Expected IPv4 source only, got %q", got)
+	}
+
+	domains.Ipv6Addr = "::1"
+	if got := getSourceStr(domains); got != "IPv4: url:https://api.ipify.org,IPv6: netInterface:eth0" {
+		t.Errorf("Expected both sources, got %q", got)
+	}
+}
+
+// TestOldAddrAndError 测试 #{ipv4OldAddr} #{ipv4Error}, 服务商未提供时为空
+func TestOldAddrAndError(t *testing.T) {
+	domains := &Domains{
+		Ipv4Addr: "2.2.2.2",
+		Ipv4Domains: []*Domain{
+			{DomainName: "example.com", SubDomain: "a", UpdateStatus: UpdatedSuccess, OldIP: "1.1.1.1"},
+			{DomainName: "example.com", SubDomain: "b", UpdateStatus: UpdatedFailed, UpdateError: "Code: \"Forbidden\"\nline"},
+		},
+		Ipv6Domains: []*Domain{{DomainName: "example.com", UpdateStatus: UpdatedFailed}},
+	}
+	got := replacePara(domains, `{"from":"#{ipv4OldAddr}","to":"#{ipv4Addr}","error":"#{ipv4Error}","old6":"#{ipv6OldAddr}","error6":"#{ipv6Error}"}`, WebhookEventUpdateFailed, UpdatedFailed, UpdatedFailed)
+	expected := `{"from":"1.1.1.1","to":"2.2.2.2","error":"b.example.com: Code: 'Forbidden' line","old6":"","error6":""}`
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+// TestExecWebhookDeleted 测试 missing=delete 删除记录后通知删除事件, 不记录为发布了IP
+func TestExecWebhookDeleted(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer server.Close()
+
+	domain := &Domain{DomainName: "example.com", SubDomain: "deleted", UpdateStatus: UpdatedDeleted}
+	domains := &Domains{Ipv4Addr: "1.1.1.1", Ipv6Domains: []*Domain{domain}}
+	conf := &Config{Webhook: Webhook{WebhookURL: server.URL, WebhookRequestBody: "#{event} #{ipv6Result} #{ipv6Addr}"}}
+
+	v4Status, v6Status := ExecWebhook(domains, conf)
+	if v4Status != UpdatedNothing || v6Status != UpdatedDeleted {
+		t.Errorf("期待状态为 %q %q, 得到 %q %q", UpdatedNothing, UpdatedDeleted, v4Status, v6Status)
+	}
+	if expected := "deleted " + util.LogStr(string(UpdatedDeleted)) + " "; body != expected {
+		t.Errorf("期待通知 %q, 得到 %q", expected, body)
+	}
+
+	RecordPublishedIP("deleted-test", domains)
+	publishedIPs.Lock()
+	published := publishedIPs.records[publishedKey("deleted-test", domain, "AAAA")]
+	publishedIPs.Unlock()
+	if len(published) != 0 {
+		t.Errorf("删除记录不应记录为发布了IP, 得到 %v", published)
+	}
+
+	RecordUpdateHistory("deleted-test", domains, util.HTTPStats{})
+	history := GetUpdateHistory()
+	if last := history[len(history)-1]; last.Domain != "deleted.example.com" || last.Status != UpdatedDeleted || last.NewIP != "" {
+		t.Errorf("更新历史不正确: %+v", last)
+	}
+}
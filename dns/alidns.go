@@ -2,6 +2,9 @@ package dns
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
 
@@ -19,6 +22,7 @@ type Alidns struct {
 	DNS     config.DNS
 	Domains config.Domains
 	TTL     string
+	retry   aliyunRetry
 }
 
 // AlidnsRecord record
@@ -36,6 +40,12 @@ type AlidnsSubDomainRecords struct {
 	}
 }
 
+// AlidnsDomainInfo DescribeDomainInfo 返回结果
+type AlidnsDomainInfo struct {
+	DomainId   string
+	DomainName string
+}
+
 // AlidnsResp 修改/添加返回结果
 type AlidnsResp struct {
 	RecordID  string
@@ -47,6 +57,7 @@ func (ali *Alidns) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6
 	ali.Domains.Ipv4Cache = ipv4cache
 	ali.Domains.Ipv6Cache = ipv6cache
 	ali.DNS = dnsConf.DNS
+	ali.retry = newAliyunRetry(dnsConf)
 	ali.Domains.GetNewIp(dnsConf)
 	if dnsConf.TTL == "" {
 		// 默认600s
@@ -71,18 +82,28 @@ func (ali *Alidns) addUpdateDomainRecords(recordType string) {
 	}
 
 	for _, domain := range domains {
+		// 根域名不存在时只有该根域名的域名失败
+		zoneName, err := ali.getDomainName(domain.DomainName)
+		if err != nil {
+			util.Log("查询域名信息发生异常! %s", err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+
 		var records AlidnsSubDomainRecords
 		// 获取当前域名信息
 		params := domain.GetCustomParams()
 		params.Set("Action", "DescribeSubDomainRecords")
-		params.Set("DomainName", domain.DomainName)
+		params.Set("DomainName", zoneName)
 		params.Set("SubDomain", domain.GetFullDomain())
 		params.Set("Type", recordType)
-		err := ali.request(params, &records)
+		err = ali.request(params, &records)
 
 		if err != nil {
 			util.Log("查询域名信息发生异常! %s", err)
 			domain.UpdateStatus = config.UpdatedFailed
+			// 根域名可能已被删除, 下次运行时重新查询
+			forgetAliyunZone("alidns", ali.DNS.ID, domain.DomainName)
 			return
 		}
 
@@ -100,17 +121,17 @@ func (ali *Alidns) addUpdateDomainRecords(recordType string) {
 			ali.modify(recordSelected, domain, recordType, ipAddr)
 		} else {
 			// 不存在，创建
-			ali.create(domain, recordType, ipAddr)
+			ali.create(zoneName, domain, recordType, ipAddr)
 		}
 
 	}
 }
 
 // 创建
-func (ali *Alidns) create(domain *config.Domain, recordType string, ipAddr string) {
+func (ali *Alidns) create(zoneName string, domain *config.Domain, recordType string, ipAddr string) {
 	params := domain.GetCustomParams()
 	params.Set("Action", "AddDomainRecord")
-	params.Set("DomainName", domain.DomainName)
+	params.Set("DomainName", zoneName)
 	params.Set("RR", domain.GetSubDomain())
 	params.Set("Type", recordType)
 	params.Set("Value", ipAddr)
@@ -138,8 +159,9 @@ func (ali *Alidns) create(domain *config.Domain, recordType string, ipAddr strin
 func (ali *Alidns) modify(recordSelected AlidnsRecord, domain *config.Domain, recordType string, ipAddr string) {
 
 	// 相同不修改
-	if recordSelected.Value == ipAddr {
+	if util.SameIP(recordSelected.Value, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 
@@ -169,25 +191,54 @@ func (ali *Alidns) modify(recordSelected AlidnsRecord, domain *config.Domain, re
 	}
 }
 
-// request 统一请求接口
-func (ali *Alidns) request(params url.Values, result interface{}) (err error) {
+// getDomainName 获得账号中的根域名, 与ESA共用缓存, 同一根域名只查询一次
+func (ali *Alidns) getDomainName(domainName string) (string, error) {
+	return resolveAliyunZone("alidns", ali.DNS.ID, domainName, ali.lookupDomainName)
+}
 
-	util.AliyunSigner(ali.DNS.ID, ali.DNS.Secret, &params)
+// lookupDomainName 查询根域名是否在账号中
+func (ali *Alidns) lookupDomainName(zoneName string) (string, error) {
+	params := url.Values{}
+	params.Set("Action", "DescribeDomainInfo")
+	params.Set("DomainName", zoneName)
 
-	req, err := http.NewRequest(
-		"GET",
-		alidnsEndpoint,
-		bytes.NewBuffer(nil),
-	)
-	req.URL.RawQuery = params.Encode()
+	var result AlidnsDomainInfo
+	if err := ali.request(params, &result); err != nil {
+		return "", err
+	}
+	if result.DomainName == "" || aliyunZoneName(result.DomainName) != zoneName {
+		return "", fmt.Errorf("domain not found: %s", zoneName)
+	}
+	return zoneName, nil
+}
 
-	if err != nil {
-		return
+// request 统一请求接口
+func (ali *Alidns) request(params url.Values, result interface{}) (err error) {
+	endpoint := alidnsEndpoint
+	if ali.DNS.Endpoint != "" {
+		endpoint = ali.DNS.Endpoint
 	}
 
-	client := util.CreateHTTPClient()
-	resp, err := client.Do(req)
-	err = util.GetHTTPResponse(resp, err, result)
+	return ali.retry.do(ali.Domains.Context(), func(ctx context.Context) (*http.Response, error) {
+		// 每次请求重新签名, SignatureNonce 不能重复使用
+		signed := maps.Clone(params)
+		if err := util.AliyunSigner(ali.DNS.ID, ali.DNS.Secret, &signed); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(
+			ctx,
+			"GET",
+			endpoint,
+			bytes.NewBuffer(nil),
+		)
+		if err != nil {
+			return nil, err
+		}
+		req.URL.RawQuery = signed.Encode()
 
-	return
+		client := ali.Domains.HTTPClient()
+		// 超时由 ctx 控制, 见 DnsConfig.HTTPTimeout
+		client.Timeout = 0
+		return client.Do(req)
+	}, result)
 }
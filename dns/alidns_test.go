@@ -0,0 +1,68 @@
+package dns
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestAlidnsDomainCache 测试同一根域名只查询一次, 根域名不存在时只有该根域名的域名失败
+func TestAlidnsDomainCache(t *testing.T) {
+	var mu sync.Mutex
+	actions := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		mu.Lock()
+		actions[q.Get("Action")]++
+		mu.Unlock()
+		switch q.Get("Action") {
+		case "DescribeDomainInfo":
+			if q.Get("DomainName") != "example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"Code":"InvalidDomainName.NoExist"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(AlidnsDomainInfo{DomainId: "1", DomainName: "example.com"})
+		case "DescribeSubDomainRecords":
+			if q.Get("DomainName") != "example.com" {
+				t.Errorf("期待 DomainName 为 example.com, 得到 %s", q.Get("DomainName"))
+			}
+			json.NewEncoder(w).Encode(AlidnsSubDomainRecords{})
+		case "AddDomainRecord":
+			json.NewEncoder(w).Encode(AlidnsResp{RecordID: "1"})
+		default:
+			t.Errorf("unexpected action %s", q.Get("Action"))
+		}
+	}))
+	defer server.Close()
+
+	config.ResetPushedTargets()
+	www := &config.Domain{DomainName: "Example.com", SubDomain: "www"}
+	api := &config.Domain{DomainName: "example.com", SubDomain: "api"}
+	other := &config.Domain{DomainName: "example.org", SubDomain: "www"}
+	ali := &Alidns{
+		DNS:   config.DNS{ID: "alidns-domain-cache-test", Secret: "secret", Endpoint: server.URL + "/"},
+		TTL:   "600",
+		retry: aliyunRetry{times: 1},
+	}
+	ali.Domains.Ipv4Addr = "1.1.1.1"
+	ali.Domains.Ipv4Cache = &util.IpCache{}
+	ali.Domains.Ipv4Domains = []*config.Domain{www, other, api}
+	ali.addUpdateDomainRecords("A")
+
+	if www.UpdateStatus != config.UpdatedSuccess || api.UpdateStatus != config.UpdatedSuccess {
+		t.Errorf("example.com 的域名应新增成功, 得到 %s %s", www.UpdateStatus, api.UpdateStatus)
+	}
+	if other.UpdateStatus != config.UpdatedFailed {
+		t.Errorf("根域名不存在时应失败, 得到 %s", other.UpdateStatus)
+	}
+	// example.com 与 example.org 各查询一次
+	if actions["DescribeDomainInfo"] != 2 {
+		t.Errorf("期待 DescribeDomainInfo 调用2次, 得到 %d 次", actions["DescribeDomainInfo"])
+	}
+}
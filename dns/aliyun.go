@@ -0,0 +1,70 @@
+package dns
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/net/idna"
+)
+
+// aliyunZones 阿里云DNS/ESA共用的根域名(站点)ID缓存, key 为 服务|AccessKey ID|根域名
+var aliyunZones = struct {
+	sync.Mutex
+	ids map[string]string
+	// pending 正在查询的根域名, 同时更新多个域名时同一根域名只查询一次
+	pending map[string]*aliyunZoneLookup
+}{ids: map[string]string{}, pending: map[string]*aliyunZoneLookup{}}
+
+// aliyunZoneLookup 一次根域名ID的查询, 完成后关闭 done
+type aliyunZoneLookup struct {
+	done chan struct{}
+	id   string
+	err  error
+}
+
+// aliyunZoneName 统一阿里云的域名格式: 转为小写, 去掉末尾的点, 中文域名转为 punycode
+func aliyunZoneName(domainName string) string {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domainName)), ".")
+	if ascii, err := idna.ToASCII(name); err == nil {
+		return ascii
+	}
+	return name
+}
+
+// resolveAliyunZone 获得根域名对应的ID, 首次通过 lookup 查询(需精确匹配), 之后使用缓存
+func resolveAliyunZone(service, accessKey, domainName string, lookup func(zoneName string) (string, error)) (string, error) {
+	zoneName := aliyunZoneName(domainName)
+	key := service + "|" + accessKey + "|" + zoneName
+
+	aliyunZones.Lock()
+	if id, ok := aliyunZones.ids[key]; ok {
+		aliyunZones.Unlock()
+		return id, nil
+	}
+	if l, ok := aliyunZones.pending[key]; ok {
+		aliyunZones.Unlock()
+		<-l.done
+		return l.id, l.err
+	}
+	l := &aliyunZoneLookup{done: make(chan struct{})}
+	aliyunZones.pending[key] = l
+	aliyunZones.Unlock()
+
+	l.id, l.err = lookup(zoneName)
+
+	aliyunZones.Lock()
+	if l.err == nil {
+		aliyunZones.ids[key] = l.id
+	}
+	delete(aliyunZones.pending, key)
+	aliyunZones.Unlock()
+	close(l.done)
+	return l.id, l.err
+}
+
+// forgetAliyunZone 根域名(站点)不存在时删除缓存
+func forgetAliyunZone(service, accessKey, domainName string) {
+	aliyunZones.Lock()
+	delete(aliyunZones.ids, service+"|"+accessKey+"|"+aliyunZoneName(domainName))
+	aliyunZones.Unlock()
+}
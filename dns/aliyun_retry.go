@@ -0,0 +1,129 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+const (
+	aliyunRetryTimes = 3
+	aliyunRetryDelay = time.Second
+	// aliyunRequestTimeout 每次请求(包括读取返回内容)的超时时间
+	aliyunRequestTimeout = 10 * time.Second
+)
+
+// aliyunRetry 阿里云API被限流(Throttling)或返回5xx时的重试
+type aliyunRetry struct {
+	// times 最多请求的次数
+	times int
+	// delay 第一次重试前等待的时间, 之后每次加倍
+	delay time.Duration
+	// timeout 每次请求的超时时间, 为0时为 aliyunRequestTimeout
+	timeout time.Duration
+}
+
+// newAliyunRetry 使用DNS配置中的 Retry/RetryDelay/HTTPTimeout, 未配置时请求3次, 第一次重试前等待1秒, 每次请求最多10秒
+func newAliyunRetry(dnsConf *config.DnsConfig) aliyunRetry {
+	r := aliyunRetry{times: aliyunRetryTimes, delay: aliyunRetryDelay, timeout: aliyunRequestTimeout}
+	if dnsConf.Retry > 0 {
+		r.times = dnsConf.Retry
+	}
+	if dnsConf.RetryDelay > 0 {
+		r.delay = time.Duration(dnsConf.RetryDelay) * time.Millisecond
+	}
+	if dnsConf.HTTPTimeout > 0 {
+		r.timeout = time.Duration(dnsConf.HTTPTimeout) * time.Second
+	}
+	return r
+}
+
+// do 发送请求并将结果解析到 result, 限流或5xx时按指数退避重试, 签名/权限/超时等其它错误不重试
+// send 每次都需重新签名, 阿里云不允许重复使用 SignatureNonce, 请求需使用 ctx 以便超时后取消
+// parent 为所属配置本轮运行的 context, 取消后不再重试, 重试次数计入其附加的 util.HTTPCounter
+func (r aliyunRetry) do(parent context.Context, send func(ctx context.Context) (*http.Response, error), result interface{}) error {
+	for attempt := 1; ; attempt++ {
+		status, body, err := r.send(parent, send)
+		if err == nil {
+			if len(body) == 0 {
+				return nil
+			}
+			return json.Unmarshal(body, result)
+		}
+		if attempt >= r.times || !isAliyunRetryable(status, body) {
+			return err
+		}
+
+		// 加上随机的等待时间, 避免同时重试
+		wait := r.delay << (attempt - 1)
+		wait += rand.N(wait/2 + 1)
+		util.Log("阿里云API被限流或暂时不可用, 将在 %s 后重试: %s", wait.Round(time.Millisecond), err)
+		util.AddHTTPRetry(parent)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-parent.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// send 发送一次请求并读取返回内容, 超过 timeout 或 parent 取消时取消请求
+func (r aliyunRetry) send(parent context.Context, send func(ctx context.Context) (*http.Response, error)) (status int, body []byte, err error) {
+	timeout := r.timeout
+	if timeout <= 0 {
+		timeout = aliyunRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	resp, err := send(ctx)
+	if err == nil {
+		status = resp.StatusCode
+	}
+	body, err = util.GetHTTPResponseOrg(resp, err)
+	return
+}
+
+// isAliyunRetryable 限流或服务端错误可以重试
+func isAliyunRetryable(status int, body []byte) bool {
+	if status >= http.StatusInternalServerError {
+		return true
+	}
+	var resp struct {
+		Code string
+	}
+	json.Unmarshal(body, &resp)
+	return strings.HasPrefix(resp.Code, "Throttling") || resp.Code == "ServiceUnavailable"
+}
+
+// aliyunErrorCodeRegexp 错误信息中阿里云返回的 Code
+var aliyunErrorCodeRegexp = regexp.MustCompile(`"Code"\s*:\s*"([^"]+)"`)
+
+// aliyunPermanentCodes AccessKey/签名/权限错误, 需要用户修改配置, 重试无效
+var aliyunPermanentCodes = []string{
+	"InvalidAccessKeyId", "InvalidAccessKeySecret", "SignatureDoesNotMatch", "IncompleteSignature",
+	"InvalidSecurityToken", "Forbidden", "NoPermission", "NotAuthorized",
+}
+
+// isAliyunPermanentError 更新失败的原因是否为需要用户处理的错误, 网络/超时/限流/5xx等为暂时的错误
+func isAliyunPermanentError(msg string) bool {
+	m := aliyunErrorCodeRegexp.FindStringSubmatch(msg)
+	if m == nil {
+		return false
+	}
+	for _, code := range aliyunPermanentCodes {
+		if m[1] == code || strings.HasPrefix(m[1], code+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// TestAliyunRetry 测试被限流时重试, 签名错误时不重试
+func TestAliyunRetry(t *testing.T) {
+	calls := 0
+	code := "Throttling.User"
+	nonces := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		nonce := r.URL.Query().Get("SignatureNonce")
+		if nonces[nonce] {
+			t.Error("重试时应重新签名")
+		}
+		nonces[nonce] = true
+		if calls <= 2 || code == "SignatureDoesNotMatch" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"` + code + `"}`))
+			return
+		}
+		w.Write([]byte(`{"RequestId":"1"}`))
+	}))
+	defer server.Close()
+
+	esa := &ESA{
+		DNS:   config.DNS{ID: "id", Secret: "secret", Endpoint: server.URL + "/"},
+		retry: aliyunRetry{times: 3, delay: time.Millisecond},
+	}
+	var result ESAResp
+	if err := esa.request(url.Values{"Action": {"ListSites"}}, &result); err != nil || result.RequestId != "1" {
+		t.Errorf("期待重试后成功, 得到 %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("期待请求3次, 得到 %d 次", calls)
+	}
+
+	calls, code = 0, "SignatureDoesNotMatch"
+	if err := esa.request(url.Values{"Action": {"ListSites"}}, &result); err == nil {
+		t.Error("签名错误时应返回错误")
+	}
+	if calls != 1 {
+		t.Errorf("签名错误时不应重试, 请求了 %d 次", calls)
+	}
+}
+
+// TestNewAliyunRetry 测试默认值和配置的重试次数
+func TestNewAliyunRetry(t *testing.T) {
+	r := newAliyunRetry(&config.DnsConfig{})
+	if r.times != 3 || r.delay != time.Second {
+		t.Errorf("默认应请求3次, 等待1秒, 得到 %d, %s", r.times, r.delay)
+	}
+	if r.timeout != 10*time.Second {
+		t.Errorf("默认超时应为10秒, 得到 %s", r.timeout)
+	}
+	r = newAliyunRetry(&config.DnsConfig{Retry: 5, RetryDelay: 200, HTTPTimeout: 3})
+	if r.times != 5 || r.delay != 200*time.Millisecond || r.timeout != 3*time.Second {
+		t.Errorf("得到 %d, %s, %s", r.times, r.delay, r.timeout)
+	}
+}
+
+// TestAliyunRequestTimeout 测试接口无响应时在超时后返回, 且不重试
+func TestAliyunRequestTimeout(t *testing.T) {
+	var calls atomic.Int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	esa := &ESA{
+		DNS:   config.DNS{ID: "id", Secret: "secret", Endpoint: server.URL + "/"},
+		retry: aliyunRetry{times: 3, delay: time.Millisecond, timeout: 50 * time.Millisecond},
+	}
+	start := time.Now()
+	err := esa.request(url.Values{"Action": {"ListSites"}}, &ESAResp{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期待超时错误, 得到 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("应在超时后立即返回, 用时 %s", elapsed)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("超时后不应重试, 请求了 %d 次", n)
+	}
+}
+
+// TestIsAliyunPermanentError 测试AccessKey/签名/权限错误为需要处理的错误, 其它为暂时的错误
+func TestIsAliyunPermanentError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{`返回内容: {"RequestId":"1","Code":"InvalidAccessKeyId.NotFound","Message":"Specified access key is not found."} ,返回状态码: 404`, true},
+		{`返回内容: {"RequestId":"1","Code":"SignatureDoesNotMatch","Message":"Specified signature is not matched with our calculation."} ,返回状态码: 400`, true},
+		{`返回内容: {"RequestId":"1","Code":"Forbidden.RAM","Message":"User not authorized to operate on the specified resource."} ,返回状态码: 403`, true},
+		{`返回内容: {"RequestId":"1","Code":"Throttling.User","Message":"Request was denied due to user flow control."} ,返回状态码: 400`, false},
+		{`返回内容: {"RequestId":"1","Code":"InternalError","Message":"The request processing has failed due to some unknown error."} ,返回状态码: 500`, false},
+		{`Get "https://esa.cn-hangzhou.aliyuncs.com/": dial tcp: lookup esa.cn-hangzhou.aliyuncs.com: no such host`, false},
+		{`Get "https://esa.cn-hangzhou.aliyuncs.com/": context deadline exceeded`, false},
+		{`返回内容: {"Code":"ForbiddenNotAForbidden"} ,返回状态码: 400`, false},
+	}
+	for _, tt := range tests {
+		if got := isAliyunPermanentError(tt.msg); got != tt.want {
+			t.Errorf("isAliyunPermanentError(%s) = %v, 期待 %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+// TestAliyunRetryCanceled 测试本轮运行取消后不再等待重试
+func TestAliyunRetryCanceled(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Code":"Throttling.User"}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	r := aliyunRetry{times: 3, delay: time.Hour}
+	start := time.Now()
+	err := r.do(ctx, func(ctx context.Context) (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		return server.Client().Do(req)
+	}, &ESAResp{})
+	if err == nil {
+		t.Error("取消后应返回错误")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("取消后应立即返回, 等待了 %s", d)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("取消后不应重试, 请求了 %d 次", calls.Load())
+	}
+}
@@ -0,0 +1,52 @@
+package dns
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAliyunZoneName 测试根域名格式统一
+func TestAliyunZoneName(t *testing.T) {
+	tests := map[string]string{
+		"Example.COM":  "example.com",
+		"example.com.": "example.com",
+		"例子.中国":        "xn--fsqu00a.xn--fiqs8s",
+	}
+	for input, want := range tests {
+		if got := aliyunZoneName(input); got != want {
+			t.Errorf("aliyunZoneName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestResolveAliyunZone 测试根域名ID的缓存
+func TestResolveAliyunZone(t *testing.T) {
+	calls := 0
+	lookup := func(zoneName string) (string, error) {
+		calls++
+		if zoneName != "example.com" {
+			return "", errors.New("not found")
+		}
+		return "123", nil
+	}
+
+	for _, name := range []string{"example.com", "Example.com."} {
+		id, err := resolveAliyunZone("test", "key", name, lookup)
+		if err != nil || id != "123" {
+			t.Fatalf("resolveAliyunZone(%q) = %q, %v", name, id, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("lookup called %d times, want 1", calls)
+	}
+
+	forgetAliyunZone("test", "key", "example.com")
+	resolveAliyunZone("test", "key", "example.com", lookup)
+	if calls != 2 {
+		t.Errorf("lookup called %d times after forget, want 2", calls)
+	}
+
+	if _, err := resolveAliyunZone("test", "key", "example.org", lookup); err == nil {
+		t.Error("resolveAliyunZone(example.org) should fail")
+	}
+}
@@ -154,8 +154,9 @@ func (baidu *BaiduCloud) create(domain *config.Domain, recordType string, ipAddr
 // modify 更新解析
 func (baidu *BaiduCloud) modify(record BaiduRecord, domain *config.Domain, rdType string, ipAddr string) {
 	//没有变化直接跳过
-	if record.Rdata == ipAddr {
+	if util.SameIP(record.Rdata, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	var baiduModifyRequest = BaiduModifyRequest{
@@ -198,7 +199,7 @@ func (baidu *BaiduCloud) request(method string, url string, data interface{}, re
 
 	util.BaiduSigner(baidu.DNS.ID, baidu.DNS.Secret, req)
 
-	client := util.CreateHTTPClient()
+	client := baidu.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/jeessy2/ddns-go/v6/config"
@@ -17,6 +20,9 @@ type Callback struct {
 	TTL      string
 	lastIpv4 string
 	lastIpv6 string
+	// successCodes/successBody 成功条件, 见 config.DnsConfig.Callback
+	successCodes []int
+	successBody  string
 }
 
 // Init 初始化
@@ -27,6 +33,8 @@ func (cb *Callback) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv
 	cb.lastIpv6 = ipv6cache.Addr
 
 	cb.DNS = dnsConf.DNS
+	cb.successCodes = dnsConf.Callback.SuccessCodes
+	cb.successBody = dnsConf.Callback.SuccessBody
 	cb.Domains.GetNewIp(dnsConf)
 	if dnsConf.TTL == "" {
 		// 默认600
@@ -88,17 +96,65 @@ func (cb *Callback) addUpdateDomainRecords(recordType string) {
 		}
 		req.Header.Add("content-type", contentType)
 
-		clt := util.CreateHTTPClient()
+		clt := cb.Domains.HTTPClient()
 		resp, err := clt.Do(req)
+		status := 0
+		if err == nil {
+			status = resp.StatusCode
+		}
 		body, err := util.GetHTTPResponseOrg(resp, err)
+		if status != 0 {
+			err = cb.checkSuccess(status, body, err)
+		}
 		if err == nil {
 			util.Log("Callback调用成功, 域名: %s, IP: %s, 返回数据: %s", domain, ipAddr, string(body))
 			domain.UpdateStatus = config.UpdatedSuccess
 		} else {
 			util.Log("Callback调用失败, 异常信息: %s", err)
-			domain.UpdateStatus = config.UpdatedFailed
+			domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+		}
+	}
+}
+
+// checkSuccess 按配置的成功条件检查返回结果, err 为 GetHTTPResponseOrg 按状态码判断的结果
+func (cb *Callback) checkSuccess(status int, body []byte, err error) error {
+	if len(cb.successCodes) > 0 {
+		if !slices.Contains(cb.successCodes, status) {
+			return fmt.Errorf("%s", util.LogStr("返回状态码 %d 不是成功的状态码 %v, 返回内容: %s", status, cb.successCodes, string(body)))
+		}
+		err = nil
+	}
+	if err != nil || cb.successBody == "" {
+		return err
+	}
+
+	if expr, ok := strings.CutPrefix(cb.successBody, "regex:"); ok {
+		reg, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		if !reg.Match(body) {
+			return fmt.Errorf("%s", util.LogStr("返回内容不匹配 %s, 返回内容: %s", cb.successBody, string(body)))
 		}
+		return nil
+	}
+
+	path, expected, _ := strings.Cut(cb.successBody, "=")
+	value, err := util.JSONPath(body, path)
+	if err != nil {
+		return fmt.Errorf("%s", util.LogStr("返回内容不匹配 %s, 返回内容: %s", cb.successBody, string(body)))
+	}
+	actual := fmt.Sprint(value)
+	switch v := value.(type) {
+	case float64:
+		actual = strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		actual = "null"
+	}
+	if actual != expected {
+		return fmt.Errorf("%s", util.LogStr("返回内容中 %s 的值为 %s, 期待 %s", path, actual, expected))
 	}
+	return nil
 }
 
 // replacePara 替换参数
@@ -0,0 +1,60 @@
+package dns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestCallbackSuccess 测试按配置的状态码和返回内容判断是否成功
+func TestCallbackSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+		w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		status  int
+		body    string
+		codes   []int
+		match   string
+		success bool
+	}{
+		"未配置时2xx成功":     {200, `{"code":1}`, nil, "", true},
+		"未配置时4xx失败":     {409, "", nil, "", false},
+		"状态码在成功的状态码中":   {409, "", []int{200, 409}, "", true},
+		"状态码不在成功的状态码中":  {200, "", []int{201}, "", false},
+		"JSON路径的值相同":    {200, `{"data":{"code":0}}`, nil, "data.code=0", true},
+		"JSON路径的值不同":    {200, `{"data":{"code":1}}`, nil, "data.code=0", false},
+		"返回内容不是JSON":    {200, "ok", nil, "code=0", false},
+		"正则表达式匹配":       {200, "result: good", nil, "regex:^result: (good|nochg)", true},
+		"正则表达式不匹配":      {200, "result: badauth", nil, "regex:^result: (good|nochg)", false},
+		"状态码失败时不检查返回内容": {500, `{"code":0}`, nil, "code=0", false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config.ResetPushedTargets()
+			domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+			cb := &Callback{
+				DNS:          config.DNS{ID: server.URL + "/?status=" + strconv.Itoa(tt.status) + "&body=" + url.QueryEscape(tt.body)},
+				successCodes: tt.codes,
+				successBody:  tt.match,
+			}
+			cb.Domains.Ipv4Addr = "1.1.1.1"
+			cb.Domains.Ipv4Cache = &util.IpCache{}
+			cb.Domains.Ipv4Domains = []*config.Domain{domain}
+			cb.addUpdateDomainRecords("A")
+
+			if (domain.UpdateStatus == config.UpdatedSuccess) != tt.success {
+				t.Errorf("期待成功 %v, 状态 %q, 原因 %s", tt.success, domain.UpdateStatus, domain.UpdateError)
+			}
+		})
+	}
+}
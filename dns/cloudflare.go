@@ -11,6 +11,7 @@ import (
 
 	"github.com/jeessy2/ddns-go/v6/config"
 	"github.com/jeessy2/ddns-go/v6/util"
+	"golang.org/x/net/idna"
 )
 
 const zonesAPI = "https://api.cloudflare.com/client/v4/zones"
@@ -86,6 +87,7 @@ func (cf *Cloudflare) addUpdateDomainRecords(recordType string) {
 	ipAddr, domains := cf.Domains.GetNewIpResult(recordType)
 
 	if ipAddr == "" {
+		cf.deleteDomainRecords(recordType, cf.Domains.GetDeleteDomains(recordType))
 		return
 	}
 
@@ -147,9 +149,59 @@ func (cf *Cloudflare) addUpdateDomainRecords(recordType string) {
 			// 新增
 			cf.create(zoneID, domain, recordType, ipAddr)
 		}
+
+		// 根域名等通过CNAME指向该域名, Cloudflare 会自动展平(CNAME Flattening)
+		if domain.UpdateStatus != config.UpdatedFailed {
+			for _, name := range domain.GetCustomParams()["cname"] {
+				cf.ensureCname(zoneID, domain, name)
+			}
+		}
 	}
 }
 
+// ensureCname 确保 name 为指向该域名的CNAME记录, 已存在时不修改
+func (cf *Cloudflare) ensureCname(zoneID string, domain *config.Domain, name string) {
+	asciiName, err := idna.ToASCII(name)
+	if err != nil {
+		asciiName = name
+	}
+	records, err := cf.listRecords(zoneID, asciiName, "CNAME")
+	if err != nil {
+		util.Log("查询CNAME记录 %s 失败! 异常信息: %s", name, err)
+		domain.UpdateStatus = config.UpdatedFailed
+		return
+	}
+
+	target := domain.ToASCII()
+	record := CloudflareRecord{
+		Type:    "CNAME",
+		Name:    asciiName,
+		Content: target,
+		Proxied: domain.GetCustomParams().Get("proxied") == "true",
+		TTL:     cf.TTL,
+	}
+	method, api := "POST", fmt.Sprintf(zonesAPI+"/%s/dns_records", zoneID)
+	if len(records.Result) > 0 {
+		if strings.EqualFold(records.Result[0].Content, target) {
+			return
+		}
+		record.Proxied = records.Result[0].Proxied
+		method, api = "PUT", api+"/"+records.Result[0].ID
+	}
+
+	var status CloudflareStatus
+	err = cf.request(method, api, record, &status)
+	if err == nil && !status.Success {
+		err = fmt.Errorf("%s", strings.Join(status.Messages, ", "))
+	}
+	if err != nil {
+		util.Log("设置CNAME记录 %s 指向 %s 失败! 异常信息: %s", name, domain, err)
+		domain.UpdateStatus = config.UpdatedFailed
+		return
+	}
+	util.Log("已设置CNAME记录 %s 指向 %s", name, domain)
+}
+
 // 创建
 func (cf *Cloudflare) create(zoneID string, domain *config.Domain, recordType string, ipAddr string) {
 	record := &CloudflareRecord{
@@ -188,8 +240,12 @@ func (cf *Cloudflare) create(zoneID string, domain *config.Domain, recordType st
 func (cf *Cloudflare) modify(result CloudflareRecordsResp, zoneID string, domain *config.Domain, ipAddr string) {
 	for _, record := range result.Result {
 		// 相同不修改
-		if record.Content == ipAddr {
+		if util.SameIP(record.Content, ipAddr) {
 			util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+			// 不覆盖其它记录的结果
+			if domain.UpdateStatus == "" {
+				domain.UpdateStatus = config.UpdatedNothing
+			}
 			continue
 		}
 		var status CloudflareStatus
@@ -222,6 +278,67 @@ func (cf *Cloudflare) modify(result CloudflareRecordsResp, zoneID string, domain
 	}
 }
 
+// deleteDomainRecords 删除域名的记录, 用于只获取到IPv4/IPv6其中一个时删除另一个类型的记录
+func (cf *Cloudflare) deleteDomainRecords(recordType string, domains []*config.Domain) {
+	for _, domain := range domains {
+		zones, err := cf.getZones(domain)
+		if err == nil && len(zones.Result) == 0 {
+			err = fmt.Errorf("%s", util.LogStr("在DNS服务商中未找到根域名: %s", domain.DomainName))
+		}
+		if err != nil {
+			util.Log("删除域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+		zoneID := zones.Result[0].ID
+
+		records, err := cf.listRecords(zoneID, domain.ToASCII(), recordType)
+		if err != nil {
+			util.Log("删除域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+		for _, record := range records.Result {
+			var status CloudflareStatus
+			err = cf.request(
+				"DELETE",
+				fmt.Sprintf(zonesAPI+"/%s/dns_records/%s", zoneID, record.ID),
+				nil,
+				&status,
+			)
+			if err == nil && !status.Success {
+				err = fmt.Errorf("%s", strings.Join(status.Messages, ", "))
+			}
+			if err != nil {
+				util.Log("删除域名解析 %s 失败! 异常信息: %s", domain, err)
+				domain.UpdateStatus = config.UpdatedFailed
+				break
+			}
+			util.Log("删除域名解析 %s 成功! 类型: %s, IP: %s", domain, recordType, record.Content)
+			domain.UpdateStatus = config.UpdatedDeleted
+		}
+	}
+}
+
+// listRecords 获得指定名称和类型的记录
+func (cf *Cloudflare) listRecords(zoneID string, name string, recordType string) (records CloudflareRecordsResp, err error) {
+	params := url.Values{}
+	params.Set("type", recordType)
+	params.Set("name", name)
+	params.Set("per_page", "50")
+
+	err = cf.request(
+		"GET",
+		fmt.Sprintf(zonesAPI+"/%s/dns_records?%s", zoneID, params.Encode()),
+		nil,
+		&records,
+	)
+	if err == nil && !records.Success {
+		err = fmt.Errorf("%s", strings.Join(records.Messages, ", "))
+	}
+	return
+}
+
 // 获得域名记录列表
 func (cf *Cloudflare) getZones(domain *config.Domain) (result CloudflareZonesResp, err error) {
 	params := url.Values{}
@@ -256,7 +373,7 @@ func (cf *Cloudflare) request(method string, url string, data interface{}, resul
 	req.Header.Set("Authorization", "Bearer "+cf.DNS.Secret)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := cf.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -0,0 +1,160 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+const (
+	cloudflareUserPoolsAPI    = "https://api.cloudflare.com/client/v4/user/load_balancers/pools"
+	cloudflareAccountPoolsAPI = "https://api.cloudflare.com/client/v4/accounts/%s/load_balancers/pools"
+)
+
+// CloudflareLB 更新 Cloudflare 负载均衡池中源站的地址
+// 域名格式如 home.example.com?pool=池ID&origin=源站名称, origin 为空时使用域名作为源站名称
+type CloudflareLB struct {
+	DNS     config.DNS
+	Domains config.Domains
+}
+
+// CloudflareLBPoolResp 负载均衡池, 使用 map 保留其它字段, 更新时原样提交
+type CloudflareLBPoolResp struct {
+	CloudflareStatus
+	Result map[string]interface{}
+}
+
+// Init 初始化
+func (lb *CloudflareLB) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6cache *util.IpCache) {
+	lb.Domains.Ipv4Cache = ipv4cache
+	lb.Domains.Ipv6Cache = ipv6cache
+	lb.DNS = dnsConf.DNS
+	lb.Domains.GetNewIp(dnsConf)
+}
+
+// AddUpdateDomainRecords 更新源站的IPv4/IPv6地址
+func (lb *CloudflareLB) AddUpdateDomainRecords() config.Domains {
+	lb.addUpdateDomainRecords("A")
+	lb.addUpdateDomainRecords("AAAA")
+	return lb.Domains
+}
+
+func (lb *CloudflareLB) addUpdateDomainRecords(recordType string) {
+	ipAddr, domains := lb.Domains.GetNewIpResult(recordType)
+
+	if ipAddr == "" {
+		return
+	}
+
+	for _, domain := range domains {
+		params := domain.GetCustomParams()
+		poolID := params.Get("pool")
+		if poolID == "" {
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, "missing parameter pool")
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+		originName := params.Get("origin")
+		if originName == "" {
+			originName = domain.String()
+		}
+
+		var pool CloudflareLBPoolResp
+		err := lb.request("GET", lb.poolURL(poolID), nil, &pool)
+		if err != nil {
+			util.Log("查询域名信息发生异常! %s", err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+		if !pool.Success {
+			util.Log("查询域名信息发生异常! %s", strings.Join(pool.Messages, ", "))
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+
+		lb.modify(pool.Result, poolID, originName, domain, ipAddr)
+	}
+}
+
+// modify 修改源站地址并提交整个负载均衡池
+func (lb *CloudflareLB) modify(pool map[string]interface{}, poolID string, originName string, domain *config.Domain, ipAddr string) {
+	origins, _ := pool["origins"].([]interface{})
+	var origin map[string]interface{}
+	for _, o := range origins {
+		if m, ok := o.(map[string]interface{}); ok && m["name"] == originName {
+			origin = m
+			break
+		}
+	}
+	if origin == nil {
+		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, fmt.Sprintf("origin %s not found in pool %s", originName, poolID))
+		domain.UpdateStatus = config.UpdatedFailed
+		return
+	}
+
+	// 相同不修改
+	if address, _ := origin["address"].(string); util.SameIP(address, ipAddr) {
+		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
+		return
+	}
+	origin["address"] = ipAddr
+
+	// 只读字段
+	for _, key := range []string{"id", "created_on", "modified_on", "networks"} {
+		delete(pool, key)
+	}
+
+	var status CloudflareStatus
+	err := lb.request("PUT", lb.poolURL(poolID), pool, &status)
+	if err != nil {
+		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+		domain.UpdateStatus = config.UpdatedFailed
+		return
+	}
+
+	if status.Success {
+		util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
+		domain.UpdateStatus = config.UpdatedSuccess
+	} else {
+		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, strings.Join(status.Messages, ", "))
+		domain.UpdateStatus = config.UpdatedFailed
+	}
+}
+
+// poolURL 填写了 Account ID 时使用账户下的负载均衡池
+func (lb *CloudflareLB) poolURL(poolID string) string {
+	if lb.DNS.ID != "" {
+		return fmt.Sprintf(cloudflareAccountPoolsAPI, lb.DNS.ID) + "/" + poolID
+	}
+	return cloudflareUserPoolsAPI + "/" + poolID
+}
+
+// request 统一请求接口
+func (lb *CloudflareLB) request(method string, url string, data interface{}, result interface{}) (err error) {
+	jsonStr := make([]byte, 0)
+	if data != nil {
+		jsonStr, _ = json.Marshal(data)
+	}
+	req, err := http.NewRequest(
+		method,
+		url,
+		bytes.NewBuffer(jsonStr),
+	)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+lb.DNS.Secret)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := lb.Domains.HTTPClient()
+	resp, err := client.Do(req)
+	err = util.GetHTTPResponse(resp, err, result)
+
+	return
+}
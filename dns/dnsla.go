@@ -156,8 +156,9 @@ func (dnsla *Dnsla) create(domain *config.Domain, recordType string, ipAddr stri
 // 修改
 func (dnsla *Dnsla) modify(record DnslaRecord, domain *config.Domain, recordType string, ipAddr string) {
 	// 相同不修改
-	if record.Data == ipAddr {
+	if util.SameIP(record.Data, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	recordTypeInt := 1
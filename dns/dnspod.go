@@ -1,6 +1,7 @@
 package dns
 
 import (
+	"fmt"
 	"net/url"
 
 	"github.com/jeessy2/ddns-go/v6/config"
@@ -28,6 +29,7 @@ type DnspodRecord struct {
 	Type    string
 	Value   string
 	Enabled string
+	Weight  interface{} // 未设置时为 null
 }
 
 // DnspodRecordListResp recordListAPI结果
@@ -135,13 +137,15 @@ func (dnspod *Dnspod) create(domain *config.Domain, recordType string, ipAddr st
 // 修改
 func (dnspod *Dnspod) modify(record DnspodRecord, domain *config.Domain, recordType string, ipAddr string) {
 
+	params := domain.GetCustomParams()
+
 	// 相同不修改
-	if record.Value == ipAddr {
+	if dnspodRecordUnchanged(record, params, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 
-	params := domain.GetCustomParams()
 	params.Set("login_token", dnspod.DNS.ID+","+dnspod.DNS.Secret)
 	params.Set("domain", domain.DomainName)
 	params.Set("sub_domain", domain.GetSubDomain())
@@ -172,9 +176,14 @@ func (dnspod *Dnspod) modify(record DnspodRecord, domain *config.Domain, recordT
 	}
 }
 
+// dnspodRecordUnchanged IP相同且设置了权重时权重也相同, 不需要修改
+func dnspodRecordUnchanged(record DnspodRecord, params url.Values, ipAddr string) bool {
+	return util.SameIP(record.Value, ipAddr) && (!params.Has("weight") || fmt.Sprint(record.Weight) == params.Get("weight"))
+}
+
 // request sends a POST request to the given API with the given values.
 func (dnspod *Dnspod) request(apiAddr string, values url.Values) (status DnspodStatus, err error) {
-	client := util.CreateHTTPClient()
+	client := dnspod.Domains.HTTPClient()
 	resp, err := client.PostForm(
 		apiAddr,
 		values,
@@ -195,7 +204,7 @@ func (dnspod *Dnspod) getRecordList(domain *config.Domain, typ string) (result D
 	params.Set("sub_domain", domain.GetSubDomain())
 	params.Set("format", "json")
 
-	client := util.CreateHTTPClient()
+	client := dnspod.Domains.HTTPClient()
 	resp, err := client.PostForm(
 		recordListAPI,
 		params,
@@ -0,0 +1,37 @@
+package dns
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+// TestDnspodRecordUnchanged 测试IP相同但权重不同时仍需更新
+func TestDnspodRecordUnchanged(t *testing.T) {
+	tests := map[string]struct {
+		record   string
+		params   url.Values
+		ipAddr   string
+		expected bool
+	}{
+		"IP相同未设置权重":   {`{"Value":"1.1.1.1","Weight":null}`, url.Values{}, "1.1.1.1", true},
+		"IP不同":        {`{"Value":"1.1.1.1","Weight":null}`, url.Values{}, "2.2.2.2", false},
+		"IP和权重相同":     {`{"Value":"1.1.1.1","Weight":10}`, url.Values{"weight": {"10"}}, "1.1.1.1", true},
+		"IP相同权重不同":    {`{"Value":"1.1.1.1","Weight":10}`, url.Values{"weight": {"20"}}, "1.1.1.1", false},
+		"IP相同记录未设置权重": {`{"Value":"1.1.1.1","Weight":null}`, url.Values{"weight": {"10"}}, "1.1.1.1", false},
+		"IP相同权重为0":    {`{"Value":"1.1.1.1","Weight":0}`, url.Values{"weight": {"0"}}, "1.1.1.1", true},
+		"IPv6表示方式不同":  {`{"Value":"2001:0DB8::0001","Weight":5}`, url.Values{"weight": {"5"}}, "2001:db8::1", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var record DnspodRecord
+			if err := json.Unmarshal([]byte(tt.record), &record); err != nil {
+				t.Fatal(err)
+			}
+			if got := dnspodRecordUnchanged(record, tt.params, tt.ipAddr); got != tt.expected {
+				t.Errorf("期待 %t, 得到 %t", tt.expected, got)
+			}
+		})
+	}
+}
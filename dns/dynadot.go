@@ -178,7 +178,7 @@ func (dynadot *Dynadot) request(params url.Values, result interface{}) (err erro
 		return
 	}
 
-	client := util.CreateHTTPClient()
+	client := dynadot.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -82,7 +82,7 @@ func (dynv6 *Dynv6) addUpdateDomainRecords(recordType string) {
 
 		if isMain {
 			// 如果使用的域名是主域名，对比DNS记录确定是否调用更新接口
-			if (recordType == "A" && findZone.Ipv4 == ipAddr) || (recordType == "AAAA" && findZone.Ipv6 == ipAddr) {
+			if (recordType == "A" && util.SameIP(findZone.Ipv4, ipAddr)) || (recordType == "AAAA" && util.SameIP(findZone.Ipv6, ipAddr)) {
 				// ip与dns服务器一致，不执行更新
 				util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
 				domain.UpdateStatus = config.UpdatedNothing
@@ -111,7 +111,7 @@ func (dynv6 *Dynv6) addUpdateDomainRecords(recordType string) {
 
 			if isFindRecord {
 				// 判断是否需要更新
-				if findRecord.Type == recordType && findRecord.Data == ipAddr {
+				if findRecord.Type == recordType && util.SameIP(findRecord.Data, ipAddr) {
 					// ip与dns服务器一致，不执行更新
 					util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
 					domain.UpdateStatus = config.UpdatedNothing
@@ -266,7 +266,7 @@ func (dynv6 *Dynv6) request(method string, url string, data interface{}, result
 	req.Header.Add("Authorization", "Bearer "+dynv6.DNS.Secret)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := dynv6.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 	return err
@@ -3,8 +3,10 @@ package dns
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/jeessy2/ddns-go/v6/config"
 	"github.com/jeessy2/ddns-go/v6/util"
@@ -51,6 +53,10 @@ type EdgeOneZoneResponse struct {
 			ZoneId   string `json:"ZoneId"`
 			ZoneName string `json:"ZoneName"`
 		} `json:"Zones"`
+		Error struct {
+			Code    string
+			Message string
+		}
 	}
 }
 
@@ -108,18 +114,22 @@ func (eo *EdgeOne) addUpdateDomainRecords(recordType string) {
 	}
 
 	for _, domain := range domains {
-		zoneResult, err := eo.getZone(domain.DomainName)
-		if err != nil || zoneResult.Response.TotalCount <= 0 || zoneResult.Response.Zones[0].ZoneName != domain.DomainName {
+		zoneId, err := eo.getZoneId(domain.DomainName)
+		if err != nil {
 			util.Log("查询域名信息发生异常! %s", err)
 			domain.UpdateStatus = config.UpdatedFailed
-			return
+			continue
+		}
+		if zoneId == "" {
+			util.Log("在DNS服务商中未找到根域名: %s", domain.DomainName)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
 		}
-		zoneId := zoneResult.Response.Zones[0].ZoneId
 		recordResult, err := eo.getRecordList(domain, recordType, zoneId)
 		if err != nil {
 			util.Log("查询域名信息发生异常! %s", err)
 			domain.UpdateStatus = config.UpdatedFailed
-			return
+			continue
 		}
 
 		params := domain.GetCustomParams()
@@ -128,7 +138,7 @@ func (eo *EdgeOne) addUpdateDomainRecords(recordType string) {
 			isValid = func(r *EdgeOneRecord) bool { return r.RecordId == params.Get("RecordId") }
 		} else {
 			isValid = func(r *EdgeOneRecord) bool {
-				return r.Status == "enable" || r.Status == "disable" && r.Content == ipAddr
+				return r.Status == "enable" || r.Status == "disable" && util.SameIP(r.Content, ipAddr)
 			}
 		}
 		var recordSelected *EdgeOneRecord
@@ -189,8 +199,9 @@ func (eo *EdgeOne) create(domain *config.Domain, recordType string, ipAddr strin
 // ModifyDnsRecords https://cloud.tencent.com/document/product/1552/114252
 func (eo *EdgeOne) modify(record EdgeOneRecord, domain *config.Domain, recordType string, ipAddr string, ZoneId string) {
 	// 相同不修改
-	if record.Content == ipAddr {
+	if util.SameIP(record.Content, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	var status EdgeOneStatus
@@ -233,19 +244,35 @@ func (eo *EdgeOne) modify(record EdgeOneRecord, domain *config.Domain, recordTyp
 	}
 }
 
-func (eo *EdgeOne) getZone(domain string) (result EdgeOneZoneResponse, err error) {
+// getZoneId 查询根域名对应的站点ID, 未找到时返回空
+// DescribeZones https://cloud.tencent.com/document/product/1552/80713
+func (eo *EdgeOne) getZoneId(domain string) (zoneId string, err error) {
 	asciiDomain, _ := idna.ToASCII(domain)
 	record := EdgeOneDescribeDns{
 		Filters: []Filter{
 			{Name: "zone-name", Values: []string{asciiDomain}},
 		},
 	}
+	var result EdgeOneZoneResponse
 	err = eo.request(
 		"DescribeZones",
 		record,
 		&result,
 	)
-	return
+	if err != nil {
+		return "", err
+	}
+	if result.Response.Error.Code != "" {
+		return "", errors.New(result.Response.Error.Message)
+	}
+
+	// zone-name 为模糊匹配, 需找到名称相同的站点
+	for _, zone := range result.Response.Zones {
+		if strings.EqualFold(strings.TrimSuffix(zone.ZoneName, "."), asciiDomain) {
+			return zone.ZoneId, nil
+		}
+	}
+	return "", nil
 }
 
 // DescribeDnsRecords https://cloud.tencent.com/document/product/1552/80716
@@ -300,7 +327,7 @@ func (eo *EdgeOne) request(action string, data interface{}, result interface{})
 
 	util.TencentCloudSigner(eo.DNS.ID, eo.DNS.Secret, req, action, string(jsonStr), util.EdgeOne)
 
-	client := util.CreateHTTPClient()
+	client := eo.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -134,8 +134,9 @@ func (eranet *Eranet) create(domain *config.Domain, recordType string, ipAddr st
 // modify 修改DNS记录
 func (eranet *Eranet) modify(record EranetRecord, domain *config.Domain, recordType string, ipAddr string) {
 	// 相同不修改
-	if record.Value == ipAddr {
+	if util.SameIP(record.Value, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	param := map[string]string{
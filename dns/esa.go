@@ -2,11 +2,16 @@ package dns
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/jeessy2/ddns-go/v6/config"
 	"github.com/jeessy2/ddns-go/v6/util"
@@ -14,13 +19,34 @@ import (
 
 const (
 	esaEndpoint string = "https://esa.cn-hangzhou.aliyuncs.com/"
+	// esaPageSize records per page of ListRecords
+	esaPageSize = 100
+	// esaMaxPages stop listing records after this many pages
+	esaMaxPages = 20
+	// esaDomainConcurrency domains updated at the same time by default
+	esaDomainConcurrency = 4
 )
 
 // ESA Alibaba Cloud ESA
 type ESA struct {
-	DNS     config.DNS
-	Domains config.Domains
-	TTL     string
+	DNS           config.DNS
+	Domains       config.Domains
+	TTL           string
+	RecordIdCache bool
+	// concurrency domains updated at the same time, see config.DnsConfig.DomainConcurrency
+	concurrency int
+	// dryRun only list the records, see config.DnsConfig.DryRun
+	dryRun bool
+	// verify list the records again after they are changed, see config.DnsConfig.VerifyRecord
+	verify bool
+	// providerTTL the empty TTL uses the default TTL of the site, see config.DnsConfig.ProviderTTL
+	providerTTL bool
+	// managedTag/cleanupConfirm see config.DnsConfig.Cleanup
+	managedTag     string
+	cleanupConfirm bool
+	// endpointErr the configured endpoint is invalid, all requests fail with it
+	endpointErr error
+	retry       aliyunRetry
 }
 
 // ESARecord record
@@ -28,9 +54,41 @@ type ESARecord struct {
 	RecordId   int64
 	RecordName string
 	Type       string
-	Data       struct {
-		Value string
+	// BizName the business scenario (line) of the record, e.g. web, api, image_video
+	BizName string
+	Comment string
+	Data    ESARecordData
+}
+
+// ESARecordData the Data of the record, Extra holds the fields other than Value, e.g. Priority/Weight
+type ESARecordData struct {
+	Value string
+	Extra map[string]json.RawMessage
+}
+
+// UnmarshalJSON keeps the fields other than Value in Extra
+func (d *ESARecordData) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	if value, ok := fields["Value"]; ok {
+		json.Unmarshal(value, &d.Value)
+		delete(fields, "Value")
 	}
+	d.Extra = fields
+	return nil
+}
+
+// MarshalJSON merges Extra with Value, Value always wins
+func (d ESARecordData) MarshalJSON() ([]byte, error) {
+	fields := maps.Clone(d.Extra)
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	value, _ := json.Marshal(d.Value)
+	fields["Value"] = value
+	return json.Marshal(fields)
 }
 
 // ESASite site
@@ -48,6 +106,8 @@ type ESAListSitesResp struct {
 // ESAListRecordsResp list records response
 type ESAListRecordsResp struct {
 	TotalCount int
+	PageNumber int
+	PageSize   int
 	Records    []ESARecord
 }
 
@@ -62,13 +122,80 @@ func (esa *ESA) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6cac
 	esa.Domains.Ipv4Cache = ipv4cache
 	esa.Domains.Ipv6Cache = ipv6cache
 	esa.DNS = dnsConf.DNS
+	esa.RecordIdCache = dnsConf.RecordIdCache
+	esa.dryRun = dnsConf.DryRun
+	esa.verify = dnsConf.VerifyRecord
+	esa.concurrency = esaDomainConcurrency
+	if dnsConf.DomainConcurrency > 0 {
+		esa.concurrency = dnsConf.DomainConcurrency
+	}
+	esa.managedTag, esa.cleanupConfirm = dnsConf.Cleanup.Tag, dnsConf.Cleanup.Confirm
+	esa.retry = newAliyunRetry(dnsConf)
+	esa.endpointErr = nil
+	if esa.DNS.Endpoint != "" {
+		if err := checkESAEndpoint(esa.DNS.Endpoint); err != nil {
+			util.Log("%s", err)
+			esa.endpointErr = err
+		}
+	}
 	esa.Domains.GetNewIp(dnsConf)
-	if dnsConf.TTL == "" {
-		// Default to 1 (automatic) or 600? API says 30~86400 or 1.
-		esa.TTL = "30"
-	} else {
-		esa.TTL = dnsConf.TTL
+	// Empty TTL uses the default, see getTTL
+	esa.TTL = normalizeESATTL(dnsConf.TTL)
+	esa.providerTTL = dnsConf.ProviderTTL
+}
+
+// normalizeESATTL ESA only accepts 1 (automatic) or 30~86400, out-of-range values are clamped
+func normalizeESATTL(ttl string) string {
+	ttl = strings.TrimSpace(ttl)
+	if ttl == "" || ttl == "1" {
+		return ttl
+	}
+	n, err := strconv.Atoi(ttl)
+	switch {
+	case err != nil:
+		util.Log("ESA TTL %s is not a number, the default TTL is used", ttl)
+		return ""
+	case n < 30:
+		util.Log("ESA TTL %s is out of range (1 or 30~86400), %s is used", ttl, "30")
+		return "30"
+	case n > 86400:
+		util.Log("ESA TTL %s is out of range (1 or 30~86400), %s is used", ttl, "86400")
+		return "86400"
+	}
+	return strconv.Itoa(n)
+}
+
+// checkESAEndpoint the endpoint must be a well-formed https URL, e.g. https://esa.ap-southeast-1.aliyuncs.com/
+func checkESAEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid ESA endpoint %q, it must be an https URL such as %s", endpoint, esaEndpoint)
+	}
+	return nil
+}
+
+// getTTL returns the configured TTL (1 is automatic), or 30 if not configured.
+// With ProviderTTL the empty TTL is sent as 1, so ESA applies the default TTL of the site.
+// A higher minimum TTL returned by ESA for the site is used instead, see learnMinTTL
+func (esa *ESA) getTTL(domain *config.Domain) string {
+	ttl := esa.TTL
+	if ttl == "" {
+		ttl = "30"
+		if esa.providerTTL {
+			ttl = "1"
+		}
 	}
+	return zoneMinTTL("esa", domain.DomainName, ttl)
+}
+
+// requestWithTTL sends CreateRecord/UpdateRecord, retries once with the minimum TTL when ESA rejects the TTL
+func (esa *ESA) requestWithTTL(domain *config.Domain, params url.Values, result interface{}) error {
+	err := esa.request(params, result)
+	if err != nil && learnMinTTL("esa", domain.DomainName, params.Get("TTL"), err) {
+		params.Set("TTL", esa.getTTL(domain))
+		err = esa.request(params, result)
+	}
+	return err
 }
 
 // AddUpdateDomainRecords add or update IPv4/IPv6 records
@@ -82,160 +209,451 @@ func (esa *ESA) addUpdateDomainRecords(recordType string) {
 	ipAddr, domains := esa.Domains.GetNewIpResult(recordType)
 
 	if ipAddr == "" {
+		esa.deleteDomainRecords(recordType, esa.Domains.GetDeleteDomains(recordType))
 		return
 	}
 
-	for _, domain := range domains {
-		// Get SiteId
-		siteId, err := esa.getSiteId(domain.DomainName)
-		if err != nil {
-			util.Log("Failed to get Site ID for %s: %s", domain.DomainName, err)
-			domain.UpdateStatus = config.UpdatedFailed
-			continue
-		}
+	forEachDomain(domains, esa.concurrency, func(domain *config.Domain) {
+		esa.addUpdateDomainRecord(recordType, ipAddr, domain)
+		esa.classifyFailure(domain)
+		addStatusMetric("esa", domain, recordType)
+	})
+}
 
-		// List existing records
-		records, err := esa.listRecords(siteId, domain, recordType)
-		if err != nil {
-			util.Log("Failed to list records for %s: %s", domain.GetFullDomain(), err)
-			domain.UpdateStatus = config.UpdatedFailed
-			continue
-		}
+// classifyFailure marks the failures that need user action (AccessKey, signature, permission or endpoint),
+// other failures such as network errors, throttling and 5xx are retried on the next run
+func (esa *ESA) classifyFailure(domain *config.Domain) {
+	if domain.UpdateStatus != config.UpdatedFailed {
+		return
+	}
+	if esa.endpointErr != nil || isAliyunPermanentError(domain.UpdateError) {
+		domain.FailurePermanent = true
+	}
+	if domain.FailurePermanent {
+		util.Log("域名 %s 更新失败, 需要检查配置(如AccessKey、权限、Endpoint), 重试不会成功", domain)
+	}
+}
 
-		if len(records) > 0 {
-			// Update existing record
-			// Assuming we update the first matching record if multiple exist
-			esa.modify(siteId, records[0], domain, recordType, ipAddr)
-		} else {
-			// Create new record
-			esa.create(siteId, domain, recordType, ipAddr)
-		}
+func (esa *ESA) addUpdateDomainRecord(recordType string, ipAddr string, domain *config.Domain) {
+	// The domain may publish another host behind the router with ipv6suffix
+	ipAddr, ok := domainIpAddr(recordType, ipAddr, domain)
+	if !ok {
+		return
 	}
+	// Update by the cached RecordId, skip ListSites/ListRecords
+	if esa.useCachedId(domain) && esa.updateByCachedId(domain, recordType, ipAddr) {
+		return
+	}
+	addUpdateRecord(esa, recordType, ipAddr, domain)
+}
+
+// deleteDomainRecords deletes the records of the domains, used to remove the records of the missing IP family
+func (esa *ESA) deleteDomainRecords(recordType string, domains []*config.Domain) {
+	deleteRecords(esa, recordType, domains)
+}
+
+// useCachedId the cache is not used when all the records are needed: dry run, records=all and cleanup
+func (esa *ESA) useCachedId(domain *config.Domain) bool {
+	return esa.RecordIdCache && !esa.dryRun && !domain.UpdateAllRecords() && esa.managedTag == ""
 }
 
+// cleanup see duplicateCleaner
+func (esa *ESA) cleanup() (string, bool) {
+	return esa.managedTag, esa.cleanupConfirm
+}
+
+// DryRun only the read-only ListSites/ListRecords are called, the changes are logged
+func (esa *ESA) DryRun() bool {
+	return esa.dryRun
+}
+
+// verifyRecord see recordVerifier
+func (esa *ESA) verifyRecord() bool {
+	return esa.verify
+}
+
+// ListRecords lists the records of the domain, the first record is cached when RecordIdCache is enabled
+func (esa *ESA) ListRecords(domain *config.Domain, recordType string) ([]ProviderRecord, error) {
+	siteId, err := esa.getSiteId(domain.DomainName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Site ID for %s: %s", domain.DomainName, err)
+	}
+
+	records, err := esa.listRecords(siteId, domain, recordType)
+	if err != nil {
+		esa.forgetSiteId(domain, err)
+		return nil, fmt.Errorf("failed to list records for %s: %s", domain.GetFullDomain(), err)
+	}
+
+	// The record is updated even if the IP is the same when the Data.* params changed
+	extra := esaCustomData(domain.GetCustomParams())
+	result := make([]ProviderRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, ProviderRecord{
+			ZoneID:   strconv.FormatInt(siteId, 10),
+			ID:       strconv.FormatInt(record.RecordId, 10),
+			Value:    record.Data.Value,
+			Comment:  record.Comment,
+			Outdated: !esaDataMatches(record.Data, extra),
+		})
+	}
+	if len(records) > 0 {
+		esa.setCachedId(domain, recordType, siteId, records[0].RecordId, records[0].Data.Value)
+	} else {
+		util.DeleteRecordIdCache(esa.cacheKey(domain, recordType))
+	}
+	return result, nil
+}
+
+// CreateRecord creates the record
+func (esa *ESA) CreateRecord(domain *config.Domain, recordType string, value string) error {
+	siteId, err := esa.getSiteId(domain.DomainName)
+	if err != nil {
+		return err
+	}
+	recordId, err := esa.create(siteId, domain, recordType, value)
+	if err != nil {
+		esa.forgetSiteId(domain, err)
+		return err
+	}
+	esa.setCachedId(domain, recordType, siteId, recordId, value)
+	return nil
+}
+
+// UpdateRecord updates the record to the value
+func (esa *ESA) UpdateRecord(domain *config.Domain, recordType string, record ProviderRecord, value string) error {
+	siteId, recordId, err := parseESARecord(record)
+	if err != nil {
+		return err
+	}
+	if err = esa.updateRecord(siteId, recordId, domain, recordType, value); err != nil {
+		esa.forgetSiteId(domain, err)
+		return err
+	}
+	esa.setCachedId(domain, recordType, siteId, recordId, value)
+	return nil
+}
+
+// DeleteRecord deletes the record
+func (esa *ESA) DeleteRecord(domain *config.Domain, recordType string, record ProviderRecord) error {
+	_, recordId, err := parseESARecord(record)
+	if err != nil {
+		return err
+	}
+	if err = esa.deleteRecord(recordId); err != nil {
+		return err
+	}
+	util.DeleteRecordIdCache(esa.cacheKey(domain, recordType))
+	return nil
+}
+
+// parseESARecord returns the SiteId and RecordId of the record
+func parseESARecord(record ProviderRecord) (siteId int64, recordId int64, err error) {
+	if siteId, err = strconv.ParseInt(record.ZoneID, 10, 64); err != nil {
+		return
+	}
+	recordId, err = strconv.ParseInt(record.ID, 10, 64)
+	return
+}
+
+// deleteRecord deletes the record by RecordId
+func (esa *ESA) deleteRecord(recordId int64) error {
+	params := url.Values{}
+	params.Set("Action", "DeleteRecord")
+	params.Set("Version", "2024-09-10")
+	params.Set("RecordId", strconv.FormatInt(recordId, 10))
+
+	var result ESAResp
+	return esa.request(params, &result)
+}
+
+// getSiteId returns the SiteId of the apex domain, cached for the lifetime of the process and shared by all record types
 func (esa *ESA) getSiteId(domainName string) (int64, error) {
+	id, err := resolveAliyunZone("esa", esa.DNS.ID, domainName, esa.lookupSiteId)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+func (esa *ESA) lookupSiteId(siteName string) (string, error) {
 	params := url.Values{}
 	params.Set("Action", "ListSites")
 	params.Set("Version", "2024-09-10")
-	params.Set("SiteName", domainName)
+	params.Set("SiteName", siteName)
 	params.Set("ExactMatch", "true") // Ensure exact match
 
 	var result ESAListSitesResp
 	err := esa.request(params, &result)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
-	if result.TotalCount == 0 || len(result.Sites) == 0 {
-		return 0, fmt.Errorf("site not found for domain: %s", domainName)
+	for _, site := range result.Sites {
+		if aliyunZoneName(site.SiteName) == siteName {
+			return strconv.FormatInt(site.SiteId, 10), nil
+		}
 	}
-
-	return result.Sites[0].SiteId, nil
+	return "", fmt.Errorf("site not found for domain: %s", siteName)
 }
 
+// listRecords lists all pages of the records, only the records matching the name and type are returned.
+// When the domain has a BizName, records of other BizNames are distinct records and are not returned
 func (esa *ESA) listRecords(siteId int64, domain *config.Domain, recordType string) ([]ESARecord, error) {
-	params := url.Values{}
-	params.Set("Action", "ListRecords")
-	params.Set("Version", "2024-09-10")
-	params.Set("SiteId", strconv.FormatInt(siteId, 10))
-	params.Set("RecordName", domain.GetFullDomain())
-	params.Set("RecordNameMode", "exact")
-	params.Set("Type", recordType)
+	var records []ESARecord
+	bizName := esaBizName(domain)
+	listed := 0
+	for page := 1; ; page++ {
+		if page > esaMaxPages {
+			util.Log("Too many records for %s, only the first %d pages are listed", domain.GetFullDomain(), esaMaxPages)
+			break
+		}
+		params := url.Values{}
+		params.Set("Action", "ListRecords")
+		params.Set("Version", "2024-09-10")
+		params.Set("SiteId", strconv.FormatInt(siteId, 10))
+		params.Set("RecordName", domain.GetFullDomain())
+		params.Set("RecordNameMode", "exact")
+		params.Set("Type", recordType)
+		if bizName != "" {
+			params.Set("BizName", bizName)
+		}
+		params.Set("PageNumber", strconv.Itoa(page))
+		params.Set("PageSize", strconv.Itoa(esaPageSize))
 
-	var result ESAListRecordsResp
-	err := esa.request(params, &result)
-	if err != nil {
-		return nil, err
+		var result ESAListRecordsResp
+		err := esa.request(params, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range result.Records {
+			if strings.EqualFold(strings.TrimSuffix(record.RecordName, "."), domain.GetFullDomain()) && record.Type == recordType &&
+				(bizName == "" || strings.EqualFold(record.BizName, bizName)) {
+				records = append(records, record)
+			}
+		}
+		listed += len(result.Records)
+		if len(result.Records) == 0 || listed >= result.TotalCount {
+			break
+		}
 	}
 
-	return result.Records, nil
+	return records, nil
 }
 
-func (esa *ESA) create(siteId int64, domain *config.Domain, recordType string, ipAddr string) {
+// create creates the record and returns its RecordId
+func (esa *ESA) create(siteId int64, domain *config.Domain, recordType string, ipAddr string) (int64, error) {
 	params := domain.GetCustomParams()
 	params.Set("Action", "CreateRecord")
 	params.Set("Version", "2024-09-10")
 	params.Set("SiteId", strconv.FormatInt(siteId, 10))
 	params.Set("RecordName", domain.GetFullDomain())
 	params.Set("Type", recordType)
-	
-	// Construct Data JSON
-	data := map[string]string{
-		"Value": ipAddr,
-	}
-	dataBytes, _ := json.Marshal(data)
+
+	// Construct Data JSON with the Data.* params of the domain
+	dataBytes, _ := json.Marshal(ESARecordData{Value: ipAddr, Extra: esaCustomData(params)})
 	params.Set("Data", string(dataBytes))
-	
-	params.Set("TTL", esa.TTL)
 
+	params.Set("TTL", esa.getTTL(domain))
+	if esa.managedTag != "" {
+		params.Set("Comment", esa.managedTag)
+	}
+
+	// RecordId is 0 if the response does not contain it, then it is not cached
 	var result ESAResp
-	err := esa.request(params, &result)
+	err := esa.requestWithTTL(domain, params, &result)
+	if err == nil {
+		addMetric("esa", domain, recordType, MetricCreate)
+	}
+	return result.RecordId, err
+}
+
+// updateByCachedId updates the record by the cached SiteId/RecordId.
+// Returns false when there is no cache or the record no longer exists, then the records are listed again.
+func (esa *ESA) updateByCachedId(domain *config.Domain, recordType string, ipAddr string) bool {
+	key := esa.cacheKey(domain, recordType)
+	entry, ok := util.GetRecordIdCache(key)
+	if !ok {
+		return false
+	}
+	siteId, err1 := strconv.ParseInt(entry.ZoneID, 10, 64)
+	recordId, err2 := strconv.ParseInt(entry.RecordID, 10, 64)
+	if err1 != nil || err2 != nil {
+		util.DeleteRecordIdCache(key)
+		return false
+	}
+
+	if util.SameIP(entry.Value, ipAddr) && !domain.ForceUpdate() {
+		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
+		return true
+	}
 
+	err := esa.updateRecord(siteId, recordId, domain, recordType, ipAddr)
 	if err != nil {
-		util.Log("新增域名解析 %s 失败! 异常信息: %s", domain, err)
-		domain.UpdateStatus = config.UpdatedFailed
-		return
+		if isESANotFound(err) {
+			util.Log("缓存的记录ID %s 已不存在, 重新查询域名 %s", entry.RecordID, domain)
+			util.DeleteRecordIdCache(key)
+			forgetAliyunZone("esa", esa.DNS.ID, domain.DomainName)
+			return false
+		}
+		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+		domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+		return true
 	}
 
-	// CreateRecord response doesn't strictly guarantee RecordId presence in all APIs, 
-    // but usually it returns it. The struct field int defaults to 0.
-    // If successful, error should be nil.
-	util.Log("新增域名解析 %s 成功! IP: %s", domain, ipAddr)
+	domain.OldIP = entry.Value
+	esa.setCachedId(domain, recordType, siteId, recordId, ipAddr)
+	util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
 	domain.UpdateStatus = config.UpdatedSuccess
+	verifyRecord(esa, recordType, ipAddr, domain)
+	return true
 }
 
-func (esa *ESA) modify(siteId int64, record ESARecord, domain *config.Domain, recordType string, ipAddr string) {
-	if record.Data.Value == ipAddr {
-		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
-		return
-	}
-
+func (esa *ESA) updateRecord(siteId int64, recordId int64, domain *config.Domain, recordType string, ipAddr string) error {
 	params := domain.GetCustomParams()
 	params.Set("Action", "UpdateRecord")
 	params.Set("Version", "2024-09-10")
 	params.Set("SiteId", strconv.FormatInt(siteId, 10))
-	params.Set("RecordId", strconv.FormatInt(record.RecordId, 10))
+	params.Set("RecordId", strconv.FormatInt(recordId, 10))
 	params.Set("RecordName", domain.GetFullDomain()) // Some APIs require this even for update
 	params.Set("Type", recordType)
-    
-	// Construct Data JSON
-	data := map[string]string{
-		"Value": ipAddr,
-	}
-	dataBytes, _ := json.Marshal(data)
+
+	// Construct Data JSON with the Data.* params of the domain
+	dataBytes, _ := json.Marshal(ESARecordData{Value: ipAddr, Extra: esaCustomData(params)})
 	params.Set("Data", string(dataBytes))
-    
-    // Use configured TTL or default
-	params.Set("TTL", esa.TTL)
+
+	// Use configured TTL or default
+	params.Set("TTL", esa.getTTL(domain))
+	if esa.managedTag != "" {
+		params.Set("Comment", esa.managedTag)
+	}
 
 	var result ESAResp
-	err := esa.request(params, &result)
+	err := esa.requestWithTTL(domain, params, &result)
+	if err == nil {
+		addMetric("esa", domain, recordType, MetricUpdate)
+	}
+	return err
+}
 
-	if err != nil {
-		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
-		domain.UpdateStatus = config.UpdatedFailed
+func (esa *ESA) cacheKey(domain *config.Domain, recordType string) string {
+	key := []string{"esa", esa.DNS.ID, domain.GetFullDomain(), recordType}
+	if bizName := esaBizName(domain); bizName != "" {
+		key = append(key, bizName)
+	}
+	// The cached record is only reused while the Data.* params are unchanged
+	if extra := esaCustomData(domain.GetCustomParams()); len(extra) > 0 {
+		data, _ := json.Marshal(extra)
+		key = append(key, string(data))
+	}
+	return strings.Join(key, "|")
+}
+
+// esaCustomData removes the Data.* params and returns them as the extra Data fields, e.g. Data.Priority=10.
+// Values that are valid JSON (numbers, true/false) are sent as is, others as strings. Data.Value is ignored, it is always the IP
+func esaCustomData(params url.Values) map[string]json.RawMessage {
+	var extra map[string]json.RawMessage
+	for key := range params {
+		name, ok := strings.CutPrefix(key, "Data.")
+		if !ok {
+			continue
+		}
+		value := params.Get(key)
+		params.Del(key)
+		if name == "" || name == "Value" {
+			continue
+		}
+		raw := json.RawMessage(value)
+		if !json.Valid(raw) {
+			raw, _ = json.Marshal(value)
+		}
+		if extra == nil {
+			extra = map[string]json.RawMessage{}
+		}
+		extra[name] = raw
+	}
+	return extra
+}
+
+// esaDataMatches the extra fields of the record equal the Data.* params, other fields returned by ESA are ignored
+func esaDataMatches(data ESARecordData, extra map[string]json.RawMessage) bool {
+	for name, want := range extra {
+		var got, expected interface{}
+		if json.Unmarshal(data.Extra[name], &got) != nil || json.Unmarshal(want, &expected) != nil ||
+			!reflect.DeepEqual(got, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// esaBizName the BizName of the domain, e.g. www:example.com?BizName=api.
+// It is sent with CreateRecord/UpdateRecord as a custom parameter
+func esaBizName(domain *config.Domain) string {
+	return domain.GetCustomParams().Get("BizName")
+}
+
+func (esa *ESA) setCachedId(domain *config.Domain, recordType string, siteId int64, recordId int64, ipAddr string) {
+	if !esa.RecordIdCache || recordId == 0 {
 		return
 	}
+	util.SetRecordIdCache(esa.cacheKey(domain, recordType), util.RecordIdCacheEntry{
+		ZoneID:   strconv.FormatInt(siteId, 10),
+		RecordID: strconv.FormatInt(recordId, 10),
+		Value:    ipAddr,
+	})
+}
 
-	util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
-	domain.UpdateStatus = config.UpdatedSuccess
+// forgetSiteId removes the cached SiteId when the site was deleted or does not match the domain
+func (esa *ESA) forgetSiteId(domain *config.Domain, err error) {
+	if isESANotFound(err) {
+		forgetAliyunZone("esa", esa.DNS.ID, domain.DomainName)
+	}
+}
+
+// esaNotFoundSubjects the error codes of a deleted site/record start with these, e.g. Site.NotFound, InvalidRecordId.NotExist.
+// Codes of other resources such as InvalidAccessKeyId.NotFound are configuration errors
+var esaNotFoundSubjects = []string{"Site", "SiteId", "Record", "RecordId"}
+
+// isESANotFound the record or site was deleted, or the site does not match the domain
+func isESANotFound(err error) bool {
+	m := aliyunErrorCodeRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return false
+	}
+	subject, reason, ok := strings.Cut(m[1], ".")
+	if !ok || !slices.Contains(esaNotFoundSubjects, strings.TrimPrefix(subject, "Invalid")) {
+		return false
+	}
+	return strings.Contains(reason, "NotExist") || strings.Contains(reason, "NotFound") || strings.Contains(reason, "Mismatch")
 }
 
 func (esa *ESA) request(params url.Values, result interface{}) error {
-	util.AliyunSigner(esa.DNS.ID, esa.DNS.Secret, &params)
+	if esa.endpointErr != nil {
+		return esa.endpointErr
+	}
 
-	req, err := http.NewRequest(
-		"GET",
-		esaEndpoint,
-		bytes.NewBuffer(nil),
-	)
-	if err != nil {
-		return err
+	endpoint := esaEndpoint
+	if esa.DNS.Endpoint != "" {
+		endpoint = esa.DNS.Endpoint
 	}
-	
-	req.URL.RawQuery = params.Encode()
+	return esa.retry.do(esa.Domains.Context(), func(ctx context.Context) (*http.Response, error) {
+		// Sign a copy for each attempt, the nonce can not be reused
+		signed := maps.Clone(params)
+		if err := util.AliyunSigner(esa.DNS.ID, esa.DNS.Secret, &signed); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, bytes.NewBuffer(nil))
+		if err != nil {
+			return nil, err
+		}
+		req.URL.RawQuery = signed.Encode()
 
-	client := util.CreateHTTPClient()
-	resp, err := client.Do(req)
-	return util.GetHTTPResponse(resp, err, result)
+		client := esa.Domains.HTTPClient()
+		// 超时由 ctx 控制, 见 DnsConfig.HTTPTimeout
+		client.Timeout = 0
+		return client.Do(req)
+	}, result)
 }
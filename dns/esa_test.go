@@ -0,0 +1,943 @@
+package dns
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// esaHandler 处理ESA API一个 Action 的请求
+type esaHandler func(w http.ResponseWriter, q url.Values)
+
+// esaStub 模拟ESA API的测试服务
+// ListSites 返回 sites 中按 SiteName 找到的站点, ListRecords 返回 records 的结果,
+// CreateRecord/UpdateRecord/DeleteRecord 默认成功, handlers 按 Action 覆盖默认的处理
+type esaStub struct {
+	*httptest.Server
+	t        *testing.T
+	sites    map[string]int64
+	records  func(q url.Values) []ESARecord
+	handlers map[string]esaHandler
+	// delay 每个请求的处理时间
+	delay time.Duration
+	// inFlight 正在处理的请求数, maxInFlight 同时处理的最大请求数
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+
+	mu       sync.Mutex
+	received []url.Values
+}
+
+// newESAStub 启动模拟ESA API的测试服务, 测试结束时关闭
+func newESAStub(t *testing.T, sites map[string]int64, records func(q url.Values) []ESARecord, handlers map[string]esaHandler) *esaStub {
+	stub := &esaStub{t: t, sites: sites, records: records, handlers: handlers}
+	stub.Server = httptest.NewServer(http.HandlerFunc(stub.serveHTTP))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// endpoint 作为 DNS.Endpoint 的地址
+func (stub *esaStub) endpoint() string {
+	return stub.URL + "/"
+}
+
+// actions 按顺序返回收到的请求的 Action
+func (stub *esaStub) actions() (actions []string) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	for _, q := range stub.received {
+		actions = append(actions, q.Get("Action"))
+	}
+	return
+}
+
+// requests 按顺序返回收到的 action 的请求参数
+func (stub *esaStub) requests(action string) (list []url.Values) {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	for _, q := range stub.received {
+		if q.Get("Action") == action {
+			list = append(list, q)
+		}
+	}
+	return
+}
+
+func (stub *esaStub) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	stub.mu.Lock()
+	stub.received = append(stub.received, q)
+	stub.mu.Unlock()
+
+	n := stub.inFlight.Add(1)
+	defer stub.inFlight.Add(-1)
+	for {
+		seen := stub.maxInFlight.Load()
+		if n <= seen || stub.maxInFlight.CompareAndSwap(seen, n) {
+			break
+		}
+	}
+	time.Sleep(stub.delay)
+
+	action := q.Get("Action")
+	if handler, ok := stub.handlers[action]; ok {
+		handler(w, q)
+		return
+	}
+	var resp interface{}
+	switch action {
+	case "ListSites":
+		sites := ESAListSitesResp{}
+		if id, ok := stub.sites[q.Get("SiteName")]; ok {
+			sites = ESAListSitesResp{TotalCount: 1, Sites: []ESASite{{SiteId: id, SiteName: q.Get("SiteName")}}}
+		}
+		resp = sites
+	case "ListRecords":
+		var records []ESARecord
+		if stub.records != nil {
+			records = stub.records(q)
+		}
+		resp = ESAListRecordsResp{TotalCount: len(records), Records: records}
+	case "CreateRecord", "UpdateRecord", "DeleteRecord":
+		resp = ESAResp{RequestId: "1", RecordId: 1}
+	default:
+		stub.t.Errorf("unexpected action %s", action)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// esaUnexpected 不应收到该 Action 的请求
+func esaUnexpected(t *testing.T) esaHandler {
+	return func(w http.ResponseWriter, q url.Values) {
+		t.Errorf("unexpected action %s", q.Get("Action"))
+		json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+	}
+}
+
+// esaError 返回ESA的错误
+func esaError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	w.Write([]byte(`{"Code":"` + code + `"}`))
+}
+
+// esaRecord 值为 value 的记录
+func esaRecord(id int64, name, recordType, value string) ESARecord {
+	record := ESARecord{RecordId: id, RecordName: name, Type: recordType}
+	record.Data.Value = value
+	return record
+}
+
+// TestESAListRecordsPaging 测试记录在第2页时更新该记录, 而不是新增重复的记录
+func TestESAListRecordsPaging(t *testing.T) {
+	var updated string
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, nil, map[string]esaHandler{
+		"ListRecords": func(w http.ResponseWriter, q url.Values) {
+			page := ESAListRecordsResp{TotalCount: 2, PageNumber: 1, PageSize: 1, Records: []ESARecord{esaRecord(1, "other.example.com", "A", "1.1.1.1")}}
+			if q.Get("PageNumber") == "2" {
+				page.PageNumber = 2
+				page.Records = []ESARecord{esaRecord(2, "www.example.com", "A", "1.1.1.1")}
+			}
+			json.NewEncoder(w).Encode(page)
+		},
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			updated = q.Get("RecordId")
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	esa := &ESA{
+		DNS: config.DNS{ID: "esa-paging-test", Secret: "secret", Endpoint: stub.endpoint()},
+		TTL: "600",
+	}
+	esa.Domains.Ipv4Addr = "2.2.2.2"
+	esa.Domains.Ipv4Cache = &util.IpCache{}
+	esa.Domains.Ipv4Domains = []*config.Domain{domain}
+	esa.addUpdateDomainRecords("A")
+
+	if updated != "2" || domain.UpdateStatus != config.UpdatedSuccess {
+		t.Errorf("期待更新第2页的记录, 更新的记录为 %q, 状态 %s", updated, domain.UpdateStatus)
+	}
+}
+
+// TestESAEndpoint 测试使用配置的 Endpoint, 不正确时不发送请求
+func TestESAEndpoint(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"RequestId":"1"}`))
+	}))
+	defer server.Close()
+
+	esa := &ESA{DNS: config.DNS{ID: "id", Secret: "secret", Endpoint: server.URL + "/vpc/"}}
+	if err := esa.request(url.Values{"Action": {"ListSites"}}, &ESAResp{}); err != nil || path != "/vpc/" {
+		t.Errorf("期待请求配置的 Endpoint, 请求路径 %q, 错误 %v", path, err)
+	}
+
+	for endpoint, valid := range map[string]bool{
+		"https://esa.ap-southeast-1.aliyuncs.com/": true,
+		"http://esa.cn-hangzhou.aliyuncs.com/":     false,
+		"esa.cn-hangzhou.aliyuncs.com":             false,
+		"https://":                                 false,
+	} {
+		if err := checkESAEndpoint(endpoint); (err == nil) != valid {
+			t.Errorf("checkESAEndpoint(%q) = %v", endpoint, err)
+		}
+	}
+
+	path = ""
+	esa.Init(&config.DnsConfig{DNS: config.DNS{ID: "id", Secret: "secret", Endpoint: "http://" + server.Listener.Addr().String() + "/"}}, &util.IpCache{}, &util.IpCache{})
+	if err := esa.request(url.Values{"Action": {"ListSites"}}, &ESAResp{}); err == nil || path != "" {
+		t.Errorf("Endpoint 不是https时不应发送请求, 错误 %v", err)
+	}
+}
+
+// TestESAUpdateAllRecords 测试 records=all 时更新所有同名记录, 有一条失败时视为失败
+func TestESAUpdateAllRecords(t *testing.T) {
+	var updated []string
+	failID := ""
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{
+			esaRecord(1, "rr.example.com", "A", "1.1.1.1"),
+			esaRecord(2, "rr.example.com", "A", "2.2.2.2"),
+			esaRecord(3, "rr.example.com", "A", "3.3.3.3"),
+		}
+	}, map[string]esaHandler{
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			if q.Get("RecordId") == failID {
+				esaError(w, http.StatusBadRequest, "InvalidParameter")
+				return
+			}
+			updated = append(updated, q.Get("RecordId"))
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	run := func(domainStr string) *config.Domain {
+		dnsConf := &config.DnsConfig{DNS: config.DNS{ID: "esa-all-test", Secret: "secret", Endpoint: stub.endpoint()}}
+		dnsConf.Ipv4.Domains = []string{domainStr}
+		// 每次都是新的一轮更新
+		config.ResetPushedTargets()
+		esa := &ESA{DNS: dnsConf.DNS, TTL: "600"}
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv6Cache = &util.IpCache{}
+		esa.Domains.GetNewIp(dnsConf)
+		esa.Domains.Ipv4Addr = "3.3.3.3"
+		esa.addUpdateDomainRecords("A")
+		return esa.Domains.Ipv4Domains[0]
+	}
+
+	domain := run("rr.example.com?records=all")
+	if len(updated) != 2 || domain.UpdateStatus != config.UpdatedSuccess {
+		t.Errorf("期待更新2条记录, 得到 %v, 状态 %s", updated, domain.UpdateStatus)
+	}
+
+	updated, failID = nil, "2"
+	domain = run("rr.example.com?records=all")
+	if len(updated) != 1 || domain.UpdateStatus != config.UpdatedFailed {
+		t.Errorf("有一条记录更新失败时应视为失败, 更新 %v, 状态 %s", updated, domain.UpdateStatus)
+	}
+
+	updated, failID = nil, ""
+	domain = run("rr.example.com")
+	if len(updated) != 1 || updated[0] != "1" {
+		t.Errorf("默认只更新第一条记录, 得到 %v", updated)
+	}
+}
+
+// TestNormalizeESATTL 测试 TTL 只能为 1(自动) 或 30~86400
+func TestNormalizeESATTL(t *testing.T) {
+	tests := map[string]string{
+		"":       "",
+		"1":      "1",
+		"10":     "30",
+		"0":      "30",
+		"600":    "600",
+		" 60 ":   "60",
+		"100000": "86400",
+		"auto":   "",
+	}
+	for input, want := range tests {
+		if got := normalizeESATTL(input); got != want {
+			t.Errorf("normalizeESATTL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestESASiteIdCache 测试同一根域名的A/AAAA记录只查询一次站点, 站点不存在时重新查询
+func TestESASiteIdCache(t *testing.T) {
+	siteNotFound := false
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, nil, map[string]esaHandler{
+		"ListRecords": func(w http.ResponseWriter, q url.Values) {
+			if siteNotFound {
+				esaError(w, http.StatusNotFound, "Site.NotFound")
+				return
+			}
+			value := "1.1.1.1"
+			if q.Get("Type") == "AAAA" {
+				value = "2001:db8::1"
+			}
+			json.NewEncoder(w).Encode(ESAListRecordsResp{TotalCount: 1, Records: []ESARecord{esaRecord(1, "www.example.com", q.Get("Type"), value)}})
+		},
+	})
+
+	esa := &ESA{DNS: config.DNS{ID: "esa-site-cache-test", Secret: "secret", Endpoint: stub.endpoint()}}
+	domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	for _, recordType := range []string{"A", "AAAA"} {
+		if _, err := esa.ListRecords(domain, recordType); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if listSites := len(stub.requests("ListSites")); listSites != 1 {
+		t.Errorf("期待 ListSites 调用1次, 得到 %d 次", listSites)
+	}
+
+	siteNotFound = true
+	esa.ListRecords(domain, "A")
+	siteNotFound = false
+	esa.ListRecords(domain, "A")
+	if listSites := len(stub.requests("ListSites")); listSites != 2 {
+		t.Errorf("站点不存在后应重新查询, ListSites 调用了 %d 次", listSites)
+	}
+}
+
+// TestESASameIPNotUpdated 测试服务商返回的IPv6格式不同但地址相同时不更新
+func TestESASameIPNotUpdated(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{esaRecord(1, "www.example.com", "AAAA", "2001:0DB8:0000:0000:0000:0000:0000:0001")}
+	}, map[string]esaHandler{"CreateRecord": esaUnexpected(t), "UpdateRecord": esaUnexpected(t)})
+
+	config.ResetPushedTargets()
+	domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	esa := &ESA{DNS: config.DNS{ID: "esa-same-ip-test", Secret: "secret", Endpoint: stub.endpoint()}}
+	esa.Domains.Ipv6Addr = "2001:db8::1"
+	esa.Domains.Ipv6Cache = &util.IpCache{}
+	esa.Domains.Ipv6Domains = []*config.Domain{domain}
+	esa.addUpdateDomainRecords("AAAA")
+
+	if domain.UpdateStatus != config.UpdatedNothing {
+		t.Errorf("地址相同时不应更新, 状态 %s", domain.UpdateStatus)
+	}
+}
+
+// TestESACachedIdUnchanged 测试通过缓存的记录ID比对时, IP没有变化的域名为未改变
+func TestESACachedIdUnchanged(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{esaRecord(1, "www.example.com", "A", "1.1.1.1")}
+	}, nil)
+
+	// 记录ID缓存保存在配置文件所在的目录
+	t.Setenv(util.ConfigFilePathENV, filepath.Join(t.TempDir(), ".ddns_go_config.yaml"))
+	util.ReloadRecordIdCache()
+	t.Cleanup(util.ReloadRecordIdCache)
+
+	esa := &ESA{DNS: config.DNS{ID: "esa-cached-unchanged-test", Secret: "secret", Endpoint: stub.endpoint()}, TTL: "600", RecordIdCache: true}
+	esa.Domains.Ipv4Addr = "2.2.2.2"
+	for _, expected := range []string{config.UpdatedSuccess, string(config.UpdatedNothing)} {
+		config.ResetPushedTargets()
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+		if string(domain.UpdateStatus) != expected {
+			t.Errorf("期待状态 %s, 得到 %q", expected, domain.UpdateStatus)
+		}
+	}
+	if actions := stub.actions(); strings.Join(actions, ",") != "ListSites,ListRecords,UpdateRecord" {
+		t.Errorf("第二次应使用缓存且不更新, 请求 %v", actions)
+	}
+}
+
+// TestESABizName 测试不同 BizName 的同名记录视为不同的记录
+func TestESABizName(t *testing.T) {
+	var created url.Values
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		web := esaRecord(1, "www.example.com", "A", "1.1.1.1")
+		web.BizName = "web"
+		api := esaRecord(2, "www.example.com", "A", "2.2.2.2")
+		api.BizName = "api"
+		return []ESARecord{web, api}
+	}, map[string]esaHandler{
+		"CreateRecord": func(w http.ResponseWriter, q url.Values) {
+			created = q
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1", RecordId: 3})
+		},
+		"UpdateRecord": esaUnexpected(t),
+		"DeleteRecord": esaUnexpected(t),
+	})
+
+	tests := map[string]struct {
+		bizName string
+		create  bool
+	}{
+		"没有该BizName的记录时新增":  {"image_video", true},
+		"IP和BizName都相同时不更新": {"api", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			created = nil
+			config.ResetPushedTargets()
+			domain := &config.Domain{DomainName: "example.com", SubDomain: "www", CustomParams: "BizName=" + tt.bizName}
+			esa := &ESA{DNS: config.DNS{ID: "esa-bizname-test", Secret: "secret", Endpoint: stub.endpoint()}, TTL: "600"}
+			esa.Domains.Ipv4Addr = "2.2.2.2"
+			esa.Domains.Ipv4Cache = &util.IpCache{}
+			esa.Domains.Ipv4Domains = []*config.Domain{domain}
+			esa.addUpdateDomainRecords("A")
+
+			if (domain.UpdateStatus == config.UpdatedSuccess) != tt.create || domain.UpdateStatus == config.UpdatedFailed {
+				t.Errorf("状态 %q 不正确, 请求 %v", domain.UpdateStatus, stub.actions())
+			}
+			if tt.create && (created == nil || created.Get("BizName") != tt.bizName || created.Get("Data") != `{"Value":"2.2.2.2"}`) {
+				t.Errorf("期待新增 BizName 为 %s 的记录, 实际 %v", tt.bizName, created)
+			}
+			if !tt.create && created != nil {
+				t.Errorf("不应新增记录, 请求 %v", stub.actions())
+			}
+		})
+	}
+}
+
+// TestESADryRun 测试预演时只查询记录, 不调用 CreateRecord/UpdateRecord
+func TestESADryRun(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		if q.Get("Type") == "A" {
+			return []ESARecord{esaRecord(1, "www.example.com", "A", "1.1.1.1")}
+		}
+		return nil
+	}, map[string]esaHandler{"CreateRecord": esaUnexpected(t), "UpdateRecord": esaUnexpected(t)})
+
+	config.ResetPushedTargets()
+	esa := &ESA{DNS: config.DNS{ID: "esa-dry-run-test", Secret: "secret", Endpoint: stub.endpoint()}, TTL: "600", dryRun: true}
+	esa.Domains.Ipv4Cache, esa.Domains.Ipv6Cache = &util.IpCache{}, &util.IpCache{}
+
+	v4 := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	v6 := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	esa.Domains.Ipv4Addr, esa.Domains.Ipv4Domains = "2.2.2.2", []*config.Domain{v4}
+	esa.Domains.Ipv6Addr, esa.Domains.Ipv6Domains = "2001:db8::1", []*config.Domain{v6}
+	esa.AddUpdateDomainRecords()
+
+	if v4.UpdateStatus != config.UpdatedDryRun || v6.UpdateStatus != config.UpdatedDryRun {
+		t.Errorf("期待状态为预演, 实际 %q %q, 请求 %v", v4.UpdateStatus, v6.UpdateStatus, stub.actions())
+	}
+}
+
+// TestESADeleteMissing 测试未获取到IPv6时只删除添加了 missing=delete 且存在的记录
+func TestESADeleteMissing(t *testing.T) {
+	var deleted []string
+	hasRecord := true
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		if !hasRecord {
+			return nil
+		}
+		return []ESARecord{esaRecord(6, q.Get("RecordName"), "AAAA", "2001:db8::1")}
+	}, map[string]esaHandler{
+		"DeleteRecord": func(w http.ResponseWriter, q url.Values) {
+			deleted = append(deleted, q.Get("RecordId"))
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	tests := map[string]struct {
+		domain    string
+		hasRecord bool
+		deleted   int
+	}{
+		"未设置时不删除":    {"www.example.com", true, 0},
+		"设置后删除存在的记录": {"www.example.com?missing=delete", true, 1},
+		"没有记录时不删除":   {"www.example.com?missing=delete", false, 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			deleted, hasRecord = nil, tt.hasRecord
+			dnsConf := &config.DnsConfig{}
+			dnsConf.Ipv6.Domains = []string{tt.domain}
+			esa := &ESA{DNS: config.DNS{ID: "esa-missing-test", Secret: "secret", Endpoint: stub.endpoint()}}
+			esa.Domains.Ipv6Cache = &util.IpCache{TimesFailedIP: 3}
+			esa.Domains.GetNewIp(dnsConf)
+			esa.addUpdateDomainRecords("AAAA")
+
+			if len(deleted) != tt.deleted || (tt.deleted == 1 && deleted[0] != "6") {
+				t.Errorf("期待删除 %d 条记录, 实际删除 %v", tt.deleted, deleted)
+			}
+			if domain := esa.Domains.Ipv6Domains[0]; tt.deleted == 1 && domain.UpdateStatus != config.UpdatedDeleted {
+				t.Errorf("删除记录后的状态应为 %q, 得到 %q", config.UpdatedDeleted, domain.UpdateStatus)
+			}
+		})
+	}
+}
+
+// TestESADomainConcurrency 测试同时更新多个域名时请求并发发送, 每个域名的状态都正确, 站点只查询一次
+func TestESADomainConcurrency(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, nil, nil)
+	stub.delay = 50 * time.Millisecond
+
+	run := func(concurrency int) ([]*config.Domain, int32) {
+		config.ResetPushedTargets()
+		stub.maxInFlight.Store(0)
+		var domains []*config.Domain
+		for i := 0; i < 8; i++ {
+			domains = append(domains, &config.Domain{DomainName: "example.com", SubDomain: "host" + strconv.Itoa(i)})
+		}
+		esa := &ESA{
+			DNS:         config.DNS{ID: "esa-concurrency-test-" + strconv.Itoa(concurrency), Secret: "secret", Endpoint: stub.endpoint()},
+			TTL:         "600",
+			concurrency: concurrency,
+		}
+		esa.Domains.Ipv4Addr = "1.1.1.1"
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = domains
+		esa.addUpdateDomainRecords("A")
+		return domains, stub.maxInFlight.Load()
+	}
+
+	_, sequential := run(1)
+	listSites := len(stub.requests("ListSites"))
+	domains, concurrent := run(4)
+
+	for _, domain := range domains {
+		if domain.UpdateStatus != config.UpdatedSuccess {
+			t.Errorf("域名 %s 的状态为 %q", domain, domain.UpdateStatus)
+		}
+	}
+	if listSites = len(stub.requests("ListSites")) - listSites; listSites != 1 {
+		t.Errorf("期待只查询1次站点, 实际 %d 次", listSites)
+	}
+	if sequential != 1 {
+		t.Errorf("并发数为1时应按顺序请求, 同时处理的请求最多 %d 个", sequential)
+	}
+	if concurrent < 2 || concurrent > 4 {
+		t.Errorf("并发数为4时同时处理的请求应为2到4个, 实际最多 %d 个", concurrent)
+	}
+}
+
+// TestESAAbsent 测试 state=absent 的域名存在记录时删除, 不存在时不处理, 也不会新增记录
+func TestESAAbsent(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		if q.Get("RecordName") == "old.example.com" {
+			return []ESARecord{esaRecord(9, "old.example.com", "A", "1.1.1.1")}
+		}
+		return nil
+	}, map[string]esaHandler{"CreateRecord": esaUnexpected(t), "UpdateRecord": esaUnexpected(t)})
+
+	config.ResetPushedTargets()
+	dnsConf := &config.DnsConfig{}
+	dnsConf.Ipv4.Enable = true
+	dnsConf.Ipv4.ManualIP = "2.2.2.2"
+	dnsConf.Ipv4.Domains = []string{"old.example.com?state=absent", "gone.example.com?state=absent"}
+	esa := &ESA{DNS: config.DNS{ID: "esa-absent-test", Secret: "secret", Endpoint: stub.endpoint()}}
+	esa.Domains.Ipv4Cache, esa.Domains.Ipv6Cache = &util.IpCache{}, &util.IpCache{}
+	esa.Domains.GetNewIp(dnsConf)
+	domains := esa.AddUpdateDomainRecords()
+	deleteAbsentRecords(esa, "esa", &domains)
+
+	if deleted := stub.requests("DeleteRecord"); len(deleted) != 1 || deleted[0].Get("RecordId") != "9" {
+		t.Errorf("期待只删除记录9, 实际删除 %v, 请求 %v", deleted, stub.actions())
+	}
+	if domains.Ipv4Domains[0].UpdateStatus != config.UpdatedDeleted || domains.Ipv4Domains[1].UpdateStatus != "" {
+		t.Errorf("状态不正确: %q %q", domains.Ipv4Domains[0].UpdateStatus, domains.Ipv4Domains[1].UpdateStatus)
+	}
+}
+
+// TestESAOldIP 测试更新成功后保留更新前的IP, 失败时保留失败的原因
+func TestESAOldIP(t *testing.T) {
+	fail := false
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{esaRecord(1, "www.example.com", "A", "1.1.1.1")}
+	}, map[string]esaHandler{
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			if fail {
+				esaError(w, http.StatusForbidden, "InvalidAccessKeyId")
+				return
+			}
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	for _, fail = range []bool{false, true} {
+		config.ResetPushedTargets()
+		domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+		esa := &ESA{DNS: config.DNS{ID: "esa-old-ip-test", Secret: "secret", Endpoint: stub.endpoint()}, TTL: "600"}
+		esa.Domains.Ipv4Addr = "2.2.2.2"
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+
+		if !fail && (domain.UpdateStatus != config.UpdatedSuccess || domain.OldIP != "1.1.1.1" || domain.UpdateError != "") {
+			t.Errorf("期待保留更新前的IP 1.1.1.1, 得到 %+v", domain)
+		}
+		if fail && (domain.UpdateStatus != config.UpdatedFailed || !strings.Contains(domain.UpdateError, "InvalidAccessKeyId")) {
+			t.Errorf("期待保留失败的原因, 得到 %+v", domain)
+		}
+	}
+}
+
+// TestESAIpv6Suffix 测试设置了 ipv6suffix 的域名发布前缀加上后缀的地址, 后缀不正确时视为失败
+func TestESAIpv6Suffix(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, nil, nil)
+
+	config.ResetPushedTargets()
+	dnsConf := &config.DnsConfig{}
+	dnsConf.Ipv6.Enable = true
+	dnsConf.Ipv6.Domains = []string{"router.example.com", "nas.example.com?ipv6suffix=::1a2b:3cff:fe4d:5e6f", "bad.example.com?ipv6suffix=1::1"}
+	esa := &ESA{DNS: config.DNS{ID: "esa-ipv6-suffix-test", Secret: "secret", Endpoint: stub.endpoint()}}
+	esa.Domains.Ipv4Cache, esa.Domains.Ipv6Cache = &util.IpCache{}, &util.IpCache{}
+	esa.Domains.GetNewIp(dnsConf)
+	esa.Domains.Ipv6Addr = "2408:8200:1234:5678::1"
+	esa.addUpdateDomainRecords("AAAA")
+
+	created := map[string]string{}
+	for _, q := range stub.requests("CreateRecord") {
+		created[q.Get("RecordName")] = q.Get("Data")
+	}
+	if created["router.example.com"] != `{"Value":"2408:8200:1234:5678::1"}` || created["nas.example.com"] != `{"Value":"2408:8200:1234:5678:1a2b:3cff:fe4d:5e6f"}` {
+		t.Errorf("发布的地址不正确: %v", created)
+	}
+	bad := esa.Domains.Ipv6Domains[2]
+	if _, ok := created["bad.example.com"]; ok || bad.UpdateStatus != config.UpdatedFailed || bad.UpdateError == "" {
+		t.Errorf("后缀不正确时应视为失败, 状态 %q, 原因 %s", bad.UpdateStatus, bad.UpdateError)
+	}
+}
+
+// TestESAMultipleZones 测试一个DNS配置中的域名属于不同站点, 其中一个站点不存在时不影响其它域名
+func TestESAMultipleZones(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"example.com": 1, "example.net": 2}, nil, nil)
+
+	esa := &ESA{DNS: config.DNS{ID: "esa-multiple-zones-test", Secret: "secret", Endpoint: stub.endpoint()}}
+	for run := 0; run < 2; run++ {
+		config.ResetPushedTargets()
+		dnsConf := &config.DnsConfig{}
+		dnsConf.Ipv4.Enable = true
+		dnsConf.Ipv4.Domains = []string{"a.example.com", "b.other.org", "c.example.net", "d.example.com"}
+		esa.Domains = config.Domains{Ipv4Cache: &util.IpCache{}, Ipv6Cache: &util.IpCache{}}
+		esa.Domains.GetNewIp(dnsConf)
+		esa.Domains.Ipv4Addr = "2.2.2.2"
+		esa.addUpdateDomainRecords("A")
+
+		for _, domain := range esa.Domains.Ipv4Domains {
+			if domain.DomainName == "other.org" {
+				if domain.UpdateStatus != config.UpdatedFailed || !strings.Contains(domain.UpdateError, "other.org") {
+					t.Errorf("站点不存在时应失败, 状态 %q, 原因 %s", domain.UpdateStatus, domain.UpdateError)
+				}
+				continue
+			}
+			if domain.UpdateStatus != config.UpdatedSuccess {
+				t.Errorf("%s 不应受其它站点影响, 状态 %q, 原因 %s", domain, domain.UpdateStatus, domain.UpdateError)
+			}
+		}
+	}
+
+	created := map[string]string{}
+	for _, q := range stub.requests("CreateRecord") {
+		created[q.Get("RecordName")] = q.Get("SiteId")
+	}
+	if created["a.example.com"] != "1" || created["d.example.com"] != "1" || created["c.example.net"] != "2" || len(created) != 3 {
+		t.Errorf("记录应新增到各自的站点: %v", created)
+	}
+	// 存在的站点只查询一次, 不存在的站点每次都重新查询
+	var listSites []string
+	for _, q := range stub.requests("ListSites") {
+		listSites = append(listSites, q.Get("SiteName"))
+	}
+	if strings.Join(listSites, ",") != "example.com,other.org,example.net,other.org" {
+		t.Errorf("ListSites 请求不正确: %v", listSites)
+	}
+}
+
+// TestESAVerifyRecord 测试开启 VerifyRecord 时, 更新成功但查询到的仍是旧的IP时视为失败
+func TestESAVerifyRecord(t *testing.T) {
+	value, apply := "1.1.1.1", false
+	lists := 0
+	stub := newESAStub(t, map[string]int64{"example.com": 1}, func(q url.Values) []ESARecord {
+		lists++
+		return []ESARecord{esaRecord(1, "verify.example.com", "A", value)}
+	}, map[string]esaHandler{
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			if apply {
+				var data struct{ Value string }
+				json.Unmarshal([]byte(q.Get("Data")), &data)
+				value = data.Value
+			}
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	tests := []struct {
+		name   string
+		verify bool
+		apply  bool
+		lists  int
+		want   string
+	}{
+		{"不确认", false, false, 1, config.UpdatedSuccess},
+		{"确认已更新", true, true, 2, config.UpdatedSuccess},
+		{"确认仍为旧的IP", true, false, 2, config.UpdatedFailed},
+	}
+	for _, tt := range tests {
+		value, apply, lists = "1.1.1.1", tt.apply, 0
+		config.ResetPushedTargets()
+		domain := &config.Domain{DomainName: "example.com", SubDomain: "verify"}
+		esa := &ESA{
+			DNS:    config.DNS{ID: "esa-verify-test", Secret: "secret", Endpoint: stub.endpoint()},
+			TTL:    "600",
+			verify: tt.verify,
+		}
+		esa.Domains.Ipv4Addr = "2.2.2.2"
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+
+		if string(domain.UpdateStatus) != tt.want || lists != tt.lists {
+			t.Errorf("%s: 期待状态 %s, 查询 %d 次, 得到 %s, %d 次", tt.name, tt.want, tt.lists, domain.UpdateStatus, lists)
+		}
+		if tt.want == config.UpdatedFailed && !strings.Contains(domain.UpdateError, "1.1.1.1") {
+			t.Errorf("%s: 失败原因应包含查询到的IP, 得到 %q", tt.name, domain.UpdateError)
+		}
+	}
+}
+
+// TestESAMinTTL 测试ESA拒绝TTL时使用返回的最小TTL重试, 之后直接使用该TTL
+func TestESAMinTTL(t *testing.T) {
+	stub := newESAStub(t, map[string]int64{"minttl.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{esaRecord(1, "www.minttl.com", "A", "1.1.1.1")}
+	}, map[string]esaHandler{
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			if q.Get("TTL") != "600" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"RequestId":"A198-72F8","Code":"InvalidParameter.TTL","Message":"TTL must be at least 600"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	for _, ip := range []string{"2.2.2.2", "3.3.3.3"} {
+		config.ResetPushedTargets()
+		domain := &config.Domain{DomainName: "minttl.com", SubDomain: "www"}
+		esa := &ESA{
+			DNS: config.DNS{ID: "esa-minttl-test", Secret: "secret", Endpoint: stub.endpoint()},
+			TTL: "60",
+		}
+		esa.Domains.Ipv4Addr = ip
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+		if domain.UpdateStatus != config.UpdatedSuccess {
+			t.Errorf("IP %s: 期待更新成功, 得到 %s %s", ip, domain.UpdateStatus, domain.UpdateError)
+		}
+	}
+	var ttls []string
+	for _, q := range stub.requests("UpdateRecord") {
+		ttls = append(ttls, q.Get("TTL"))
+	}
+	if want := []string{"60", "600", "600"}; strings.Join(ttls, ",") != strings.Join(want, ",") {
+		t.Errorf("期待请求的TTL为 %v, 得到 %v", want, ttls)
+	}
+}
+
+// TestESAMetrics 测试新增/更新/未改变/失败的次数和最后一次更新成功的时间
+func TestESAMetrics(t *testing.T) {
+	var records []ESARecord
+	fail := false
+	save := func(w http.ResponseWriter, q url.Values) {
+		if fail {
+			esaError(w, http.StatusBadRequest, "InvalidParameter")
+			return
+		}
+		record := ESARecord{RecordId: 1, RecordName: "www.metrics.com", Type: "A"}
+		json.Unmarshal([]byte(q.Get("Data")), &record.Data)
+		records = []ESARecord{record}
+		json.NewEncoder(w).Encode(ESAResp{RequestId: "1", RecordId: 1})
+	}
+	stub := newESAStub(t, map[string]int64{"metrics.com": 1}, func(q url.Values) []ESARecord {
+		return records
+	}, map[string]esaHandler{"CreateRecord": save, "UpdateRecord": save})
+
+	run := func(ip string) {
+		config.ResetPushedTargets()
+		esa := &ESA{
+			DNS: config.DNS{ID: "esa-metrics-test", Secret: "secret", Endpoint: stub.endpoint()},
+			TTL: "600",
+		}
+		esa.Domains.Ipv4Addr = ip
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{{DomainName: "metrics.com", SubDomain: "www"}}
+		esa.addUpdateDomainRecords("A")
+	}
+	run("1.1.1.1")
+	run("2.2.2.2")
+	run("2.2.2.2")
+	finishRunMetrics()
+	fail = true
+	run("3.3.3.3")
+	finishRunMetrics()
+
+	key := MetricKey{Provider: "esa", Domain: "www.metrics.com", RecordType: "A"}
+	m := GetMetrics()
+	for action, want := range map[string]uint64{MetricCreate: 1, MetricUpdate: 1, MetricUnchanged: 1, MetricFailed: 1} {
+		key.Action = action
+		if m.Total[key] != want {
+			t.Errorf("%s 期待 %d 次, 得到 %d 次", action, want, m.Total[key])
+		}
+		if lastRun := m.LastRun[key]; (action == MetricFailed) != (lastRun == 1) {
+			t.Errorf("上次运行只有一次失败, %s 得到 %d 次", action, lastRun)
+		}
+	}
+	key.Action = ""
+	if since := time.Since(m.LastSuccess[key]); since < 0 || since > time.Minute {
+		t.Errorf("最后一次更新成功的时间不正确: %s", m.LastSuccess[key])
+	}
+}
+
+// TestESAFailurePermanent 测试签名错误时记录为需要修改配置, 服务端错误时为下次运行重试
+func TestESAFailurePermanent(t *testing.T) {
+	status, body := 0, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   bool
+	}{
+		{"签名错误", http.StatusBadRequest, `{"Code":"SignatureDoesNotMatch"}`, true},
+		{"AccessKey不存在", http.StatusNotFound, `{"Code":"InvalidAccessKeyId.NotFound"}`, true},
+		{"服务端错误", http.StatusInternalServerError, `{"Code":"InternalError"}`, false},
+		{"被限流", http.StatusBadRequest, `{"Code":"Throttling.User"}`, false},
+	}
+	for i, tt := range tests {
+		status, body = tt.status, tt.body
+		config.ResetPushedTargets()
+		domain := &config.Domain{DomainName: "permanent.com", SubDomain: "www"}
+		esa := &ESA{
+			DNS:   config.DNS{ID: "esa-permanent-test-" + strconv.Itoa(i), Secret: "secret", Endpoint: server.URL + "/"},
+			TTL:   "600",
+			retry: aliyunRetry{times: 1},
+		}
+		esa.Domains.Ipv4Addr = "1.1.1.1"
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+
+		if domain.UpdateStatus != config.UpdatedFailed || domain.FailurePermanent != tt.want {
+			t.Errorf("%s: 期待失败且 FailurePermanent 为 %v, 得到 %s %v", tt.name, tt.want, domain.UpdateStatus, domain.FailurePermanent)
+		}
+	}
+}
+
+// TestIsESANotFound 测试只有站点/记录不存在时才重新查询, AccessKey不存在等需要修改配置
+func TestIsESANotFound(t *testing.T) {
+	tests := map[string]bool{
+		`{"Code":"Site.NotFound"}`:               true,
+		`{"Code":"InvalidSiteId.NotFound"}`:      true,
+		`{"Code":"Record.NotExist"}`:             true,
+		`{"Code":"InvalidRecordId.NotExist"}`:    true,
+		`{"Code":"Record.SiteIdMismatch"}`:       true,
+		`{"Code":"InvalidAccessKeyId.NotFound"}`: false,
+		`{"Code":"InvalidParameter"}`:            false,
+		"NotFound":                               false,
+	}
+	for msg, want := range tests {
+		if got := isESANotFound(errors.New(msg)); got != want {
+			t.Errorf("isESANotFound(%s) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+// TestESACustomData 测试 Data.* 参数合并到记录的Data中, 不覆盖Value, 也不作为参数发送
+func TestESACustomData(t *testing.T) {
+	params, _ := url.ParseQuery("Data.Priority=10&Data.Proxied=true&Data.Tag=issue&Data.Value=9.9.9.9&BizName=web")
+	extra := esaCustomData(params)
+	if len(params) != 1 || params.Get("BizName") != "web" {
+		t.Errorf("Data.* 参数不应再发送, 得到 %v", params)
+	}
+
+	b, err := json.Marshal(ESARecordData{Value: "1.1.1.1", Extra: extra})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data map[string]interface{}
+	json.Unmarshal(b, &data)
+	want := map[string]interface{}{"Value": "1.1.1.1", "Priority": float64(10), "Proxied": true, "Tag": "issue"}
+	if len(data) != len(want) {
+		t.Errorf("期待 %v, 得到 %s", want, b)
+	}
+	for k, v := range want {
+		if data[k] != v {
+			t.Errorf("%s 期待 %v, 得到 %v", k, v, data[k])
+		}
+	}
+}
+
+// TestESACustomDataChanged 测试IP相同但 Data.* 参数与记录不同时更新记录
+func TestESACustomDataChanged(t *testing.T) {
+	record := ESARecord{RecordId: 1, RecordName: "www.data.com", Type: "A"}
+	json.Unmarshal([]byte(`{"Value":"1.1.1.1","Priority":5,"Other":"x"}`), &record.Data)
+	stub := newESAStub(t, map[string]int64{"data.com": 1}, func(q url.Values) []ESARecord {
+		return []ESARecord{record}
+	}, map[string]esaHandler{
+		"UpdateRecord": func(w http.ResponseWriter, q url.Values) {
+			json.Unmarshal([]byte(q.Get("Data")), &record.Data)
+			json.NewEncoder(w).Encode(ESAResp{RequestId: "1"})
+		},
+	})
+
+	for _, want := range []string{string(config.UpdatedSuccess), string(config.UpdatedNothing)} {
+		config.ResetPushedTargets()
+		domain := &config.Domain{DomainName: "data.com", SubDomain: "www", CustomParams: "Data.Priority=10"}
+		esa := &ESA{
+			DNS: config.DNS{ID: "esa-data-test", Secret: "secret", Endpoint: stub.endpoint()},
+			TTL: "600",
+		}
+		esa.Domains.Ipv4Addr = "1.1.1.1"
+		esa.Domains.Ipv4Cache = &util.IpCache{}
+		esa.Domains.Ipv4Domains = []*config.Domain{domain}
+		esa.addUpdateDomainRecords("A")
+		if string(domain.UpdateStatus) != want {
+			t.Errorf("期待 %s, 得到 %s", want, domain.UpdateStatus)
+		}
+	}
+	var updated []string
+	for _, q := range stub.requests("UpdateRecord") {
+		updated = append(updated, q.Get("Data"))
+	}
+	if len(updated) != 1 || updated[0] != `{"Priority":10,"Value":"1.1.1.1"}` {
+		t.Errorf("期待只更新一次Priority, 得到 %v", updated)
+	}
+}
+
+// TestESAProviderTTL 测试未设置TTL时默认使用30, 开启 ProviderTTL 后使用ESA站点的默认TTL
+func TestESAProviderTTL(t *testing.T) {
+	domain := &config.Domain{DomainName: "provider-ttl.com", SubDomain: "www"}
+	tests := []struct {
+		ttl         string
+		providerTTL bool
+		want        string
+	}{
+		{"", false, "30"},
+		{"", true, "1"},
+		{"600", true, "600"},
+	}
+	for _, tt := range tests {
+		esa := &ESA{TTL: tt.ttl, providerTTL: tt.providerTTL}
+		if got := esa.getTTL(domain); got != tt.want {
+			t.Errorf("TTL %q ProviderTTL %t 期待 %s, 得到 %s", tt.ttl, tt.providerTTL, tt.want, got)
+		}
+	}
+}
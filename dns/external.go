@@ -0,0 +1,149 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// externalTimeout 外部程序的超时时间
+const externalTimeout = 60 * time.Second
+
+// External 调用外部程序更新记录, 用于 ddns-go 不支持的DNS服务商
+// DNS.ID 为执行的命令, DNS.Secret 通过环境变量 DDNS_SECRET 传递
+// 每条记录执行一次, 标准输入为 externalRequest 的 JSON, 标准输出可返回 externalResponse 的 JSON
+// 退出码为 0 时为成功, 未返回 status 时视为已更新; 退出码不为 0 时为失败
+type External struct {
+	DNS      config.DNS
+	Domains  config.Domains
+	TTL      string
+	lastIpv4 string
+	lastIpv6 string
+}
+
+// externalRequest 传递给外部程序的记录
+type externalRequest struct {
+	// Domain 完整域名, 如 www.example.com
+	Domain     string            `json:"domain"`
+	DomainName string            `json:"domainName"`
+	SubDomain  string            `json:"subDomain"`
+	Type       string            `json:"type"`
+	OldIP      string            `json:"oldIp"`
+	NewIP      string            `json:"newIp"`
+	TTL        string            `json:"ttl"`
+	Params     map[string]string `json:"params"`
+}
+
+// externalResponse 外部程序返回的结果
+type externalResponse struct {
+	// Status updated/unchanged/failed
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Init 初始化
+func (ext *External) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6cache *util.IpCache) {
+	ext.Domains.Ipv4Cache = ipv4cache
+	ext.Domains.Ipv6Cache = ipv6cache
+	ext.lastIpv4 = ipv4cache.Addr
+	ext.lastIpv6 = ipv6cache.Addr
+
+	ext.DNS = dnsConf.DNS
+	ext.Domains.GetNewIp(dnsConf)
+	ext.TTL = dnsConf.TTL
+}
+
+// AddUpdateDomainRecords 添加或更新IPv4/IPv6记录
+func (ext *External) AddUpdateDomainRecords() config.Domains {
+	ext.addUpdateDomainRecords("A")
+	ext.addUpdateDomainRecords("AAAA")
+	return ext.Domains
+}
+
+func (ext *External) addUpdateDomainRecords(recordType string) {
+	ipAddr, domains := ext.Domains.GetNewIpResult(recordType)
+
+	if ipAddr == "" {
+		return
+	}
+
+	oldIP := ext.lastIpv4
+	if recordType == "AAAA" {
+		oldIP = ext.lastIpv6
+	}
+
+	for _, domain := range domains {
+		params := map[string]string{}
+		query := domain.GetCustomParams()
+		for k := range query {
+			params[k] = query.Get(k)
+		}
+		resp, err := ext.run(externalRequest{
+			Domain:     domain.String(),
+			DomainName: domain.DomainName,
+			SubDomain:  domain.SubDomain,
+			Type:       recordType,
+			OldIP:      oldIP,
+			NewIP:      ipAddr,
+			TTL:        ext.TTL,
+			Params:     params,
+		})
+		if err != nil {
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+
+		switch resp.Status {
+		case "", "updated":
+			util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
+			domain.UpdateStatus = config.UpdatedSuccess
+		case "unchanged":
+			util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+			domain.UpdateStatus = config.UpdatedNothing
+		default:
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, resp.Message)
+			domain.UpdateStatus = config.UpdatedFailed
+		}
+	}
+}
+
+// run 执行外部程序
+func (ext *External) run(req externalRequest) (resp externalResponse, err error) {
+	if strings.TrimSpace(ext.DNS.ID) == "" {
+		return resp, errors.New("the command is empty")
+	}
+	input, _ := json.Marshal(req)
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+	defer cancel()
+	cmd := util.ShellCommandContext(ctx, ext.DNS.ID)
+	cmd.Env = append(os.Environ(), "DDNS_SECRET="+ext.DNS.Secret)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	// 标准输出不是 JSON 时忽略
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) > 0 && json.Unmarshal(out, &resp) != nil {
+		resp = externalResponse{}
+	}
+	if runErr != nil {
+		msg := resp.Message
+		if msg == "" {
+			msg = strings.TrimSpace(stderr.String() + " " + string(out))
+		}
+		return resp, fmt.Errorf("%s: %s", runErr, msg)
+	}
+	return resp, nil
+}
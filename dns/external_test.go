@@ -0,0 +1,59 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// TestExternalRun 测试外部程序的输入输出与退出码
+func TestExternalRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+
+	tests := []struct {
+		name    string
+		script  string
+		status  string
+		wantErr bool
+	}{
+		{"默认为已更新", "cat > " + input, "", false},
+		{"返回状态", `cat > /dev/null; echo '{"status":"unchanged"}'`, "unchanged", false},
+		{"非JSON输出", "cat > /dev/null; echo ok", "", false},
+		{"退出码不为0", "cat > /dev/null; echo 'invalid token' >&2; exit 2", "", true},
+		{"返回失败原因", `cat > /dev/null; echo '{"status":"failed","message":"quota"}'`, "failed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := &External{DNS: config.DNS{Name: "external", ID: tt.script, Secret: "s3cret"}}
+			resp, err := ext.run(externalRequest{Domain: "www.example.com", Type: "A", OldIP: "1.2.3.4", NewIP: "5.6.7.8"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "invalid token") {
+				t.Errorf("错误信息应包含标准错误: %s", err)
+			}
+			if resp.Status != tt.status {
+				t.Errorf("status = %q, want %q", resp.Status, tt.status)
+			}
+		})
+	}
+
+	byt, _ := os.ReadFile(input)
+	if !strings.Contains(string(byt), `"oldIp":"1.2.3.4","newIp":"5.6.7.8"`) {
+		t.Errorf("标准输入错误: %s", byt)
+	}
+
+	// Secret 通过环境变量传递
+	ext := &External{DNS: config.DNS{ID: `cat > /dev/null; echo "{\"message\":\"$DDNS_SECRET\"}"`, Secret: "s3cret"}}
+	if resp, _ := ext.run(externalRequest{}); resp.Message != "s3cret" {
+		t.Errorf("未传递 DDNS_SECRET: %q", resp.Message)
+	}
+}
@@ -0,0 +1,54 @@
+package dns
+
+import (
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// failoverPrimary 备用DNS配置的主配置的序号, 主配置不存在或本身也是备用配置时返回 -1
+func failoverPrimary(dnsConf []config.DnsConfig, dc *config.DnsConfig) int {
+	for i, primary := range dnsConf {
+		if primary.Name == dc.Failover && primary.Failover == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// failoverSecondaries 设置了 Failover 的DNS配置, 在主配置之后运行
+// 找不到主配置时按普通的DNS配置运行, 避免配置错误时不再更新
+func failoverSecondaries(dnsConf []config.DnsConfig) []bool {
+	secondary := make([]bool, len(dnsConf))
+	for i := range dnsConf {
+		dc := &dnsConf[i]
+		if dc.Failover == "" {
+			continue
+		}
+		if failoverPrimary(dnsConf, dc) < 0 {
+			util.Log("备用DNS配置 %s 的主DNS配置 %s 不存在, 将每次都更新", dc.Name, dc.Failover)
+			continue
+		}
+		secondary[i] = true
+	}
+	return secondary
+}
+
+// useFailover 只保留主配置本轮更新失败的IPv4/IPv6, 都未失败时返回 false 跳过备用配置
+func useFailover(dc *config.DnsConfig, failed [2]bool) bool {
+	dc.Ipv4.Enable = dc.Ipv4.Enable && failed[0]
+	dc.Ipv6.Enable = dc.Ipv6.Enable && failed[1]
+	if !dc.Ipv4.Enable && !dc.Ipv6.Enable {
+		return false
+	}
+	util.Log("主DNS配置 %s 更新失败, 将使用备用DNS配置 %s 更新", dc.Failover, dc.Name)
+	return true
+}
+
+// logFailoverResult 记录最终由哪个DNS配置更新
+func logFailoverResult(dc *config.DnsConfig, failed [2]bool) {
+	if (dc.Ipv4.Enable && failed[0]) || (dc.Ipv6.Enable && failed[1]) {
+		util.Log("备用DNS配置 %s 也更新失败", dc.Name)
+		return
+	}
+	util.Log("已由备用DNS配置 %s (%s) 更新", dc.Name, dc.DNS.Name)
+}
@@ -0,0 +1,51 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// TestFailoverSecondaries 测试找到主配置时才作为备用配置, 备用配置不能作为主配置
+func TestFailoverSecondaries(t *testing.T) {
+	dnsConf := []config.DnsConfig{
+		{Name: "primary"},
+		{Name: "backup", Failover: "primary"},
+		{Name: "backup2", Failover: "backup"},
+		{Name: "typo", Failover: "primay"},
+	}
+	want := []bool{false, true, false, false}
+	got := failoverSecondaries(dnsConf)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 个配置期待 %v, 得到 %v", i, want[i], got[i])
+		}
+	}
+	if i := failoverPrimary(dnsConf, &dnsConf[1]); i != 0 {
+		t.Errorf("期待主配置为第0个, 得到 %d", i)
+	}
+}
+
+// TestUseFailover 测试只更新主配置失败的IPv4/IPv6
+func TestUseFailover(t *testing.T) {
+	tests := []struct {
+		name   string
+		v4, v6 bool
+		failed [2]bool
+		want   bool
+		wantV4 bool
+		wantV6 bool
+	}{
+		{"主配置成功", true, true, [2]bool{false, false}, false, false, false},
+		{"IPv4失败", true, true, [2]bool{true, false}, true, true, false},
+		{"都失败", true, true, [2]bool{true, true}, true, true, true},
+		{"备用配置未启用IPv6", true, false, [2]bool{false, true}, false, false, false},
+	}
+	for _, tt := range tests {
+		dc := config.DnsConfig{Name: "backup", Failover: "primary"}
+		dc.Ipv4.Enable, dc.Ipv6.Enable = tt.v4, tt.v6
+		if got := useFailover(&dc, tt.failed); got != tt.want || dc.Ipv4.Enable != tt.wantV4 || dc.Ipv6.Enable != tt.wantV6 {
+			t.Errorf("%s: 得到 %v, IPv4 %v, IPv6 %v", tt.name, got, dc.Ipv4.Enable, dc.Ipv6.Enable)
+		}
+	}
+}
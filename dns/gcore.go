@@ -233,8 +233,9 @@ func (gc *Gcore) createRecord(zoneName string, domain *config.Domain, recordType
 func (gc *Gcore) updateRecord(zoneName string, domain *config.Domain, recordType string, ipAddr string, existingRecord *GcoreRRSet) {
 	// 检查IP是否相同
 	if len(existingRecord.ResourceRecords) > 0 && len(existingRecord.ResourceRecords[0].Content) > 0 {
-		if existingRecord.ResourceRecords[0].Content[0] == ipAddr {
+		if content, _ := existingRecord.ResourceRecords[0].Content[0].(string); util.SameIP(content, ipAddr) {
 			util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+			domain.UpdateStatus = config.UpdatedNothing
 			return
 		}
 	}
@@ -293,7 +294,7 @@ func (gc *Gcore) request(method string, url string, data interface{}, result int
 	req.Header.Set("Authorization", "APIKey "+gc.DNS.Secret)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := gc.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
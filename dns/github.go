@@ -0,0 +1,262 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+const githubAPIEndpoint = "https://api.github.com"
+
+// GitHub 将IP写入 Gist 或仓库中的文件, 供其它脚本读取
+// DNS.ID 为 gist:<Gist ID>/<文件名> 或 <owner>/<repo>/<文件路径>, DNS.ExtParam 为仓库的分支
+// 文件内容为 JSON, 如 {"home.example.com":{"A":"1.2.3.4","AAAA":"2001:db8::1"}}
+type GitHub struct {
+	DNS     config.DNS
+	Domains config.Domains
+	// 本轮需要写入的记录
+	records map[string]map[string]string
+	changed []githubChange
+}
+
+type githubChange struct {
+	domain *config.Domain
+	ipAddr string
+}
+
+// githubGist https://docs.github.com/en/rest/gists/gists
+type githubGist struct {
+	Files map[string]struct {
+		Content   string `json:"content"`
+		Truncated bool   `json:"truncated"`
+	} `json:"files"`
+}
+
+// githubContent https://docs.github.com/en/rest/repos/contents
+type githubContent struct {
+	Content string `json:"content"`
+	Sha     string `json:"sha"`
+}
+
+// Init 初始化
+func (gh *GitHub) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6cache *util.IpCache) {
+	gh.Domains.Ipv4Cache = ipv4cache
+	gh.Domains.Ipv6Cache = ipv6cache
+	gh.DNS = dnsConf.DNS
+	gh.Domains.GetNewIp(dnsConf)
+}
+
+// AddUpdateDomainRecords 添加或更新IPv4/IPv6记录
+func (gh *GitHub) AddUpdateDomainRecords() config.Domains {
+	gh.records = map[string]map[string]string{}
+	gh.changed = nil
+	gh.addUpdateDomainRecords("A")
+	gh.addUpdateDomainRecords("AAAA")
+	if len(gh.changed) > 0 {
+		gh.write()
+	}
+	return gh.Domains
+}
+
+func (gh *GitHub) addUpdateDomainRecords(recordType string) {
+	ipAddr, domains := gh.Domains.GetNewIpResult(recordType)
+
+	if ipAddr == "" {
+		return
+	}
+
+	for _, domain := range domains {
+		name := domain.String()
+		if gh.records[name] == nil {
+			gh.records[name] = map[string]string{}
+		}
+		gh.records[name][recordType] = ipAddr
+		gh.changed = append(gh.changed, githubChange{domain: domain, ipAddr: ipAddr})
+	}
+}
+
+// write 读取文件, 合并本轮的记录后写回, 文件中的其它内容保持不变
+func (gh *GitHub) write() {
+	var err error
+	if gistPath, ok := strings.CutPrefix(gh.DNS.ID, "gist:"); ok {
+		err = gh.writeGist(gistPath)
+	} else {
+		err = gh.writeRepoFile(gh.DNS.ID)
+	}
+	if err != nil {
+		for _, c := range gh.changed {
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", c.domain, err)
+			c.domain.UpdateStatus = config.UpdatedFailed
+		}
+	}
+}
+
+// writeGist 更新 Gist 中的文件, gistPath 为 <Gist ID>/<文件名>
+func (gh *GitHub) writeGist(gistPath string) error {
+	gistID, fileName, ok := strings.Cut(gistPath, "/")
+	if !ok || gistID == "" || fileName == "" {
+		return fmt.Errorf("invalid Gist %q, expected gist:<Gist ID>/<file name>", gh.DNS.ID)
+	}
+
+	var gist githubGist
+	err := gh.request(http.MethodGet, githubAPIEndpoint+"/gists/"+gistID, nil, &gist)
+	if err != nil {
+		return err
+	}
+	if gist.Files[fileName].Truncated {
+		return fmt.Errorf("the content of %s is truncated, file is too large", fileName)
+	}
+	content, changed := gh.mergeContent(gist.Files[fileName].Content)
+	if !changed {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"files": map[string]interface{}{
+			fileName: map[string]string{"content": content},
+		},
+	}
+	err = gh.request(http.MethodPatch, githubAPIEndpoint+"/gists/"+gistID, body, nil)
+	if err == nil {
+		gh.logSuccess()
+	}
+	return err
+}
+
+// writeRepoFile 更新仓库中的文件, 不存在时创建, repoPath 为 <owner>/<repo>/<文件路径>
+func (gh *GitHub) writeRepoFile(repoPath string) error {
+	parts := strings.SplitN(repoPath, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid repository file %q, expected <owner>/<repo>/<path>", repoPath)
+	}
+	fileURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", githubAPIEndpoint, parts[0], parts[1], parts[2])
+
+	getURL := fileURL
+	if gh.DNS.ExtParam != "" {
+		getURL += "?ref=" + url.QueryEscape(gh.DNS.ExtParam)
+	}
+	var file githubContent
+	exists, err := gh.getRepoFile(getURL, &file)
+	if err != nil {
+		return err
+	}
+
+	old := ""
+	if exists {
+		byt, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+		if err != nil {
+			return err
+		}
+		old = string(byt)
+	}
+	content, changed := gh.mergeContent(old)
+	if !changed {
+		return nil
+	}
+
+	body := map[string]string{
+		"message": "Update IP by ddns-go",
+		"content": base64.StdEncoding.EncodeToString([]byte(content)),
+	}
+	if exists {
+		body["sha"] = file.Sha
+	}
+	if gh.DNS.ExtParam != "" {
+		body["branch"] = gh.DNS.ExtParam
+	}
+	err = gh.request(http.MethodPut, fileURL, body, nil)
+	if err == nil {
+		gh.logSuccess()
+	}
+	return err
+}
+
+// getRepoFile 获取仓库中的文件, 不存在时返回 false
+func (gh *GitHub) getRepoFile(fileURL string, result *githubContent) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+	if err != nil {
+		return false, err
+	}
+	gh.setHeaders(req)
+
+	client := gh.Domains.HTTPClient()
+	resp, err := client.Do(req)
+	if err == nil && resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return false, nil
+	}
+	return true, util.GetHTTPResponse(resp, err, result)
+}
+
+// mergeContent 将本轮的记录合并到原有的 JSON 内容中, 返回新内容以及是否有变化
+func (gh *GitHub) mergeContent(old string) (string, bool) {
+	records := map[string]map[string]string{}
+	if strings.TrimSpace(old) != "" && json.Unmarshal([]byte(old), &records) != nil {
+		util.Log("文件原有内容不是有效的JSON, 将被覆盖")
+		records = map[string]map[string]string{}
+	}
+
+	changed := false
+	for name, types := range gh.records {
+		if records[name] == nil {
+			records[name] = map[string]string{}
+		}
+		for recordType, ipAddr := range types {
+			if !util.SameIP(records[name][recordType], ipAddr) {
+				records[name][recordType] = ipAddr
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		for _, c := range gh.changed {
+			util.Log("你的IP %s 没有变化, 域名 %s", c.ipAddr, c.domain)
+			c.domain.UpdateStatus = config.UpdatedNothing
+		}
+		return old, false
+	}
+
+	byt, _ := json.MarshalIndent(records, "", "  ")
+	return string(byt) + "\n", true
+}
+
+func (gh *GitHub) logSuccess() {
+	for _, c := range gh.changed {
+		util.Log("更新域名解析 %s 成功! IP: %s", c.domain, c.ipAddr)
+		c.domain.UpdateStatus = config.UpdatedSuccess
+	}
+}
+
+func (gh *GitHub) setHeaders(req *http.Request) {
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+gh.DNS.Secret)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
+
+// request 统一请求接口
+func (gh *GitHub) request(method string, url string, data interface{}, result interface{}) (err error) {
+	body := bytes.NewBuffer(nil)
+	if data != nil {
+		jsonStr, _ := json.Marshal(data)
+		body = bytes.NewBuffer(jsonStr)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return
+	}
+	gh.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := gh.Domains.HTTPClient()
+	resp, err := client.Do(req)
+	err = util.GetHTTPResponse(resp, err, result)
+
+	return
+}
@@ -0,0 +1,32 @@
+package dns
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestGitHubMergeContent 测试合并记录时保留文件中的其它内容
+func TestGitHubMergeContent(t *testing.T) {
+	gh := &GitHub{records: map[string]map[string]string{
+		"home.example.com": {"A": "1.2.3.4"},
+	}}
+
+	content, changed := gh.mergeContent(`{"home.example.com":{"A":"1.1.1.1","AAAA":"2001:db8::1"},"nas.example.com":{"A":"5.6.7.8"}}`)
+	if !changed {
+		t.Fatal("IP改变时应返回 changed")
+	}
+	var records map[string]map[string]string
+	if err := json.Unmarshal([]byte(content), &records); err != nil {
+		t.Fatal(err)
+	}
+	if records["home.example.com"]["A"] != "1.2.3.4" || records["home.example.com"]["AAAA"] != "2001:db8::1" || records["nas.example.com"]["A"] != "5.6.7.8" {
+		t.Errorf("合并结果错误: %s", content)
+	}
+
+	if _, changed = gh.mergeContent(content); changed {
+		t.Error("IP未改变时不应返回 changed")
+	}
+	if content, changed = gh.mergeContent("not json"); !changed || content == "" {
+		t.Error("无效的JSON应被覆盖")
+	}
+}
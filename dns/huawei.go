@@ -206,7 +206,7 @@ func (hw *Huaweicloud) create(domain *config.Domain, recordType string, ipAddr s
 		return
 	}
 
-	if len(result.Records) > 0 && result.Records[0] == ipAddr {
+	if len(result.Records) > 0 && util.SameIP(result.Records[0], ipAddr) {
 		util.Log("新增域名解析 %s 成功! IP: %s", domain, ipAddr)
 		domain.UpdateStatus = config.UpdatedSuccess
 	} else {
@@ -219,8 +219,9 @@ func (hw *Huaweicloud) create(domain *config.Domain, recordType string, ipAddr s
 func (hw *Huaweicloud) modify(record HuaweicloudRecordsets, domain *config.Domain, ipAddr string) {
 
 	// 相同不修改
-	if len(record.Records) > 0 && record.Records[0] == ipAddr {
+	if len(record.Records) > 0 && util.SameIP(record.Records[0], ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 
@@ -245,7 +246,7 @@ func (hw *Huaweicloud) modify(record HuaweicloudRecordsets, domain *config.Domai
 		return
 	}
 
-	if len(result.Records) > 0 && result.Records[0] == ipAddr {
+	if len(result.Records) > 0 && util.SameIP(result.Records[0], ipAddr) {
 		util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
 		domain.UpdateStatus = config.UpdatedSuccess
 	} else {
@@ -305,7 +306,7 @@ func (hw *Huaweicloud) request(method string, urlString string, data interface{}
 
 	req.Header.Add("content-type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := hw.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
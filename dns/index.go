@@ -1,6 +1,9 @@
 package dns
 
 import (
+	"context"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/jeessy2/ddns-go/v6/config"
@@ -14,6 +17,11 @@ type DNS interface {
 	AddUpdateDomainRecords() (domains config.Domains)
 }
 
+// recordDeleter 支持删除记录的DNS服务商, 见 config.PartialDelete
+type recordDeleter interface {
+	deleteDomainRecords(recordType string, domains []*config.Domain)
+}
+
 var (
 	Addresses = []string{
 		alidnsEndpoint,
@@ -32,95 +40,309 @@ var (
 	}
 
 	Ipcache = [][2]util.IpCache{}
+
+	// providerRateLimits 按各DNS服务商文档中的QPS限制设置的默认值
+	providerRateLimits = map[string]struct {
+		endpoint string
+		qps      float64
+	}{
+		"alidns":       {alidnsEndpoint, 20},
+		"esa":          {esaEndpoint, 10},
+		"tencentcloud": {tencentCloudEndPoint, 20},
+		"edgeone":      {edgeoneEndPoint, 20},
+		"dnspod":       {recordListAPI, 5},
+		"cloudflare":   {zonesAPI, 4},
+		"huaweicloud":  {huaweicloudEndpoint, 10},
+		"porkbun":      {porkbunEndpoint, 1},
+		"gcore":        {gcoreAPIEndpoint, 10},
+	}
 )
 
-// RunTimer 定时运行
-func RunTimer(delay time.Duration) {
-	for {
+// providerAuthHints 各DNS服务商返回 401/403 时的处理建议
+var providerAuthHints = map[string]string{
+	alidnsEndpoint:            "请确认RAM用户已授予 AliyunDNSFullAccess 权限",
+	esaEndpoint:               "请确认RAM用户已授予 AliyunESAFullAccess 权限",
+	zonesAPI:                  "请确认Token拥有 Zone:DNS:Edit 权限, 负载均衡需要 Load Balancing: Monitors and Pools:Edit 权限",
+	huaweicloudEndpoint:       "请确认IAM用户已授予 DNS FullAccess 权限",
+	tencentCloudEndPoint:      "请确认子用户已授予 QcloudDNSPodFullAccess 权限",
+	edgeoneEndPoint:           "请确认子用户已授予 QcloudTEOFullAccess 权限",
+	gcoreAPIEndpoint:          "请确认API Token的角色拥有修改DNS的权限",
+	porkbunEndpoint:           "请确认已在Porkbun的域名设置中开启 API ACCESS",
+	githubAPIEndpoint:         "请确认令牌拥有 gist 或 contents 写入权限",
+	"https://api.godaddy.com": "请确认使用的是 Production 环境的Key, GoDaddy 只允许域名数量达到要求的帐号使用API",
+}
+
+func init() {
+	for endpoint, hint := range providerAuthHints {
+		if u, err := url.Parse(endpoint); err == nil {
+			util.SetAuthHint(u.Hostname(), hint)
+		}
+	}
+}
+
+// setRateLimits 设置各DNS服务商的限流, 配置文件中的 QPS 优先
+// 配置了 Endpoint 时同时限制实际访问的地址
+func setRateLimits(conf *config.Config) {
+	for name, limit := range providerRateLimits {
+		qps := limit.qps
+		if v, ok := conf.QPS[name]; ok {
+			qps = v
+		}
+		endpoints := []string{limit.endpoint}
+		for _, dc := range conf.DnsConf {
+			if dc.DNS.Name == name && dc.DNS.Endpoint != "" {
+				endpoints = append(endpoints, dc.DNS.Endpoint)
+			}
+		}
+		for _, endpoint := range endpoints {
+			if u, err := url.Parse(endpoint); err == nil && u.Hostname() != "" {
+				util.SetRateLimit(u.Hostname(), qps)
+			}
+		}
+	}
+}
+
+// RunTimer 定时运行, 收到外部触发时提前运行. ctx 取消后完成当前一轮更新再返回
+func RunTimer(ctx context.Context, delay time.Duration) {
+	notifyTrigger()
+	runGrace()
+	for ctx.Err() == nil {
 		RunOnce()
-		time.Sleep(delay)
+		waitNextRun(ctx, delay, triggerDebounce())
 	}
 }
 
+// runGrace 启动时先发布配置的 GraceIP, 之后立即获取真实的IP
+func runGrace() {
+	conf, err := config.GetConfigCached()
+	if err != nil || !conf.HasGraceIP() {
+		return
+	}
+	config.SetGraceRun(true)
+	defer config.SetGraceRun(false)
+	RunOnce()
+}
+
 // RunOnce RunOnce
 func RunOnce() {
 	conf, err := config.GetConfigCached()
 	if err != nil {
 		return
 	}
-	if util.ForceCompareGlobal || len(Ipcache) != len(conf.DnsConf) {
+	setRunning(true)
+	defer setRunning(false)
+
+	// 只读取一次, 并发运行的配置使用同一个值
+	force := util.ForceCompareGlobal
+	if force || len(Ipcache) != len(conf.DnsConf) {
 		Ipcache = [][2]util.IpCache{}
 		for range conf.DnsConf {
 			Ipcache = append(Ipcache, [2]util.IpCache{{}, {}})
 		}
 	}
+	setRateLimits(&conf)
+	util.SetConnectOverrides(conf.ConnectOverride)
+	util.SetLogRepeatWindow(time.Duration(conf.LogRepeatWindow) * time.Second)
+	config.ResetPushedTargets()
 
-	for i, dc := range conf.DnsConf {
-		var dnsSelected DNS
-		switch dc.DNS.Name {
-		case "alidns":
-			dnsSelected = &Alidns{}
-		case "aliyun":
-			dnsSelected = &Alidns{}
-		case "esa":
-			dnsSelected = &ESA{}
-		case "tencentcloud":
-			dnsSelected = &TencentCloud{}
-		case "trafficroute":
-			dnsSelected = &TrafficRoute{}
-		case "dnspod":
-			dnsSelected = &Dnspod{}
-		case "dnsla":
-			dnsSelected = &Dnsla{}
-		case "cloudflare":
-			dnsSelected = &Cloudflare{}
-		case "huaweicloud":
-			dnsSelected = &Huaweicloud{}
-		case "callback":
-			dnsSelected = &Callback{}
-		case "baiducloud":
-			dnsSelected = &BaiduCloud{}
-		case "porkbun":
-			dnsSelected = &Porkbun{}
-		case "godaddy":
-			dnsSelected = &GoDaddyDNS{}
-		case "namecheap":
-			dnsSelected = &NameCheap{}
-		case "namesilo":
-			dnsSelected = &NameSilo{}
-		case "vercel":
-			dnsSelected = &Vercel{}
-		case "dynadot":
-			dnsSelected = &Dynadot{}
-		case "dynv6":
-			dnsSelected = &Dynv6{}
-		case "spaceship":
-			dnsSelected = &Spaceship{}
-		case "nowcn":
-			dnsSelected = &Nowcn{}
-		case "eranet":
-			dnsSelected = &Eranet{}
-		case "gcore":
-			dnsSelected = &Gcore{}
-		case "edgeone":
-			dnsSelected = &EdgeOne{}
-		case "nsone":
-			dnsSelected = &NSOne{}
-		default:
-			dnsSelected = &Alidns{}
+	// 获取到的IP, 按配置的顺序使用第一个获取到的IP写入文件/执行命令
+	addrs := make([][2]string, len(conf.DnsConf))
+	failed := make([][2]bool, len(conf.DnsConf))
+	secondary := failoverSecondaries(conf.DnsConf)
+	runDnsConfs(conf.DnsConf, conf.Concurrency, func(i int, dc *config.DnsConfig) {
+		if !secondary[i] {
+			addrs[i], failed[i] = runDnsConf(i, dc, &conf, force)
+		}
+	})
+	// 备用DNS配置只更新主配置失败的IPv4/IPv6
+	runDnsConfs(conf.DnsConf, conf.Concurrency, func(i int, dc *config.DnsConfig) {
+		if secondary[i] && useFailover(dc, failed[failoverPrimary(conf.DnsConf, dc)]) {
+			addrs[i], failed[i] = runDnsConf(i, dc, &conf, force)
+			logFailoverResult(dc, failed[i])
 		}
-		dnsSelected.Init(&dc, &Ipcache[i][0], &Ipcache[i][1])
-		domains := dnsSelected.AddUpdateDomainRecords()
-		// webhook
-		v4Status, v6Status := config.ExecWebhook(&domains, &conf)
-		// 重置单个cache
-		if v4Status == config.UpdatedFailed {
-			Ipcache[i][0] = util.IpCache{}
+	})
+	var ipv4Addr, ipv6Addr string
+	for _, addr := range addrs {
+		if ipv4Addr == "" {
+			ipv4Addr = addr[0]
 		}
-		if v6Status == config.UpdatedFailed {
-			Ipcache[i][1] = util.IpCache{}
+		if ipv6Addr == "" {
+			ipv6Addr = addr[1]
 		}
 	}
 
+	config.ExecIPOutput(ipv4Addr, ipv6Addr, &conf)
+	config.ExecPTR(ipv4Addr, ipv6Addr, &conf)
+	finishRunMetrics()
+	setRunAddrs(ipv4Addr, ipv6Addr)
+	util.FlushLogRepeat()
+
 	util.ForceCompareGlobal = false
 }
+
+// newDNS 按名称返回DNS服务商, 未知的名称为阿里云
+func newDNS(name string) DNS {
+	switch name {
+	case "alidns":
+		return &Alidns{}
+	case "aliyun":
+		return &Alidns{}
+	case "esa":
+		return &ESA{}
+	case "tencentcloud":
+		return &TencentCloud{}
+	case "trafficroute":
+		return &TrafficRoute{}
+	case "dnspod":
+		return &Dnspod{}
+	case "dnsla":
+		return &Dnsla{}
+	case "cloudflare":
+		return &Cloudflare{}
+	case "cloudflarelb":
+		return &CloudflareLB{}
+	case "huaweicloud":
+		return &Huaweicloud{}
+	case "callback":
+		return &Callback{}
+	case "baiducloud":
+		return &BaiduCloud{}
+	case "porkbun":
+		return &Porkbun{}
+	case "godaddy":
+		return &GoDaddyDNS{}
+	case "namecheap":
+		return &NameCheap{}
+	case "namesilo":
+		return &NameSilo{}
+	case "vercel":
+		return &Vercel{}
+	case "dynadot":
+		return &Dynadot{}
+	case "dynv6":
+		return &Dynv6{}
+	case "spaceship":
+		return &Spaceship{}
+	case "nowcn":
+		return &Nowcn{}
+	case "eranet":
+		return &Eranet{}
+	case "gcore":
+		return &Gcore{}
+	case "edgeone":
+		return &EdgeOne{}
+	case "nsone":
+		return &NSOne{}
+	case "github":
+		return &GitHub{}
+	case "external":
+		return &External{}
+	case "nsupdate":
+		return &NSUpdate{}
+	default:
+		return &Alidns{}
+	}
+}
+
+// SupportsDelete 该DNS服务商是否支持删除记录, 见 state=absent 和 config.PartialDelete
+func SupportsDelete(name string) bool {
+	_, ok := newDNS(name).(recordDeleter)
+	return ok
+}
+
+// runDnsConf 更新第 i 个DNS配置, 返回获取到的IPv4/IPv6, 以及IPv4/IPv6是否更新失败
+// force 为 true 时忽略服务商维护的状态
+func runDnsConf(i int, dc *config.DnsConfig, conf *config.Config, force bool) (addr [2]string, failed [2]bool) {
+	// 服务商正在维护, 未到重试时间
+	if !force && inMaintenance(dc.DNS.Name, time.Now()) {
+		return addr, [2]bool{true, true}
+	}
+
+	dnsSelected := newDNS(dc.DNS.Name)
+	if _, ok := dnsSelected.(recordDeleter); !ok && dc.Partial == config.PartialDelete {
+		util.Log("DNS服务商 %s 不支持删除记录, 将保留记录", dc.DNS.Name)
+	}
+	if dc.HTTPTimeout <= 0 {
+		dc.HTTPTimeout = conf.HTTPTimeout
+	}
+	// 只统计该配置的请求, 不受并发运行的其它配置影响
+	counter := &util.HTTPCounter{}
+	dc.SetContext(util.WithHTTPCounter(context.Background(), counter))
+	dnsSelected.Init(dc, &Ipcache[i][0], &Ipcache[i][1])
+	domains := dnsSelected.AddUpdateDomainRecords()
+	domains.MarkPushed()
+	deleteAbsentRecords(dnsSelected, dc.DNS.Name, &domains)
+	unavailable := counter.ServiceUnavailable() > 0 && hasFailedDomain(&domains)
+	checkMaintenance(dc.DNS.Name, &domains, unavailable, time.Now())
+	waitPropagation(dc, &domains)
+	config.CheckIpReverted(&domains, conf)
+	// webhook
+	v4Status, v6Status := config.ExecWebhook(&domains, conf)
+	config.RecordRunResult(&domains)
+	config.RecordUpdateHistory(dc.DNS.Name, &domains, counter.Stats())
+	config.RecordPublishedIP(dc.DNS.Name, &domains)
+	recordDomainStatus(dc.DNS.Name, &domains)
+	// 重置单个cache, 服务商维护时也需要重试
+	if v4Status == config.UpdatedFailed || unavailable {
+		Ipcache[i][0] = util.IpCache{}
+	}
+	if v6Status == config.UpdatedFailed || unavailable {
+		Ipcache[i][1] = util.IpCache{}
+	}
+	addr = [2]string{domains.Ipv4Addr, domains.Ipv6Addr}
+	failed = [2]bool{v4Status == config.UpdatedFailed || unavailable, v6Status == config.UpdatedFailed || unavailable}
+	return
+}
+
+// deleteAbsentRecords 删除 state=absent 的域名的记录, 记录不存在时不处理
+func deleteAbsentRecords(dnsSelected DNS, name string, domains *config.Domains) {
+	for _, recordType := range []string{"A", "AAAA"} {
+		list := domains.GetAbsentDomains(recordType)
+		if len(list) == 0 {
+			continue
+		}
+		deleter, ok := dnsSelected.(recordDeleter)
+		if !ok {
+			util.Log("DNS服务商 %s 不支持删除记录, 将跳过 state=absent 的域名", name)
+			continue
+		}
+		deleter.deleteDomainRecords(recordType, list)
+	}
+}
+
+// runDnsConfs 同时运行 concurrency 个DNS服务商, 同一服务商的配置按顺序运行, 避免超出服务商的限制
+func runDnsConfs(dnsConf []config.DnsConfig, concurrency int, run func(i int, dc *config.DnsConfig)) {
+	if concurrency <= 1 {
+		for i, dc := range dnsConf {
+			run(i, &dc)
+		}
+		return
+	}
+
+	var names []string
+	providers := map[string][]int{}
+	for i, dc := range dnsConf {
+		if _, ok := providers[dc.DNS.Name]; !ok {
+			names = append(names, dc.DNS.Name)
+		}
+		providers[dc.DNS.Name] = append(providers[dc.DNS.Name], i)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(list []int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for _, i := range list {
+				dc := dnsConf[i]
+				run(i, &dc)
+			}
+		}(providers[name])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,58 @@
+package dns
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// TestRunDnsConfs 测试同时运行的服务商个数不超过限制, 同一服务商的配置按顺序运行
+func TestRunDnsConfs(t *testing.T) {
+	var dnsConf []config.DnsConfig
+	for _, name := range []string{"alidns", "cloudflare", "alidns", "dnspod", "cloudflare"} {
+		dnsConf = append(dnsConf, config.DnsConfig{DNS: config.DNS{Name: name}})
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	providers := map[string]bool{}
+	done := make([]bool, len(dnsConf))
+	runDnsConfs(dnsConf, 2, func(i int, dc *config.DnsConfig) {
+		mu.Lock()
+		if providers[dc.DNS.Name] {
+			t.Errorf("服务商 %s 的配置同时运行", dc.DNS.Name)
+		}
+		providers[dc.DNS.Name] = true
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		providers[dc.DNS.Name] = false
+		running--
+		done[i] = true
+		mu.Unlock()
+	})
+
+	if maxRunning != 2 {
+		t.Errorf("期待同时运行2个服务商, 得到 %d", maxRunning)
+	}
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("第 %d 个配置没有运行", i)
+		}
+	}
+}
+
+// TestSupportsDelete 测试只有实现了删除记录的服务商支持 state=absent
+func TestSupportsDelete(t *testing.T) {
+	for name, expected := range map[string]bool{"cloudflare": true, "esa": true, "alidns": false, "dnspod": false} {
+		if got := SupportsDelete(name); got != expected {
+			t.Errorf("%s 期待 %t, 得到 %t", name, expected, got)
+		}
+	}
+}
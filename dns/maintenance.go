@@ -0,0 +1,91 @@
+package dns
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+const (
+	// 服务商返回503后第一次重试的等待时间, 之后每次加倍
+	maintenanceMinBackoff = 5 * time.Minute
+	maintenanceMaxBackoff = time.Hour
+	// 维护超过该时间仍未恢复时才算失败并通知
+	maintenanceNotifyAfter = time.Hour
+)
+
+// maintenance 服务商的维护状态
+type maintenance struct {
+	// 第一次返回503的时间
+	since   time.Time
+	retryAt time.Time
+	backoff time.Duration
+}
+
+var maintenances = struct {
+	sync.Mutex
+	providers map[string]*maintenance
+}{providers: map[string]*maintenance{}}
+
+// inMaintenance 服务商正在维护且未到重试时间时跳过本次更新
+func inMaintenance(provider string, now time.Time) bool {
+	maintenances.Lock()
+	defer maintenances.Unlock()
+
+	m := maintenances.providers[provider]
+	if m == nil || !now.Before(m.retryAt) {
+		return false
+	}
+	util.Log("DNS服务商 %s 正在维护, 将在 %s 后重试", provider, m.retryAt.Format("15:04:05"))
+	return true
+}
+
+// checkMaintenance 本次更新中服务商返回了503时推迟重试, 维护未超过 maintenanceNotifyAfter 时失败的域名不算失败
+func checkMaintenance(provider string, domains *config.Domains, unavailable bool, now time.Time) {
+	maintenances.Lock()
+	defer maintenances.Unlock()
+
+	m := maintenances.providers[provider]
+	if !unavailable {
+		if m != nil {
+			util.Log("DNS服务商 %s 已结束维护", provider)
+			delete(maintenances.providers, provider)
+		}
+		return
+	}
+
+	if m == nil {
+		m = &maintenance{since: now, backoff: maintenanceMinBackoff}
+		maintenances.providers[provider] = m
+	} else {
+		m.backoff = min(m.backoff*2, maintenanceMaxBackoff)
+	}
+	m.retryAt = now.Add(m.backoff)
+
+	if now.Sub(m.since) >= maintenanceNotifyAfter {
+		util.Log("DNS服务商 %s 维护已超过 %s, 将视为更新失败", provider, maintenanceNotifyAfter)
+		return
+	}
+	util.Log("DNS服务商 %s 正在维护, 将在 %s 后重试, 超过 %s 仍未恢复时才通知失败", provider, m.retryAt.Format("15:04:05"), maintenanceNotifyAfter)
+	for _, list := range [][]*config.Domain{domains.Ipv4Domains, domains.Ipv6Domains} {
+		for _, domain := range list {
+			if domain.UpdateStatus == config.UpdatedFailed {
+				domain.UpdateStatus = config.UpdatedMaintenance
+			}
+		}
+	}
+}
+
+// hasFailedDomain 是否有更新失败的域名。同时运行时其他服务商返回的503也会计入, 本服务商有失败的域名时才算正在维护
+func hasFailedDomain(domains *config.Domains) bool {
+	for _, list := range [][]*config.Domain{domains.Ipv4Domains, domains.Ipv6Domains} {
+		for _, domain := range list {
+			if domain.UpdateStatus == config.UpdatedFailed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// TestCheckMaintenance 测试服务商返回503时推迟重试, 维护超过一定时间才算失败
+func TestCheckMaintenance(t *testing.T) {
+	defer delete(maintenances.providers, "test")
+
+	newDomains := func() *config.Domains {
+		return &config.Domains{Ipv4Domains: []*config.Domain{{DomainName: "example.com", UpdateStatus: config.UpdatedFailed}}}
+	}
+	now := time.Date(2024, 1, 15, 3, 0, 0, 0, time.Local)
+
+	domains := newDomains()
+	checkMaintenance("test", domains, true, now)
+	if domains.Ipv4Domains[0].UpdateStatus != config.UpdatedMaintenance {
+		t.Errorf("维护开始时不应算失败, 实际: %s", domains.Ipv4Domains[0].UpdateStatus)
+	}
+	if !inMaintenance("test", now.Add(4*time.Minute)) || inMaintenance("test", now.Add(5*time.Minute)) {
+		t.Error("第一次应在5分钟后重试")
+	}
+
+	// 重试时间加倍
+	now = now.Add(5 * time.Minute)
+	checkMaintenance("test", newDomains(), true, now)
+	if !inMaintenance("test", now.Add(9*time.Minute)) || inMaintenance("test", now.Add(10*time.Minute)) {
+		t.Error("第二次应在10分钟后重试")
+	}
+
+	domains = newDomains()
+	checkMaintenance("test", domains, true, now.Add(time.Hour))
+	if domains.Ipv4Domains[0].UpdateStatus != config.UpdatedFailed {
+		t.Errorf("维护超过1小时应算失败, 实际: %s", domains.Ipv4Domains[0].UpdateStatus)
+	}
+
+	checkMaintenance("test", newDomains(), false, now.Add(2*time.Hour))
+	if inMaintenance("test", now.Add(2*time.Hour)) {
+		t.Error("未返回503时应结束维护")
+	}
+}
@@ -0,0 +1,87 @@
+package dns
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// 记录操作的类型
+const (
+	MetricCreate    = "create"
+	MetricUpdate    = "update"
+	MetricUnchanged = "unchanged"
+	MetricFailed    = "failed"
+)
+
+// MetricKey 统计的维度, 最后一次更新成功的时间不区分 Action
+type MetricKey struct {
+	Provider   string
+	Domain     string
+	RecordType string
+	Action     string
+}
+
+// Metrics 记录操作的统计, 供 /metrics 接口读取
+type Metrics struct {
+	// Total 启动以来的次数
+	Total map[MetricKey]uint64
+	// LastRun 上次运行的次数
+	LastRun map[MetricKey]uint64
+	// LastSuccess 域名最后一次更新成功的时间
+	LastSuccess map[MetricKey]time.Time
+}
+
+var metrics = struct {
+	sync.Mutex
+	total, run, lastRun map[MetricKey]uint64
+	lastSuccess         map[MetricKey]time.Time
+}{
+	total:       map[MetricKey]uint64{},
+	run:         map[MetricKey]uint64{},
+	lastRun:     map[MetricKey]uint64{},
+	lastSuccess: map[MetricKey]time.Time{},
+}
+
+// addMetric 记录一次操作
+func addMetric(provider string, domain *config.Domain, recordType string, action string) {
+	key := MetricKey{provider, domain.String(), recordType, action}
+	metrics.Lock()
+	metrics.total[key]++
+	metrics.run[key]++
+	metrics.Unlock()
+}
+
+// addStatusMetric 按域名本轮的更新状态记录未改变/失败的次数, 以及最后一次更新成功的时间
+func addStatusMetric(provider string, domain *config.Domain, recordType string) {
+	switch domain.UpdateStatus {
+	case config.UpdatedNothing:
+		addMetric(provider, domain, recordType, MetricUnchanged)
+	case config.UpdatedFailed:
+		addMetric(provider, domain, recordType, MetricFailed)
+	case config.UpdatedSuccess:
+		metrics.Lock()
+		metrics.lastSuccess[MetricKey{Provider: provider, Domain: domain.String(), RecordType: recordType}] = time.Now()
+		metrics.Unlock()
+	}
+}
+
+// finishRunMetrics 运行结束时保存本轮的次数
+func finishRunMetrics() {
+	metrics.Lock()
+	metrics.lastRun, metrics.run = metrics.run, map[MetricKey]uint64{}
+	metrics.Unlock()
+}
+
+// GetMetrics 获得记录操作的统计
+func GetMetrics() Metrics {
+	metrics.Lock()
+	defer metrics.Unlock()
+	return Metrics{
+		Total:       maps.Clone(metrics.total),
+		LastRun:     maps.Clone(metrics.lastRun),
+		LastSuccess: maps.Clone(metrics.lastSuccess),
+	}
+}
@@ -109,8 +109,9 @@ func (ns *NameSilo) addUpdateDomainRecords(recordType string) {
 			isAdd = true
 		} else {
 			recordID = record.RecordID
-			if record.Value == ipAddr {
+			if util.SameIP(record.Value, ipAddr) {
 				util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+				domain.UpdateStatus = config.UpdatedNothing
 				continue
 			}
 		}
@@ -180,7 +181,7 @@ func (ns *NameSilo) request(ipAddr string, domain *config.Domain, recordID, reco
 		return
 	}
 
-	client := util.CreateHTTPClient()
+	client := ns.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return
@@ -137,8 +137,9 @@ func (nowcn *Nowcn) create(domain *config.Domain, recordType string, ipAddr stri
 // modify 修改DNS记录
 func (nowcn *Nowcn) modify(record NowcnRecord, domain *config.Domain, recordType string, ipAddr string) {
 	// 相同不修改
-	if record.Value == ipAddr {
+	if util.SameIP(record.Value, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	param := map[string]string{
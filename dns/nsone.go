@@ -307,8 +307,9 @@ func (nsone *NSOne) createRecord(domain *config.Domain, recordType string, ipAdd
 
 func (nsone *NSOne) updateRecord(domain *config.Domain, recordType string, ipAddr string, existingRecord *NSOneRecordResponse) {
 	if len(existingRecord.Answers) > 0 && len(existingRecord.Answers[0].Answer) > 0 {
-		if existingRecord.Answers[0].Answer[0] == ipAddr {
+		if util.SameIP(existingRecord.Answers[0].Answer[0], ipAddr) {
 			util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+			domain.UpdateStatus = config.UpdatedNothing
 			return
 		}
 	}
@@ -364,7 +365,7 @@ func (nsone *NSOne) request(method string, url string, data interface{}, result
 	req.Header.Set("X-NSONE-Key", nsone.DNS.Secret)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := nsone.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"errors"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// nsupdateDefaultTTL TTL 为空时使用的值
+const nsupdateDefaultTTL = 600
+
+// NSUpdate 通过 RFC 2136 动态更新(nsupdate)修改自建DNS(如BIND)的记录
+// DNS.ID 为TSIG密钥名称, 可加上算法前缀如 hmac-sha512:ddns-key, 与 nsupdate -y 相同, 为空时不签名
+// DNS.Secret 为base64编码的TSIG密钥, DNS.ExtParam 为权威DNS服务器地址
+// 区域(zone)为根域名, 可通过 子域名:区域 指定, 如 www:home.example.com
+type NSUpdate struct {
+	DNS     config.DNS
+	Domains config.Domains
+	TTL     uint32
+}
+
+// Init 初始化
+func (nsu *NSUpdate) Init(dnsConf *config.DnsConfig, ipv4cache *util.IpCache, ipv6cache *util.IpCache) {
+	nsu.Domains.Ipv4Cache = ipv4cache
+	nsu.Domains.Ipv6Cache = ipv6cache
+	nsu.DNS = dnsConf.DNS
+	nsu.Domains.GetNewIp(dnsConf)
+
+	nsu.TTL = nsupdateDefaultTTL
+	if ttl, err := strconv.ParseUint(dnsConf.TTL, 10, 32); err == nil {
+		nsu.TTL = uint32(ttl)
+	}
+}
+
+// AddUpdateDomainRecords 添加或更新IPv4/IPv6记录
+func (nsu *NSUpdate) AddUpdateDomainRecords() config.Domains {
+	nsu.addUpdateDomainRecords("A")
+	nsu.addUpdateDomainRecords("AAAA")
+	return nsu.Domains
+}
+
+func (nsu *NSUpdate) addUpdateDomainRecords(recordType string) {
+	ipAddr, domains := nsu.Domains.GetNewIpResult(recordType)
+
+	if ipAddr == "" {
+		return
+	}
+
+	for _, domain := range domains {
+		err := nsu.update(domain, ipAddr)
+		if err != nil {
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateStatus = config.UpdatedFailed
+			continue
+		}
+		util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
+		domain.UpdateStatus = config.UpdatedSuccess
+	}
+}
+
+// update 替换域名的记录
+func (nsu *NSUpdate) update(domain *config.Domain, ipAddr string) error {
+	server := strings.TrimSpace(nsu.DNS.ExtParam)
+	if server == "" {
+		return errors.New("the DNS server is empty")
+	}
+	ip, err := netip.ParseAddr(ipAddr)
+	if err != nil {
+		return err
+	}
+	return util.DNSUpdate(server, domain.DomainName, domain.String(), ip, nsu.TTL, nsu.tsigKey())
+}
+
+// tsigKey 解析 [算法:]名称 格式的TSIG密钥, 未填写时不签名
+func (nsu *NSUpdate) tsigKey() *util.TSIGKey {
+	if nsu.DNS.ID == "" {
+		return nil
+	}
+	key := &util.TSIGKey{Name: nsu.DNS.ID, Secret: nsu.DNS.Secret}
+	if algorithm, name, ok := strings.Cut(nsu.DNS.ID, ":"); ok {
+		key.Algorithm, key.Name = algorithm, name
+	}
+	return key
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/jeessy2/ddns-go/v6/config"
 	"github.com/jeessy2/ddns-go/v6/util"
@@ -20,6 +21,7 @@ type Porkbun struct {
 	TTL       string
 }
 type PorkbunDomainRecord struct {
+	ID      *string `json:"id,omitempty"`
 	Name    *string `json:"name"`    // subdomain
 	Type    *string `json:"type"`    // record type, e.g. A AAAA CNAME
 	Content *string `json:"content"` // value
@@ -93,7 +95,13 @@ func (pb *Porkbun) addUpdateDomainRecords(recordType string) {
 		if record.Status == "SUCCESS" {
 			if len(record.Records) > 0 {
 				// 存在，更新
-				pb.modify(&record, domain, recordType, ipAddr)
+				selected, err := selectPorkbunRecord(record.Records, domain.GetCustomParams(), ipAddr)
+				if err != nil {
+					util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+					domain.UpdateStatus = config.UpdatedFailed
+					continue
+				}
+				pb.modify(selected, domain, recordType, ipAddr)
 			} else {
 				// 不存在，创建
 				pb.create(domain, recordType, ipAddr)
@@ -141,25 +149,59 @@ func (pb *Porkbun) create(domain *config.Domain, recordType string, ipAddr strin
 	}
 }
 
+// selectPorkbunRecord 选择要更新的记录
+// editByNameType 会覆盖同名同类型的所有记录, 因此存在多条记录时只按 id 更新其中一条:
+// 可通过参数 id 指定, 否则已是当前IP的记录无需更新, 无法确定时不更新
+func selectPorkbunRecord(records []PorkbunDomainRecord, params url.Values, ipAddr string) (*PorkbunDomainRecord, error) {
+	if params.Has("id") {
+		for i := range records {
+			if records[i].ID != nil && *records[i].ID == params.Get("id") {
+				return &records[i], nil
+			}
+		}
+		return nil, fmt.Errorf("record id %s not found", params.Get("id"))
+	}
+
+	if len(records) == 1 {
+		return &records[0], nil
+	}
+
+	for i := range records {
+		if records[i].Content != nil && util.SameIP(*records[i].Content, ipAddr) {
+			return &records[i], nil
+		}
+	}
+	return nil, fmt.Errorf("found %d records with the same name and type, please specify the record to update with ?id=", len(records))
+}
+
 // 修改
-func (pb *Porkbun) modify(record *PorkbunDomainQueryResponse, domain *config.Domain, recordType string, ipAddr string) {
+func (pb *Porkbun) modify(record *PorkbunDomainRecord, domain *config.Domain, recordType string, ipAddr string) {
 
 	// 相同不修改
-	if len(record.Records) > 0 && *record.Records[0].Content == ipAddr {
+	if record.Content != nil && util.SameIP(*record.Content, ipAddr) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 
 	var response PorkbunResponse
 
+	// 有记录 id 时只更新这一条记录
+	editURL := porkbunEndpoint + fmt.Sprintf("/editByNameType/%s/%s/%s", domain.DomainName, recordType, domain.SubDomain)
+	if record.ID != nil {
+		editURL = porkbunEndpoint + fmt.Sprintf("/edit/%s/%s", domain.DomainName, *record.ID)
+	}
+
 	err := pb.request(
-		porkbunEndpoint+fmt.Sprintf("/editByNameType/%s/%s/%s", domain.DomainName, recordType, domain.SubDomain),
+		editURL,
 		&PorkbunDomainCreateOrUpdateVO{
 			PorkbunApiKey: &PorkbunApiKey{
 				AccessKey: pb.DNSConfig.ID,
 				SecretKey: pb.DNSConfig.Secret,
 			},
 			PorkbunDomainRecord: &PorkbunDomainRecord{
+				Name:    &domain.SubDomain,
+				Type:    &recordType,
 				Content: &ipAddr,
 				Ttl:     &pb.TTL,
 			},
@@ -198,7 +240,7 @@ func (pb *Porkbun) request(url string, data interface{}, result interface{}) (er
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := pb.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -0,0 +1,51 @@
+package dns
+
+import (
+	"net/url"
+	"testing"
+)
+
+func porkbunRecord(id, content string) PorkbunDomainRecord {
+	return PorkbunDomainRecord{ID: &id, Content: &content}
+}
+
+// TestSelectPorkbunRecord 测试存在多条同名同类型记录时只选择一条记录更新
+func TestSelectPorkbunRecord(t *testing.T) {
+	records := []PorkbunDomainRecord{
+		porkbunRecord("1", "1.1.1.1"),
+		porkbunRecord("2", "2.2.2.2"),
+	}
+
+	tests := map[string]struct {
+		records  []PorkbunDomainRecord
+		params   url.Values
+		ipAddr   string
+		expected string
+		wantErr  bool
+	}{
+		"单条记录":        {records[:1], url.Values{}, "3.3.3.3", "1", false},
+		"指定 id":       {records, url.Values{"id": {"2"}}, "3.3.3.3", "2", false},
+		"指定的 id 不存在":  {records, url.Values{"id": {"9"}}, "3.3.3.3", "", true},
+		"多条记录中已有当前IP": {records, url.Values{}, "2.2.2.2", "2", false},
+		"IPv6表示方式不同":  {[]PorkbunDomainRecord{porkbunRecord("1", "2001:db8::1"), porkbunRecord("2", "2001:0DB8:0000:0000:0000:0000:0000:0002")}, url.Values{}, "2001:db8::2", "2", false},
+		"多条记录无法确定":    {records, url.Values{}, "3.3.3.3", "", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			record, err := selectPorkbunRecord(tt.records, tt.params, tt.ipAddr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("期待返回错误, 得到记录 %s", *record.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *record.ID != tt.expected {
+				t.Errorf("期待记录 %s, 得到 %s", tt.expected, *record.ID)
+			}
+		})
+	}
+}
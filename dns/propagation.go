@@ -0,0 +1,82 @@
+package dns
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// propagationInterval 查询解析是否生效的间隔
+var propagationInterval = 5 * time.Second
+
+// propagationTarget 等待解析生效的域名及发布的IP
+type propagationTarget struct {
+	domain  *config.Domain
+	network string
+	ipAddr  string
+}
+
+// waitPropagation 更新成功后查询域名的解析, 直到为新的IP或超过 Propagation.Timeout
+// 超时的域名标记为 PropagationPending, 通知的结果为 config.UpdatedPending
+func waitPropagation(dc *config.DnsConfig, domains *config.Domains) {
+	if dc.Propagation.Timeout <= 0 {
+		return
+	}
+
+	var targets []propagationTarget
+	add := func(list []*config.Domain, network string, ipAddr string) {
+		for _, domain := range list {
+			if domain.UpdateStatus != config.UpdatedSuccess {
+				continue
+			}
+			addr := ipAddr
+			if network == "ip6" {
+				// 成功时 ipv6suffix 一定是正确的
+				addr, _ = domain.Ipv6Addr(ipAddr)
+			}
+			targets = append(targets, propagationTarget{domain: domain, network: network, ipAddr: addr})
+		}
+	}
+	add(domains.Ipv4Domains, "ip4", domains.Ipv4Addr)
+	add(domains.Ipv6Domains, "ip6", domains.Ipv6Addr)
+
+	deadline := time.Now().Add(time.Duration(dc.Propagation.Timeout) * time.Second)
+	for len(targets) > 0 {
+		remaining := targets[:0]
+		for _, t := range targets {
+			if resolvesTo(t, dc.Propagation.Resolver, deadline) {
+				util.Log("域名 %s 的解析已生效, IP: %s", t.domain, t.ipAddr)
+				continue
+			}
+			remaining = append(remaining, t)
+		}
+		targets = remaining
+		if len(targets) == 0 || time.Now().Add(propagationInterval).After(deadline) {
+			break
+		}
+		time.Sleep(propagationInterval)
+	}
+
+	for _, t := range targets {
+		util.Log("等待域名 %s 的解析生效超时, IP: %s", t.domain, t.ipAddr)
+		t.domain.PropagationPending = true
+	}
+}
+
+// resolvesTo 域名的解析是否包含发布的IP, 单次查询最多等待到 deadline
+func resolvesTo(t propagationTarget, resolver string, deadline time.Time) bool {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	addrs, err := util.LookupAddrs(ctx, t.domain.DomainName, t.domain.ToASCII(), t.network, resolver)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(addrs, func(addr netip.Addr) bool {
+		return util.SameIP(addr.String(), t.ipAddr)
+	})
+}
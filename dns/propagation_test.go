@@ -0,0 +1,76 @@
+package dns
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// TestWaitPropagation 测试解析生效后不再等待, 超时的域名通知为等待生效
+func TestWaitPropagation(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// www 第3次查询时生效, slow 一直为旧的IP
+	var queries atomic.Int32
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			header, err := p.Start(buf[:n])
+			if err != nil {
+				continue
+			}
+			q, err := p.Question()
+			if err != nil {
+				continue
+			}
+			ip := [4]byte{1, 1, 1, 1}
+			if q.Name.String() == "www.example.com." && queries.Add(1) >= 3 {
+				ip = [4]byte{2, 2, 2, 2}
+			}
+			b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true, Authoritative: true})
+			b.StartQuestions()
+			b.Question(q)
+			b.StartAnswers()
+			if q.Type == dnsmessage.TypeA {
+				b.AResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.AResource{A: ip})
+			}
+			resp, _ := b.Finish()
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	interval := propagationInterval
+	propagationInterval = 50 * time.Millisecond
+	defer func() { propagationInterval = interval }()
+
+	dc := &config.DnsConfig{}
+	dc.Propagation.Timeout = 1
+	dc.Propagation.Resolver = conn.LocalAddr().String()
+	www := &config.Domain{DomainName: "example.com", SubDomain: "www", UpdateStatus: config.UpdatedSuccess}
+	slow := &config.Domain{DomainName: "example.com", SubDomain: "slow", UpdateStatus: config.UpdatedSuccess}
+	failed := &config.Domain{DomainName: "example.com", SubDomain: "failed", UpdateStatus: config.UpdatedFailed}
+	domains := &config.Domains{Ipv4Addr: "2.2.2.2", Ipv4Domains: []*config.Domain{www, slow, failed}}
+
+	start := time.Now()
+	waitPropagation(dc, domains)
+
+	if www.PropagationPending || !slow.PropagationPending || failed.PropagationPending {
+		t.Errorf("等待生效的状态不正确: www %v, slow %v, failed %v", www.PropagationPending, slow.PropagationPending, failed.PropagationPending)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("超时后应停止等待, 用时 %s", elapsed)
+	}
+}
@@ -0,0 +1,246 @@
+package dns
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// ProviderRecord DNS服务商的一条记录
+type ProviderRecord struct {
+	// ZoneID 记录所在的根域名(站点)ID, 不需要时为空
+	ZoneID string
+	ID     string
+	Value  string
+	// Comment 记录的备注, 用于判断是否由ddns-go管理
+	Comment string
+	// Outdated 除值以外的属性与配置不同, 值相同时也需要更新
+	Outdated bool
+}
+
+// RecordProvider 提供记录增删改查的DNS服务商, 添加或更新的流程由 addUpdateRecord 统一处理
+type RecordProvider interface {
+	// ListRecords 查询域名该类型的所有记录
+	ListRecords(domain *config.Domain, recordType string) ([]ProviderRecord, error)
+	CreateRecord(domain *config.Domain, recordType string, value string) error
+	UpdateRecord(domain *config.Domain, recordType string, record ProviderRecord, value string) error
+	DeleteRecord(domain *config.Domain, recordType string, record ProviderRecord) error
+}
+
+// dryRunner 可以预演的DNS服务商, 见 config.DnsConfig.DryRun
+type dryRunner interface {
+	DryRun() bool
+}
+
+// isDryRun 是否只查询记录, 不新增/更新/删除
+func isDryRun(p RecordProvider) bool {
+	d, ok := p.(dryRunner)
+	return ok && d.DryRun()
+}
+
+// recordVerifier 可以在新增/更新后再查询记录确认结果的DNS服务商, 见 config.DnsConfig.VerifyRecord
+type recordVerifier interface {
+	verifyRecord() bool
+}
+
+// verifyRecord 开启 VerifyRecord 时重新查询记录, 没有值为 ipAddr 的记录时记录失败
+func verifyRecord(p RecordProvider, recordType string, ipAddr string, domain *config.Domain) {
+	v, ok := p.(recordVerifier)
+	if !ok || !v.verifyRecord() {
+		return
+	}
+	records, err := p.ListRecords(domain, recordType)
+	if err != nil {
+		util.Log("确认域名 %s 的记录时查询失败! 异常信息: %s", domain, err)
+		domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+		return
+	}
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		if util.SameIP(record.Value, ipAddr) {
+			return
+		}
+		values = append(values, record.Value)
+	}
+	util.Log("更新后查询到域名 %s 的 %s 记录为 %v, 不是 %s", domain, recordType, values, ipAddr)
+	domain.UpdateStatus = config.UpdatedFailed
+	domain.UpdateError = fmt.Sprintf("%s record of %s is %v after update, expected %s", recordType, domain, values, ipAddr)
+}
+
+// duplicateCleaner 可以清理重复记录的DNS服务商, 见 config.DnsConfig.Cleanup
+type duplicateCleaner interface {
+	// cleanup 由ddns-go管理的记录的备注, 为空时不清理; 是否确认删除
+	cleanup() (tag string, confirm bool)
+}
+
+// domainIpAddr 域名发布的IP, AAAA记录使用域名的 ipv6suffix 替换获取到的IPv6地址的后缀, 不正确时记录失败并返回 false
+func domainIpAddr(recordType string, ipAddr string, domain *config.Domain) (string, bool) {
+	if recordType != "AAAA" {
+		return ipAddr, true
+	}
+	addr, err := domain.Ipv6Addr(ipAddr)
+	if err != nil {
+		util.Log("域名 %s 的IPv6后缀不正确! 异常信息: %s", domain, err)
+		domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+		domain.FailurePermanent = true
+		return "", false
+	}
+	return addr, true
+}
+
+// forEachDomain 最多 workers 个域名同时运行 fn, workers <= 1 时按顺序运行
+// 每个域名只在一个goroutine中处理, fn 中只能修改该域名的 UpdateStatus
+func forEachDomain(domains []*config.Domain, workers int, fn func(domain *config.Domain)) {
+	if workers <= 1 || len(domains) <= 1 {
+		for _, domain := range domains {
+			fn(domain)
+		}
+		return
+	}
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, domain := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(domain)
+		}()
+	}
+	wg.Wait()
+}
+
+// addUpdateRecord 没有记录时新增, 记录的值不同或 forceupdate=true 时更新
+// 存在多条记录时默认只更新第一条, records=all 时全部更新, 都成功才算成功
+func addUpdateRecord(p RecordProvider, recordType string, ipAddr string, domain *config.Domain) {
+	records, err := p.ListRecords(domain, recordType)
+	if err != nil {
+		util.Log("查询域名信息发生异常! %s", err)
+		domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+		return
+	}
+
+	if len(records) == 0 {
+		if isDryRun(p) {
+			util.Log("预演: 将新增 %s 记录 %s -> %s", recordType, domain, ipAddr)
+			domain.UpdateStatus = config.UpdatedDryRun
+			return
+		}
+		if err := p.CreateRecord(domain, recordType, ipAddr); err != nil {
+			util.Log("新增域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+			return
+		}
+		util.Log("新增域名解析 %s 成功! IP: %s", domain, ipAddr)
+		domain.UpdateStatus = config.UpdatedSuccess
+		verifyRecord(p, recordType, ipAddr, domain)
+		return
+	}
+
+	if !domain.UpdateAllRecords() {
+		cleanupDuplicates(p, recordType, domain, records[1:])
+		records = records[:1]
+	}
+	updated, failed, dryRun := 0, 0, 0
+	for _, record := range records {
+		if util.SameIP(record.Value, ipAddr) {
+			switch {
+			case record.Outdated:
+				util.Log("域名 %s 的记录与配置的属性不同, 将更新记录", domain)
+			case domain.ForceUpdate():
+				util.Log("你的IP %s 没有变化, 将强制更新域名 %s", ipAddr, domain)
+			default:
+				continue
+			}
+		}
+		if isDryRun(p) {
+			util.Log("预演: 将更新 %s 记录 %s %s -> %s", recordType, domain, record.Value, ipAddr)
+			dryRun++
+			continue
+		}
+		if err := p.UpdateRecord(domain, recordType, record, ipAddr); err != nil {
+			util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, err)
+			domain.UpdateError = err.Error()
+			failed++
+			continue
+		}
+		if domain.OldIP == "" {
+			domain.OldIP = record.Value
+		}
+		updated++
+	}
+
+	switch {
+	case failed > 0:
+		domain.UpdateStatus = config.UpdatedFailed
+	case dryRun > 0:
+		domain.UpdateStatus = config.UpdatedDryRun
+	case updated == 0:
+		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
+	default:
+		util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
+		domain.UpdateStatus = config.UpdatedSuccess
+		verifyRecord(p, recordType, ipAddr, domain)
+	}
+}
+
+// cleanupDuplicates 删除更新的记录之外的重复记录, 只删除备注为 Cleanup.Tag 的记录
+func cleanupDuplicates(p RecordProvider, recordType string, domain *config.Domain, duplicates []ProviderRecord) {
+	c, ok := p.(duplicateCleaner)
+	if !ok || len(duplicates) == 0 {
+		return
+	}
+	tag, confirm := c.cleanup()
+	if tag == "" {
+		return
+	}
+	for _, record := range duplicates {
+		if record.Comment != tag {
+			util.Log("重复的记录 %s %s 不是由ddns-go管理的, 不删除", domain, record.Value)
+			continue
+		}
+		if !confirm || isDryRun(p) {
+			util.Log("将删除重复的记录 %s %s, 设置 confirm 后才会删除", domain, record.Value)
+			continue
+		}
+		if err := p.DeleteRecord(domain, recordType, record); err != nil {
+			util.Log("删除重复的记录 %s %s 失败! 异常信息: %s", domain, record.Value, err)
+			continue
+		}
+		util.Log("删除重复的记录 %s %s 成功!", domain, record.Value)
+	}
+}
+
+// deleteRecords 删除域名该类型的所有记录, 见 config.PartialDelete
+func deleteRecords(p RecordProvider, recordType string, domains []*config.Domain) {
+	for _, domain := range domains {
+		records, err := p.ListRecords(domain, recordType)
+		if err != nil {
+			util.Log("查询域名信息发生异常! %s", err)
+			domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+			continue
+		}
+
+		for _, record := range records {
+			if isDryRun(p) {
+				util.Log("预演: 将删除 %s 记录 %s -> %s", recordType, domain, record.Value)
+				domain.UpdateStatus = config.UpdatedDryRun
+				continue
+			}
+			if err := p.DeleteRecord(domain, recordType, record); err != nil {
+				util.Log("删除域名解析 %s 失败! 异常信息: %s", domain, err)
+				domain.UpdateStatus, domain.UpdateError = config.UpdatedFailed, err.Error()
+				break
+			}
+			util.Log("删除域名解析 %s 成功! 类型: %s, IP: %s", domain, recordType, record.Value)
+			domain.UpdateStatus = config.UpdatedDeleted
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// fakeRecordProvider 内存中的记录, failID 的记录更新失败
+type fakeRecordProvider struct {
+	records []ProviderRecord
+	created []string
+	updated []string
+	deleted []string
+	failID  string
+	dryRun  bool
+	tag     string
+	confirm bool
+}
+
+func (p *fakeRecordProvider) cleanup() (string, bool) {
+	return p.tag, p.confirm
+}
+
+func (p *fakeRecordProvider) DryRun() bool {
+	return p.dryRun
+}
+
+func (p *fakeRecordProvider) ListRecords(*config.Domain, string) ([]ProviderRecord, error) {
+	return p.records, nil
+}
+
+func (p *fakeRecordProvider) CreateRecord(_ *config.Domain, _ string, value string) error {
+	p.created = append(p.created, value)
+	return nil
+}
+
+func (p *fakeRecordProvider) UpdateRecord(_ *config.Domain, _ string, record ProviderRecord, _ string) error {
+	if record.ID == p.failID {
+		return errors.New("update failed")
+	}
+	p.updated = append(p.updated, record.ID)
+	return nil
+}
+
+func (p *fakeRecordProvider) DeleteRecord(_ *config.Domain, _ string, record ProviderRecord) error {
+	p.deleted = append(p.deleted, record.ID)
+	return nil
+}
+
+// TestAddUpdateRecords 测试统一的添加或更新流程
+func TestAddUpdateRecords(t *testing.T) {
+	records := []ProviderRecord{{ID: "1", Value: "1.1.1.1"}, {ID: "2", Value: "2.2.2.2"}}
+	parse := func(domainStr string) *config.Domain {
+		dnsConf := &config.DnsConfig{}
+		dnsConf.Ipv4.Domains = []string{domainStr}
+		domains := &config.Domains{}
+		domains.GetNewIp(dnsConf)
+		return domains.Ipv4Domains[0]
+	}
+
+	tests := map[string]struct {
+		domain  string
+		records []ProviderRecord
+		failID  string
+		ipAddr  string
+		created int
+		updated int
+		status  string
+	}{
+		"没有记录时新增":      {"www.example.com", nil, "", "3.3.3.3", 1, 0, config.UpdatedSuccess},
+		"IP没有变化":       {"www.example.com", records, "", "1.1.1.1", 0, 0, string(config.UpdatedNothing)},
+		"IPv6表示方式不同":   {"www.example.com", []ProviderRecord{{ID: "1", Value: "2001:0db8:0000:0000:0000:0000:0000:0001"}}, "", "2001:db8::1", 0, 0, string(config.UpdatedNothing)},
+		"默认只更新第一条":     {"www.example.com", records, "", "3.3.3.3", 0, 1, config.UpdatedSuccess},
+		"更新所有记录":       {"www.example.com?records=all", records, "", "3.3.3.3", 0, 2, config.UpdatedSuccess},
+		"更新所有记录时有一条失败": {"www.example.com?records=all", records, "2", "3.3.3.3", 0, 1, config.UpdatedFailed},
+		"强制更新时IP相同也更新": {"www.example.com?forceupdate=true", records, "", "1.1.1.1", 0, 1, config.UpdatedSuccess},
+		"强制更新时没有记录只新增": {"www.example.com?forceupdate=true", nil, "", "1.1.1.1", 1, 0, config.UpdatedSuccess},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &fakeRecordProvider{records: tt.records, failID: tt.failID}
+			domain := parse(tt.domain)
+			addUpdateRecord(p, "A", tt.ipAddr, domain)
+			if len(p.created) != tt.created || len(p.updated) != tt.updated || string(domain.UpdateStatus) != tt.status {
+				t.Errorf("新增 %v, 更新 %v, 状态 %q", p.created, p.updated, domain.UpdateStatus)
+			}
+		})
+	}
+
+	p := &fakeRecordProvider{records: records}
+	domain := parse("www.example.com")
+	deleteRecords(p, "A", []*config.Domain{domain})
+	if len(p.deleted) != 2 || domain.UpdateStatus != config.UpdatedDeleted {
+		t.Errorf("期待删除所有记录, 得到 %v", p.deleted)
+	}
+}
+
+// TestAddUpdateRecordsDryRun 测试预演时不新增/更新/删除记录
+func TestAddUpdateRecordsDryRun(t *testing.T) {
+	records := []ProviderRecord{{ID: "1", Value: "1.1.1.1"}}
+	for _, list := range [][]ProviderRecord{nil, records} {
+		p := &fakeRecordProvider{records: list, dryRun: true}
+		domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+		addUpdateRecord(p, "A", "3.3.3.3", domain)
+		if len(p.created) != 0 || len(p.updated) != 0 || domain.UpdateStatus != config.UpdatedDryRun {
+			t.Errorf("预演时新增 %v, 更新 %v, 状态 %q", p.created, p.updated, domain.UpdateStatus)
+		}
+	}
+
+	// 强制更新时也只预演
+	p := &fakeRecordProvider{records: records, dryRun: true}
+	dnsConf := &config.DnsConfig{}
+	dnsConf.Ipv4.Domains = []string{"www.example.com?forceupdate=true"}
+	domains := &config.Domains{}
+	domains.GetNewIp(dnsConf)
+	addUpdateRecord(p, "A", "1.1.1.1", domains.Ipv4Domains[0])
+	if len(p.updated) != 0 || domains.Ipv4Domains[0].UpdateStatus != config.UpdatedDryRun {
+		t.Errorf("强制更新预演时更新 %v, 状态 %q", p.updated, domains.Ipv4Domains[0].UpdateStatus)
+	}
+
+	p = &fakeRecordProvider{records: records, dryRun: true}
+	domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+	deleteRecords(p, "A", []*config.Domain{domain})
+	if len(p.deleted) != 0 || domain.UpdateStatus != config.UpdatedDryRun {
+		t.Errorf("预演时删除 %v, 状态 %q", p.deleted, domain.UpdateStatus)
+	}
+}
+
+// TestCleanupDuplicates 测试只删除确认后由ddns-go管理的重复记录, 保留更新的记录
+func TestCleanupDuplicates(t *testing.T) {
+	records := []ProviderRecord{
+		{ID: "1", Value: "1.1.1.1", Comment: "ddns-go"},
+		{ID: "2", Value: "2.2.2.2", Comment: "ddns-go"},
+		{ID: "3", Value: "3.3.3.3", Comment: "manual"},
+	}
+
+	tests := map[string]struct {
+		tag     string
+		confirm bool
+		dryRun  bool
+		deleted []string
+	}{
+		"未设置备注时不清理":  {"", true, false, nil},
+		"未确认时不删除":    {"ddns-go", false, false, nil},
+		"预演时不删除":     {"ddns-go", true, true, nil},
+		"只删除备注相同的记录": {"ddns-go", true, false, []string{"2"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &fakeRecordProvider{records: records, tag: tt.tag, confirm: tt.confirm, dryRun: tt.dryRun}
+			domain := &config.Domain{DomainName: "example.com", SubDomain: "www"}
+			addUpdateRecord(p, "A", "4.4.4.4", domain)
+			if strings.Join(p.deleted, ",") != strings.Join(tt.deleted, ",") {
+				t.Errorf("期待删除 %v, 实际删除 %v", tt.deleted, p.deleted)
+			}
+			if !tt.dryRun && strings.Join(p.updated, ",") != "1" {
+				t.Errorf("期待只更新第一条记录, 实际更新 %v", p.updated)
+			}
+		})
+	}
+}
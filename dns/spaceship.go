@@ -53,6 +53,7 @@ func (s *Spaceship) AddUpdateDomainRecords() (domains config.Domains) {
 			}
 			if !hasUpdated {
 				util.Log("你的IP %s 没有变化, 域名 %s", ip, domain)
+				domain.UpdateStatus = config.UpdatedNothing
 			} else {
 				util.Log("更新域名解析 %s 成功! IP: %s", domain, ip)
 				domain.UpdateStatus = config.UpdatedSuccess
@@ -71,7 +72,7 @@ func (s *Spaceship) request(domain *config.Domain, method string, query url.Valu
 	req.Header = s.header
 	req.URL.RawQuery = query.Encode()
 
-	cli := util.CreateHTTPClient()
+	cli := s.domains.HTTPClient()
 	resp, err := cli.Do(req)
 	if err != nil {
 		return
@@ -216,7 +217,7 @@ func (s *Spaceship) updateRecord(recordType string, ip string, domain *config.Do
 	if err != nil {
 		return
 	}
-	if len(ips) == 1 && ips[0] == ip {
+	if len(ips) == 1 && util.SameIP(ips[0], ip) {
 		return
 	}
 	err = s.deleteRecords(recordType, domain, ips)
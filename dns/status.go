@@ -0,0 +1,126 @@
+package dns
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+// RunStatus 定时任务的运行状态, 供页面显示下次运行的倒计时
+type RunStatus struct {
+	// 正在运行
+	Running bool
+	// 上次运行完成的时间
+	LastRun time.Time
+	// 下次运行的时间, 收到外部触发时提前
+	NextRun time.Time
+	// 上次运行获取到的IPv4/IPv6
+	Ipv4Addr string
+	Ipv6Addr string
+}
+
+// DomainStatus 域名最近一次的更新结果
+type DomainStatus struct {
+	Provider   string
+	Domain     string
+	RecordType string
+	IP         string
+	Status     string
+	// FailurePermanent 失败的原因需要修改配置, 见 config.Domain.FailurePermanent
+	FailurePermanent bool
+	// 最近一次有结果的时间
+	Time time.Time
+}
+
+// Failed 最近一次更新失败
+func (s DomainStatus) Failed() bool {
+	return s.Status == config.UpdatedFailed
+}
+
+var domainStatuses = struct {
+	sync.Mutex
+	order    []string
+	statuses map[string]DomainStatus
+}{statuses: map[string]DomainStatus{}}
+
+var runStatus = struct {
+	sync.Mutex
+	RunStatus
+}{}
+
+// GetRunStatus 获得定时任务的运行状态
+func GetRunStatus() RunStatus {
+	runStatus.Lock()
+	defer runStatus.Unlock()
+	return runStatus.RunStatus
+}
+
+// setRunning 记录开始/结束运行
+func setRunning(running bool) {
+	runStatus.Lock()
+	defer runStatus.Unlock()
+	runStatus.Running = running
+	if !running {
+		runStatus.LastRun = time.Now()
+	}
+}
+
+// setNextRun 记录下次运行的时间
+func setNextRun(next time.Time) {
+	runStatus.Lock()
+	defer runStatus.Unlock()
+	runStatus.NextRun = next
+}
+
+// setRunAddrs 记录上次运行获取到的IP
+func setRunAddrs(ipv4Addr, ipv6Addr string) {
+	runStatus.Lock()
+	defer runStatus.Unlock()
+	runStatus.Ipv4Addr, runStatus.Ipv6Addr = ipv4Addr, ipv6Addr
+}
+
+// recordDomainStatus 记录每个域名本轮的结果, 本轮没有结果(如IP未改变时跳过)的保留上次的结果
+func recordDomainStatus(provider string, domains *config.Domains) {
+	now := time.Now()
+	domainStatuses.Lock()
+	defer domainStatuses.Unlock()
+
+	record := func(list []*config.Domain, recordType string, ipAddr string) {
+		for _, d := range list {
+			if d.UpdateStatus == "" {
+				continue
+			}
+			key := provider + "|" + d.String() + "|" + recordType
+			if _, ok := domainStatuses.statuses[key]; !ok {
+				domainStatuses.order = append(domainStatuses.order, key)
+			}
+			ip := ipAddr
+			if d.UpdateStatus == config.UpdatedDeleted {
+				ip = ""
+			}
+			domainStatuses.statuses[key] = DomainStatus{
+				Provider:         provider,
+				Domain:           d.String(),
+				RecordType:       recordType,
+				IP:               ip,
+				Status:           string(d.UpdateStatus),
+				FailurePermanent: d.FailurePermanent,
+				Time:             now,
+			}
+		}
+	}
+	record(domains.Ipv4Domains, "A", domains.Ipv4Addr)
+	record(domains.Ipv6Domains, "AAAA", domains.Ipv6Addr)
+}
+
+// GetDomainStatuses 获得每个域名最近一次的更新结果, 按第一次出现的顺序
+func GetDomainStatuses() []DomainStatus {
+	domainStatuses.Lock()
+	defer domainStatuses.Unlock()
+	list := make([]DomainStatus, 0, len(domainStatuses.order))
+	for _, key := range domainStatuses.order {
+		list = append(list, domainStatuses.statuses[key])
+	}
+	return list
+}
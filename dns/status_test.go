@@ -0,0 +1,38 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// TestRecordDomainStatus 测试记录每个域名最近一次的结果, 本轮没有结果时保留上次的结果
+func TestRecordDomainStatus(t *testing.T) {
+	www := &config.Domain{DomainName: "status.com", SubDomain: "www", UpdateStatus: config.UpdatedSuccess}
+	api := &config.Domain{DomainName: "status.com", SubDomain: "api", UpdateStatus: config.UpdatedFailed}
+	domains := &config.Domains{Ipv4Addr: "1.1.1.1", Ipv4Domains: []*config.Domain{www, api}, Ipv4Cache: &util.IpCache{}}
+	recordDomainStatus("status-test", domains)
+
+	www.UpdateStatus, api.UpdateStatus = config.UpdatedNothing, ""
+	domains.Ipv4Addr = "2.2.2.2"
+	recordDomainStatus("status-test", domains)
+
+	want := map[string][2]string{
+		"www.status.com": {"2.2.2.2", string(config.UpdatedNothing)},
+		"api.status.com": {"1.1.1.1", config.UpdatedFailed},
+	}
+	found := 0
+	for _, s := range GetDomainStatuses() {
+		if s.Provider != "status-test" {
+			continue
+		}
+		found++
+		if w := want[s.Domain]; s.IP != w[0] || s.Status != w[1] || s.RecordType != "A" {
+			t.Errorf("%s 期待 %v, 得到 %s %s %s", s.Domain, w, s.RecordType, s.IP, s.Status)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("期待 %d 个域名, 得到 %d 个", len(want), found)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/jeessy2/ddns-go/v6/config"
 	"github.com/jeessy2/ddns-go/v6/util"
@@ -38,6 +40,8 @@ type TencentCloudRecord struct {
 	RecordId int64 `json:"RecordId,omitempty"`
 	// DescribeRecordList 不需要 TTL
 	TTL int `json:"TTL,omitempty"`
+	// 权重, 0 到 100, 仅企业版及以上套餐支持
+	Weight *uint64 `json:"Weight,omitempty"`
 }
 
 // TencentCloudRecordListsResp 获取域名的解析记录列表返回结果
@@ -134,6 +138,7 @@ func (tc *TencentCloud) create(domain *config.Domain, recordType string, ipAddr
 		RecordLine: tc.getRecordLine(domain),
 		Value:      ipAddr,
 		TTL:        tc.TTL,
+		Weight:     tc.getWeight(domain),
 	}
 
 	var status TencentCloudStatus
@@ -153,7 +158,7 @@ func (tc *TencentCloud) create(domain *config.Domain, recordType string, ipAddr
 		util.Log("新增域名解析 %s 成功! IP: %s", domain, ipAddr)
 		domain.UpdateStatus = config.UpdatedSuccess
 	} else {
-		util.Log("新增域名解析 %s 失败! 异常信息: %s", domain, status.Response.Error.Message)
+		util.Log("新增域名解析 %s 失败! 异常信息: %s", domain, tc.errorMessage(status))
 		domain.UpdateStatus = config.UpdatedFailed
 	}
 }
@@ -161,9 +166,11 @@ func (tc *TencentCloud) create(domain *config.Domain, recordType string, ipAddr
 // modify 修改记录
 // ModifyRecord https://cloud.tencent.com/document/api/1427/56157
 func (tc *TencentCloud) modify(record TencentCloudRecord, domain *config.Domain, recordType string, ipAddr string) {
+	weight := tc.getWeight(domain)
 	// 相同不修改
-	if record.Value == ipAddr {
+	if tencentCloudRecordUnchanged(record, ipAddr, weight) {
 		util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
+		domain.UpdateStatus = config.UpdatedNothing
 		return
 	}
 	var status TencentCloudStatus
@@ -173,6 +180,7 @@ func (tc *TencentCloud) modify(record TencentCloudRecord, domain *config.Domain,
 	record.RecordLine = tc.getRecordLine(domain)
 	record.Value = ipAddr
 	record.TTL = tc.TTL
+	record.Weight = weight
 	err := tc.request(
 		"ModifyRecord",
 		record,
@@ -189,7 +197,7 @@ func (tc *TencentCloud) modify(record TencentCloudRecord, domain *config.Domain,
 		util.Log("更新域名解析 %s 成功! IP: %s", domain, ipAddr)
 		domain.UpdateStatus = config.UpdatedSuccess
 	} else {
-		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, status.Response.Error.Message)
+		util.Log("更新域名解析 %s 失败! 异常信息: %s", domain, tc.errorMessage(status))
 		domain.UpdateStatus = config.UpdatedFailed
 	}
 }
@@ -220,6 +228,35 @@ func (tc *TencentCloud) getRecordLine(domain *config.Domain) string {
 	return "默认"
 }
 
+// getWeight 获取记录权重，未设置返回 nil
+func (tc *TencentCloud) getWeight(domain *config.Domain) *uint64 {
+	params := domain.GetCustomParams()
+	if !params.Has("Weight") {
+		return nil
+	}
+	weight, err := strconv.ParseUint(params.Get("Weight"), 10, 64)
+	if err != nil || weight > 100 {
+		util.Log("域名 %s 的权重 %s 不正确, 应为 0 到 100 的整数", domain, params.Get("Weight"))
+		return nil
+	}
+	return &weight
+}
+
+// tencentCloudRecordUnchanged IP相同且设置了权重时权重也相同, 不需要修改
+func tencentCloudRecordUnchanged(record TencentCloudRecord, ipAddr string, weight *uint64) bool {
+	return util.SameIP(record.Value, ipAddr) && (weight == nil || (record.Weight != nil && *record.Weight == *weight))
+}
+
+// errorMessage 鉴权/权限错误时附加处理建议
+func (tc *TencentCloud) errorMessage(status TencentCloudStatus) string {
+	code := status.Response.Error.Code
+	if strings.HasPrefix(code, "AuthFailure") || strings.HasPrefix(code, "UnauthorizedOperation") {
+		u, _ := url.Parse(tencentCloudEndPoint)
+		return status.Response.Error.Message + " " + util.AuthHint(u.Hostname())
+	}
+	return status.Response.Error.Message
+}
+
 // request 统一请求接口
 func (tc *TencentCloud) request(action string, data interface{}, result interface{}) (err error) {
 	jsonStr := make([]byte, 0)
@@ -240,7 +277,7 @@ func (tc *TencentCloud) request(action string, data interface{}, result interfac
 
 	util.TencentCloudSigner(tc.DNS.ID, tc.DNS.Secret, req, action, string(jsonStr), util.DnsPod)
 
-	client := util.CreateHTTPClient()
+	client := tc.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	err = util.GetHTTPResponse(resp, err, result)
 
@@ -0,0 +1,63 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+)
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+// TestTencentCloudGetWeight 测试权重的解析, 不是 0 到 100 的整数时不设置权重
+func TestTencentCloudGetWeight(t *testing.T) {
+	tests := map[string]struct {
+		customParams string
+		expected     *uint64
+	}{
+		"未设置":   {"", nil},
+		"0":     {"Weight=0", uint64Ptr(0)},
+		"100":   {"Weight=100", uint64Ptr(100)},
+		"超过100": {"Weight=101", nil},
+		"负数":    {"Weight=-1", nil},
+		"非数字":   {"Weight=abc", nil},
+		"空值":    {"Weight=", nil},
+	}
+
+	tc := &TencentCloud{}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := tc.getWeight(&config.Domain{DomainName: "example.com", SubDomain: "www", CustomParams: tt.customParams})
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("期待 %v, 得到 %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestTencentCloudRecordUnchanged 测试IP相同但权重不同时仍需更新
+func TestTencentCloudRecordUnchanged(t *testing.T) {
+	tests := map[string]struct {
+		recordWeight *uint64
+		weight       *uint64
+		ipAddr       string
+		expected     bool
+	}{
+		"IP相同未设置权重":     {nil, nil, "1.1.1.1", true},
+		"IP不同":          {nil, nil, "2.2.2.2", false},
+		"IP和权重相同":       {uint64Ptr(10), uint64Ptr(10), "1.1.1.1", true},
+		"IP相同权重不同":      {uint64Ptr(10), uint64Ptr(20), "1.1.1.1", false},
+		"IP相同记录未设置权重":   {nil, uint64Ptr(10), "1.1.1.1", false},
+		"未设置权重时忽略记录的权重": {uint64Ptr(10), nil, "1.1.1.1", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			record := TencentCloudRecord{Value: "1.1.1.1", Weight: tt.recordWeight}
+			if got := tencentCloudRecordUnchanged(record, tt.ipAddr, tt.weight); got != tt.expected {
+				t.Errorf("期待 %t, 得到 %t", tt.expected, got)
+			}
+		})
+	}
+}
@@ -199,7 +199,7 @@ func (tr *TrafficRoute) create(zoneID int, domain *config.Domain, recordType, ip
 
 // modify 修改解析记录
 func (tr *TrafficRoute) modify(record TrafficRouteMeta, domain *config.Domain, ipAddr string) {
-	if record.Value == ipAddr {
+	if util.SameIP(record.Value, ipAddr) {
 		util.Log("IP %s 没有变化，域名 %s", ipAddr, domain)
 		domain.UpdateStatus = config.UpdatedNothing
 		return
@@ -282,7 +282,7 @@ func (tr *TrafficRoute) request(method string, action string, data interface{},
 		return err
 	}
 
-	client := util.CreateHTTPClient()
+	client := tr.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	return util.GetHTTPResponse(resp, err, result)
 }
@@ -0,0 +1,60 @@
+package dns
+
+import (
+	"context"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// defaultTriggerDebounce 外部触发后等待的默认时间
+const defaultTriggerDebounce = 5 * time.Second
+
+// triggerCh 外部触发(信号/HTTP), 运行中收到的触发会在本次运行结束后处理
+var triggerCh = make(chan struct{}, 1)
+
+// Trigger 外部通知IP可能已改变, 如 PPP 重新拨号后调用
+func Trigger(source string) {
+	util.Log("收到外部触发: %s", source)
+	select {
+	case triggerCh <- struct{}{}:
+	default:
+	}
+}
+
+// waitNextRun 等待下次运行, 收到外部触发时提前运行
+// 触发后等待 debounce 时间, 期间再次触发则重新计时, 多次触发只运行一次
+// ctx 取消时立即返回
+func waitNextRun(ctx context.Context, delay, debounce time.Duration) {
+	setNextRun(time.Now().Add(delay))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return
+	case <-ctx.Done():
+		return
+	case <-triggerCh:
+	}
+
+	for {
+		setNextRun(time.Now().Add(debounce))
+		select {
+		case <-triggerCh:
+		case <-time.After(debounce):
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// triggerDebounce 配置文件中的 TriggerDebounce
+func triggerDebounce() time.Duration {
+	if conf, err := config.GetConfigCached(); err == nil && conf.TriggerDebounce > 0 {
+		return time.Duration(conf.TriggerDebounce) * time.Second
+	}
+	return defaultTriggerDebounce
+}
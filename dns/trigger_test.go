@@ -0,0 +1,50 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestWaitNextRun 测试外部触发提前运行, 以及连续触发只运行一次
+func TestWaitNextRun(t *testing.T) {
+	start := time.Now()
+	waitNextRun(context.Background(), 20*time.Millisecond, time.Second)
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("未触发时应等待 delay, 实际 %s", elapsed)
+	}
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			Trigger("test")
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	start = time.Now()
+	waitNextRun(context.Background(), time.Hour, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond || elapsed > time.Second {
+		t.Errorf("连续触发后应在最后一次触发后等待 debounce, 实际 %s", elapsed)
+	}
+
+	select {
+	case <-triggerCh:
+		t.Error("debounce 期间的触发不应再次运行")
+	default:
+	}
+}
+
+// TestWaitNextRunStatus 测试等待时记录下次运行的时间, 触发后提前
+func TestWaitNextRunStatus(t *testing.T) {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		if next := time.Until(GetRunStatus().NextRun); next < 50*time.Minute {
+			t.Errorf("等待时下次运行应在约1小时后, 实际 %s", next)
+		}
+		Trigger("test")
+		time.Sleep(10 * time.Millisecond)
+		if next := time.Until(GetRunStatus().NextRun); next > 50*time.Millisecond {
+			t.Errorf("触发后下次运行应提前到 debounce 后, 实际 %s", next)
+		}
+	}()
+	waitNextRun(context.Background(), time.Hour, 50*time.Millisecond)
+}
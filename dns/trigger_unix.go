@@ -0,0 +1,20 @@
+//go:build !windows
+
+package dns
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyTrigger 收到 SIGUSR1 时触发更新, 如 kill -USR1 $(pidof ddns-go)
+func notifyTrigger() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1)
+	go func() {
+		for range sig {
+			Trigger("SIGUSR1")
+		}
+	}()
+}
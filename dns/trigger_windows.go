@@ -0,0 +1,6 @@
+//go:build windows
+
+package dns
+
+// notifyTrigger Windows 不支持 SIGUSR1, 可使用 /trigger 接口
+func notifyTrigger() {}
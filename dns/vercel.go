@@ -91,7 +91,7 @@ func (v *Vercel) addUpdateDomainRecords(recordType string) {
 		if targetRecord == nil {
 			err = v.createRecord(domain, recordType, ipAddr)
 		} else {
-			if strings.ToLower(targetRecord.Value) == ipAddr {
+			if util.SameIP(targetRecord.Value, ipAddr) {
 				util.Log("你的IP %s 没有变化, 域名 %s", ipAddr, domain)
 				domain.UpdateStatus = config.UpdatedNothing
 				continue
@@ -170,7 +170,7 @@ func (v *Vercel) request(method, api string, data, result interface{}) (err erro
 	req.Header.Set("Authorization", "Bearer "+v.DNS.Secret)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := util.CreateHTTPClient()
+	client := v.Domains.HTTPClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -0,0 +1,70 @@
+package dns
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// zoneMinTTLs 服务商拒绝TTL时返回的最小TTL, 按服务商和根域名缓存
+var zoneMinTTLs = struct {
+	sync.Mutex
+	ttls map[string]int
+}{ttls: map[string]int{}}
+
+var (
+	// 错误信息中的 Message, 避免匹配到 RequestId 中的数字
+	ttlErrorMessageRegexp = regexp.MustCompile(`"Message"\s*:\s*"([^"]*)"`)
+	// 30-86400 / 30~86400 / 600 to 86400
+	ttlRangeRegexp = regexp.MustCompile(`(\d+)\s*(?:-|~|to)\s*\d+`)
+	// minimum 600 / at least 600 / 不能小于600 / 最小600
+	ttlMinRegexp = regexp.MustCompile(`(?i)(?:min(?:imum)?|at least|not less than|不能小于|不小于|最小(?:值)?为?)\D{0,10}?(\d+)`)
+)
+
+// parseMinTTL 从服务商拒绝TTL的错误信息中解析支持的最小TTL
+func parseMinTTL(err error) (int, bool) {
+	msg := err.Error()
+	if m := ttlErrorMessageRegexp.FindStringSubmatch(msg); m != nil {
+		msg = m[1]
+	}
+	if !strings.Contains(strings.ToUpper(msg), "TTL") {
+		return 0, false
+	}
+	for _, re := range []*regexp.Regexp{ttlMinRegexp, ttlRangeRegexp} {
+		if m := re.FindStringSubmatch(msg); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n > 1 {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// zoneMinTTL 服务商返回过根域名的最小TTL且大于 ttl 时使用该最小TTL
+func zoneMinTTL(provider, domainName, ttl string) string {
+	zoneMinTTLs.Lock()
+	minTTL, ok := zoneMinTTLs.ttls[provider+"|"+domainName]
+	zoneMinTTLs.Unlock()
+
+	if n, err := strconv.Atoi(ttl); ok && (err != nil || n < minTTL) {
+		return strconv.Itoa(minTTL)
+	}
+	return ttl
+}
+
+// learnMinTTL 服务商因TTL太小拒绝请求时缓存返回的最小TTL, 之后的请求使用该TTL
+// 最小TTL大于本次请求的 ttl 时返回 true, 调用方可使用新的TTL重试一次
+func learnMinTTL(provider, domainName, ttl string, err error) bool {
+	minTTL, ok := parseMinTTL(err)
+	if n, _ := strconv.Atoi(ttl); !ok || minTTL <= n {
+		return false
+	}
+	zoneMinTTLs.Lock()
+	zoneMinTTLs.ttls[provider+"|"+domainName] = minTTL
+	zoneMinTTLs.Unlock()
+	util.Log("DNS服务商 %s 要求 %s 的TTL不小于 %d, 之后将使用该TTL", provider, domainName, minTTL)
+	return true
+}
@@ -0,0 +1,49 @@
+package dns
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseMinTTL 测试从服务商的错误信息中解析最小TTL
+func TestParseMinTTL(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want int
+		ok   bool
+	}{
+		{`返回内容: {"RequestId":"CB1A380B-09F0-41BB-A198-72F8FD6DA2FE","Code":"InvalidParameter.TTL","Message":"The TTL is invalid. Valid values: 1, 30-86400."} ,返回状态码: 400`, 30, true},
+		{`{"Code":"InvalidTTL","Message":"TTL must be at least 600 for the current plan"}`, 600, true},
+		{`{"Code":"InvalidParameter","Message":"TTL不能小于 600"}`, 600, true},
+		{`{"Code":"InvalidParameter","Message":"The specified Value is invalid."}`, 0, false},
+		{`{"RequestId":"A198-72F8","Code":"InvalidParameter.TTL","Message":"The TTL is invalid."}`, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseMinTTL(errors.New(tt.msg))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseMinTTL(%s) = %d, %v, 期待 %d, %v", tt.msg, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestLearnMinTTL 测试只在最小TTL大于请求的TTL时缓存, 之后使用缓存的TTL
+func TestLearnMinTTL(t *testing.T) {
+	err := errors.New(`{"Message":"TTL must be at least 600"}`)
+	if learnMinTTL("test", "learn.example.com", "600", err) {
+		t.Error("TTL不小于最小TTL时不应重试")
+	}
+	if got := zoneMinTTL("test", "learn.example.com", "60"); got != "60" {
+		t.Errorf("未缓存时应使用原来的TTL, 得到 %s", got)
+	}
+	if !learnMinTTL("test", "learn.example.com", "60", err) {
+		t.Error("TTL小于最小TTL时应重试")
+	}
+	for ttl, want := range map[string]string{"60": "600", "1": "600", "3600": "3600"} {
+		if got := zoneMinTTL("test", "learn.example.com", ttl); got != want {
+			t.Errorf("TTL %s 期待 %s, 得到 %s", ttl, want, got)
+		}
+	}
+	if got := zoneMinTTL("test", "other.example.com", "60"); got != "60" {
+		t.Errorf("其它根域名不应使用缓存的TTL, 得到 %s", got)
+	}
+}
@@ -11,6 +11,6 @@ require (
 )
 
 require (
-	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/sys v0.39.0
 	golang.org/x/text v0.32.0
 )
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"errors"
 	"flag"
@@ -10,9 +11,12 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/jeessy2/ddns-go/v6/config"
@@ -46,6 +50,15 @@ var serviceType = flag.String("s", "", "Service management (install|uninstall|re
 // 配置文件路径
 var configFilePath = flag.String("c", util.GetConfigFilePathDefault(), "Custom configuration file path")
 
+// 远程配置
+var configURL = flag.String("config-url", "", "Fetch configuration from the URL, the configuration file is used as the cache")
+
+// 远程配置的请求头
+var configHeaders headerFlags
+
+// 远程配置的检查频率(秒)
+var configInterval = flag.Int("config-interval", 0, "Check frequency of the remote configuration(seconds), 0 only fetches at startup")
+
 // Web 服务
 var noWebService = flag.Bool("noweb", false, "No web service")
 
@@ -61,6 +74,9 @@ var newPassword = flag.String("resetPassword", "", "Reset password to the one en
 // 后台运行
 var daemonize = flag.Bool("d", false, "Run in background (daemon/detached)")
 
+// 只运行一次
+var once = flag.Bool("once", false, "Update once and exit")
+
 //go:embed static
 var staticEmbeddedFiles embed.FS
 
@@ -70,6 +86,22 @@ var faviconEmbeddedFile embed.FS
 // version
 var version = "DEV"
 
+// headerFlags 可多次指定的请求头
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+func init() {
+	flag.Var(&configHeaders, "config-header", "Header of the remote configuration request, can be repeated, example: \"Authorization: Bearer xxx\"")
+}
+
 func main() {
 	flag.Parse()
 	if *versionFlag {
@@ -131,7 +163,7 @@ func main() {
 		restartService()
 	default:
 		if util.IsRunInDocker() || os.Getenv("DDNS_GO_DAEMON") == "1" {
-			run()
+			runUntilSignal()
 		} else {
 			s := getService()
 			status, _ := s.Status()
@@ -146,18 +178,53 @@ func main() {
 				default:
 					util.Log("可使用 sudo ./ddns-go -s install 安装服务运行")
 				}
-				run()
+				runUntilSignal()
 			}
 		}
 	}
 }
 
-func run() {
+// runUntilSignal 非服务方式运行, 收到 SIGINT/SIGTERM 后完成当前一轮更新再退出
+func runUntilSignal() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	run(ctx)
+}
+
+// run 运行直到 ctx 取消, 退出前发送汇总结果
+func run(ctx context.Context) {
+	defer shutdown()
+
+	// 获取远程配置
+	if *configURL != "" {
+		fetchRemoteConfig()
+	}
 	// 兼容之前的配置文件
 	conf, _ := config.GetConfigCached()
 	conf.CompatibleConfig()
 	// 初始化语言
 	util.InitLogLang(conf.Lang)
+	// 日志写入文件
+	if conf.LogFilePath != "" {
+		util.SetLogFile(conf.LogFilePath, conf.LogMaxSize, conf.LogRotateHours, conf.LogMaxBackups)
+	}
+	// 配置文件中的自定义DNS
+	if *customDNS == "" && conf.Resolver != "" {
+		*customDNS = conf.Resolver
+		util.SetDNS(*customDNS)
+	}
+	// 配置文件中的代理, 不正确时退出, 避免不经过代理直接请求
+	if err := util.SetProxy(conf.Proxy); err != nil {
+		log.Fatalln(err)
+	}
+
+	// 只运行一次, 退出前发送汇总结果
+	if *once {
+		util.InitBackupDNS(*customDNS, conf.Lang)
+		util.WaitInternet(dns.Addresses)
+		dns.RunOnce()
+		return
+	}
 
 	if !*noWebService {
 		go func() {
@@ -177,8 +244,45 @@ func run() {
 	// 等待网络连接
 	util.WaitInternet(dns.Addresses)
 
+	// 定时检查远程配置
+	if *configURL != "" && *configInterval > 0 {
+		go watchRemoteConfig(time.Duration(*configInterval) * time.Second)
+	}
+
 	// 定时运行
-	dns.RunTimer(time.Duration(*every) * time.Second)
+	dns.RunTimer(ctx, time.Duration(*every)*time.Second)
+}
+
+// fetchRemoteConfig 获取远程配置, 失败时使用本地缓存的配置文件
+func fetchRemoteConfig() bool {
+	changed, err := config.FetchRemoteConfig(*configURL, configHeaders)
+	if err != nil {
+		util.Log("获取远程配置 %s 失败, 将使用本地配置文件 %s. 异常信息: %s", *configURL, util.GetConfigFilePath(), err)
+		return false
+	}
+	if changed {
+		util.Log("远程配置已更新, 已保存到 %s", util.GetConfigFilePath())
+	}
+	return changed
+}
+
+// watchRemoteConfig 定时检查远程配置, 有变化时触发定时任务按新配置运行
+func watchRemoteConfig(interval time.Duration) {
+	for range time.Tick(interval) {
+		if fetchRemoteConfig() {
+			util.ForceCompareGlobal = true
+			dns.Trigger("remote config")
+		}
+	}
+}
+
+// shutdown 退出前调用Webhook发送汇总结果, 输出合并的日志并关闭日志文件
+func shutdown() {
+	if conf, err := config.GetConfigCached(); err == nil {
+		config.ExecShutdownWebhook(&conf)
+	}
+	util.FlushLogRepeat()
+	util.CloseLogFile()
 }
 
 func staticFsFunc(writer http.ResponseWriter, request *http.Request) {
@@ -198,9 +302,19 @@ func runWebServer() error {
 
 	http.HandleFunc("/", web.Auth(web.Writing))
 	http.HandleFunc("/save", web.Auth(web.Save))
+	http.HandleFunc("/compare", web.Auth(web.Compare))
+	http.HandleFunc("/rawConfig", web.Auth(web.RawConfig))
+	http.HandleFunc("/backup", web.Auth(web.Backup))
+	http.HandleFunc("/restore", web.Auth(web.Restore))
+	http.HandleFunc("/trigger", web.AuthAssert(web.Trigger))
+	http.HandleFunc("/metrics", web.AuthAssert(web.Metrics))
 	http.HandleFunc("/logs", web.Auth(web.Logs))
+	http.HandleFunc("/status", web.Auth(web.Status))
+	http.HandleFunc("/statusPage", web.AuthAssert(web.StatusPage))
 	http.HandleFunc("/clearLog", web.Auth(web.ClearLog))
+	http.HandleFunc("/history", web.Auth(web.History))
 	http.HandleFunc("/webhookTest", web.Auth(web.WebhookTest))
+	http.HandleFunc("/notifyTest", web.Auth(web.NotifyTest))
 	http.HandleFunc("/logout", web.Auth(web.Logout))
 
 	util.Log("监听 %s", *listen)
@@ -245,18 +359,33 @@ func runAsDaemon() error {
 	return proc.Release()
 }
 
-type program struct{}
+// serviceStopTimeout 停止服务时等待当前一轮更新完成的最长时间
+const serviceStopTimeout = 10 * time.Second
+
+type program struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
 
 func (p *program) Start(s service.Service) error {
 	// Start should not block. Do the actual work async.
-	go p.run()
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel, p.done = cancel, make(chan struct{})
+	go p.run(ctx)
 	return nil
 }
-func (p *program) run() {
-	run()
+func (p *program) run(ctx context.Context) {
+	defer close(p.done)
+	run(ctx)
 }
 func (p *program) Stop(s service.Service) error {
 	// Stop should not block. Return with a few seconds.
+	// 与非服务方式相同, 由 run 完成当前一轮更新后发送汇总结果
+	p.cancel()
+	select {
+	case <-p.done:
+	case <-time.After(serviceStopTimeout):
+	}
 	return nil
 }
 
@@ -298,6 +427,13 @@ func getService() service.Service {
 		svcConfig.Arguments = append(svcConfig.Arguments, "-dns", *customDNS)
 	}
 
+	if *configURL != "" {
+		svcConfig.Arguments = append(svcConfig.Arguments, "-config-url", *configURL, "-config-interval", strconv.Itoa(*configInterval))
+		for _, header := range configHeaders {
+			svcConfig.Arguments = append(svcConfig.Arguments, "-config-header", header)
+		}
+	}
+
 	prg := &program{}
 	s, err := service.New(prg, svcConfig)
 	if err != nil {
@@ -7,9 +7,16 @@ const DNS_PROVIDERS = {
     idLabel: "AccessKey ID",
     secretLabel: "AccessKey Secret",
     helpHtml: {
-      "en": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>Create AccessKey</a>",
-      "zh-cn": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>创建 AccessKey</a>",
-    }
+      "en": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>Create AccessKey</a>. On ECS, enter <code>ecs-ram-role</code> as AccessKey ID to use the instance RAM role, AccessKey Secret is the role name (optional)",
+      "zh-cn": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak?spm=5176.12818093.nav-right.dak.488716d0mHaMgg'>创建 AccessKey</a>。在ECS上可将 AccessKey ID 填写为 <code>ecs-ram-role</code> 使用实例RAM角色, AccessKey Secret 填写角色名称(可不填)",
+    },
+    // 可选的API地址, value 为空时使用默认地址
+    endpoints: [
+      { name: { "en": "Default (alidns.aliyuncs.com)", "zh-cn": "默认 (alidns.aliyuncs.com)" }, value: "" },
+      { name: { "en": "China (Hangzhou)", "zh-cn": "华东1（杭州）" }, value: "https://alidns.cn-hangzhou.aliyuncs.com/" },
+      { name: { "en": "China (Hong Kong)", "zh-cn": "中国香港" }, value: "https://alidns.cn-hongkong.aliyuncs.com/" },
+      { name: { "en": "Singapore", "zh-cn": "新加坡" }, value: "https://alidns.ap-southeast-1.aliyuncs.com/" },
+    ],
   },
   tencentcloud: {
     name: {
@@ -45,6 +52,18 @@ const DNS_PROVIDERS = {
       "zh-cn": "<a target='_blank' href='https://dash.cloudflare.com/profile/api-tokens'>创建令牌 -> 编辑区域 DNS (使用模板)</a>",
     }
   },
+  cloudflarelb: {
+    name: {
+      "en": "Cloudflare Load Balancer",
+      "zh-cn": "Cloudflare 负载均衡",
+    },
+    idLabel: "Account ID",
+    secretLabel: "Token",
+    helpHtml: {
+      "en": "Updates the address of a pool origin. Account ID is optional, user level pools are used if empty. Domain format: <code>home.example.com?pool=POOL_ID&origin=ORIGIN_NAME</code>, the domain is used as origin name if origin is empty. <a target='_blank' href='https://dash.cloudflare.com/profile/api-tokens'>Create Token -> Load Balancing: Monitors and Pools Edit</a>",
+      "zh-cn": "更新负载均衡池中源站的地址。Account ID 可不填, 为空时使用用户级的负载均衡池。域名格式: <code>home.example.com?pool=池ID&origin=源站名称</code>, origin 为空时使用域名作为源站名称。<a target='_blank' href='https://dash.cloudflare.com/profile/api-tokens'>创建令牌 -> 负载均衡: 监视器和池 编辑</a>",
+    }
+  },
   huaweicloud: {
     name: {
       "en": "Huawei",
@@ -256,6 +275,52 @@ const DNS_PROVIDERS = {
       "zh-cn": "<a target='_blank' href='https://my.nsone.net/#/account/settings/keys'>创建 API 密钥</a>",
     }
   },
+  github: {
+    name: {
+      "en": "GitHub Gist/File",
+      "zh-cn": "GitHub Gist/文件",
+    },
+    idLabel: "Gist/File",
+    secretLabel: "Token",
+    helpHtml: {
+      "en": "Writes the IPs as JSON to a Gist or repository file for other scripts. Gist/File: <code>gist:GIST_ID/FILE_NAME</code> or <code>OWNER/REPO/PATH</code>. <a target='_blank' href='https://github.com/settings/tokens'>Create Token</a> with gist or contents write permission",
+      "zh-cn": "将IP以JSON写入Gist或仓库中的文件, 供其它脚本读取。Gist/文件: <code>gist:Gist ID/文件名</code> 或 <code>用户名/仓库/文件路径</code>。<a target='_blank' href='https://github.com/settings/tokens'>创建令牌</a>, 需要 gist 或 contents 写入权限",
+    },
+    extParamLabel: "Branch",
+    extParamHelpHtml: {
+      "en": "Optional. Branch of the repository file, the default branch is used if empty",
+      "zh-cn": "可选项，仓库文件所在的分支，为空时使用默认分支"
+    }
+  },
+  external: {
+    name: {
+      "en": "External program",
+      "zh-cn": "外部程序",
+    },
+    idLabel: "Command",
+    secretLabel: "Secret",
+    helpHtml: {
+      "en": "Runs the command for each record to support other DNS providers, the record is passed as JSON on stdin and Secret in the environment variable <code>DDNS_SECRET</code>. See <a target='_blank' href='https://github.com/jeessy2/ddns-go#external-program'>External program</a>",
+      "zh-cn": "每条记录执行一次命令, 用于支持其它DNS服务商, 记录以JSON通过标准输入传递, Secret 通过环境变量 <code>DDNS_SECRET</code> 传递。参考 <a target='_blank' href='https://github.com/jeessy2/ddns-go#外部程序'>外部程序</a>",
+    }
+  },
+  nsupdate: {
+    name: {
+      "en": "RFC 2136 (nsupdate)",
+      "zh-cn": "RFC 2136 (nsupdate)",
+    },
+    idLabel: "TSIG Key",
+    secretLabel: "TSIG Secret",
+    helpHtml: {
+      "en": "Updates self-hosted DNS such as BIND by RFC 2136 dynamic update. TSIG Key: key name, optionally prefixed with the algorithm like <code>hmac-sha512:ddns-key</code> (default hmac-sha256), leave empty to send unsigned updates. TSIG Secret: base64 secret. The zone is the root domain, use <code>www:home.example.com</code> to specify it. See <a target='_blank' href='https://github.com/jeessy2/ddns-go#rfc-2136-nsupdate'>RFC 2136</a>",
+      "zh-cn": "通过 RFC 2136 动态更新修改BIND等自建DNS。TSIG Key: 密钥名称, 可加上算法前缀如 <code>hmac-sha512:ddns-key</code>(默认 hmac-sha256), 为空时不签名。TSIG Secret: base64编码的密钥。区域为根域名, 可通过 <code>www:home.example.com</code> 指定。参考 <a target='_blank' href='https://github.com/jeessy2/ddns-go#rfc-2136-nsupdate'>RFC 2136</a>",
+    },
+    extParamLabel: "DNS Server",
+    extParamHelpHtml: {
+      "en": "Address of the authoritative DNS server, e.g. <code>ns1.example.com</code> / <code>192.168.1.53:53</code>",
+      "zh-cn": "权威DNS服务器的地址, 如 <code>ns1.example.com</code> / <code>192.168.1.53:53</code>"
+    }
+  },
   esa: {
     name: {
       "en": "Alibaba Cloud ESA",
@@ -264,9 +329,13 @@ const DNS_PROVIDERS = {
     idLabel: "AccessKey ID",
     secretLabel: "AccessKey Secret",
     helpHtml: {
-      "en": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak'>Create AccessKey</a>",
-      "zh-cn": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak'>创建 AccessKey</a>",
-    }
+      "en": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak'>Create AccessKey</a>. On ECS, enter <code>ecs-ram-role</code> as AccessKey ID to use the instance RAM role, AccessKey Secret is the role name (optional)",
+      "zh-cn": "<a target='_blank' href='https://ram.console.aliyun.com/manage/ak'>创建 AccessKey</a>。在ECS上可将 AccessKey ID 填写为 <code>ecs-ram-role</code> 使用实例RAM角色, AccessKey Secret 填写角色名称(可不填)",
+    },
+    endpoints: [
+      { name: { "en": "China (Hangzhou)", "zh-cn": "中国内地（杭州）" }, value: "" },
+      { name: { "en": "Singapore (International)", "zh-cn": "新加坡（国际站）" }, value: "https://esa.ap-southeast-1.aliyuncs.com/" },
+    ],
   },
 };
 
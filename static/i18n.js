@@ -7,6 +7,38 @@ const I18N_MAP = {
     'en': 'Save',
     'zh-cn': '保存'
   },
+  'Raw config': {
+    'en': 'Raw config',
+    'zh-cn': '原始配置'
+  },
+  'Backup': {
+    'en': 'Backup',
+    'zh-cn': '备份'
+  },
+  'Restore': {
+    'en': 'Restore',
+    'zh-cn': '恢复'
+  },
+  'Export history': {
+    'en': 'Export history (CSV)',
+    'zh-cn': '导出更新历史(CSV)'
+  },
+  'Test notification': {
+    'en': 'Test notification',
+    'zh-cn': '测试通知'
+  },
+  'Redact secrets': {
+    'en': 'Without ID/Secret/password',
+    'zh-cn': '不包含ID/Secret/密码'
+  },
+  'Cancel': {
+    'en': 'Cancel',
+    'zh-cn': '取消'
+  },
+  'rawConfigHelp': {
+    'en': 'The full config in JSON, including fields not shown in the form. ID/Secret are masked and kept unchanged if not modified; leave Password empty to keep the current password',
+    'zh-cn': 'JSON格式的完整配置, 包括表单中没有的字段。ID/Secret隐藏显示, 未修改时保持不变; Password为空时不修改密码'
+  },
   'Config:': {
     'en': 'Config:',
     'zh-cn': '配置切换:'
@@ -99,15 +131,53 @@ const I18N_MAP = {
     'en': `
       Enter one domain per line.
       If the domain is unregistrable, manually separate it into a subdomain and a root domain by using a colon. e.g. <code>www:domain.example.com</code><br />
+      Prefix with <code>+</code> to also update www, e.g. <code>+example.com</code>. The subdomains can be changed with <code>expandsubdomains</code> in the config file<br />
+      Add <code>?ipfamily=6</code> to only update AAAA for a domain, e.g. when the same list is used for IPv4 and IPv6<br />
+      Add <code>?healthcheck=443</code> to only publish the new IP when its TCP port 443 can be connected<br />
+      Add <code>?cron=* 2-4 * * *</code> to only update a domain when the time matches the cron expression, e.g. between 2:00 and 4:59<br />
+      Add <code>?records=all</code> to update all records of the same name and type (e.g. round-robin A records) instead of only the first one. Currently supports Alibaba Cloud ESA<br />
+      Add <code>?forceupdate=true</code> to update the record even if the IP is the same, e.g. to rewrite a TTL changed by another tool. Currently supports Alibaba Cloud ESA<br />
+      Add <code>?state=absent</code> to keep a domain without the record: it is deleted if present and never created. Currently supports Cloudflare and Alibaba Cloud ESA<br />
+      Add <code>?ipv6suffix=::1a2b:3cff:fe4d:5e6f</code> to publish another host behind the router: the detected IPv6 prefix (<code>ipv6prefixlen</code>, default 64) plus the suffix. Currently supports Alibaba Cloud ESA<br />
+      Add <code>?missing=delete</code> to delete the record when no IP of that type can be obtained 3 times in a row. Currently supports Cloudflare and Alibaba Cloud ESA<br />
+      Add <code>?BizName=api</code> to Alibaba Cloud ESA domains to only update the record of that business scenario, records of other BizNames are left unchanged<br />
+      Add <code>?Data.Priority=10</code> to Alibaba Cloud ESA domains to set extra fields of the record Data besides the IP. Numbers and true/false are sent as is, the record is also updated when only these fields differ<br />
 
       Support for <a target="blank" href="https://github.com/jeessy2/ddns-go/wiki/传递自定义参数">custom parameters</a> (Simplified Chinese)
     `,
     'zh-cn': `
       每行一个域名。
       如果域名不可注册，请使用冒号手动将其分为子域名和根域名。如 <code>www:domain.example.com</code><br />
+      以 <code>+</code> 开头时同时更新 www，如 <code>+example.com</code>，可在配置文件中通过 <code>expandsubdomains</code> 修改<br />
+      添加 <code>?ipfamily=6</code> 时该域名只更新AAAA记录，适合IPv4和IPv6使用相同的域名列表<br />
+      添加 <code>?healthcheck=443</code> 时新IP的TCP端口443能连接才发布<br />
+      添加 <code>?cron=* 2-4 * * *</code> 时只在满足cron表达式的时间更新该域名，如2:00到4:59之间<br />
+      添加 <code>?records=all</code> 时同名同类型的多条记录(如轮询的A记录)全部更新，默认只更新第一条，目前支持阿里云ESA<br />
+      添加 <code>?forceupdate=true</code> 时IP相同也更新记录，如重新写入被其它工具修改的TTL，目前支持阿里云ESA<br />
+      添加 <code>?state=absent</code> 时不更新该域名，存在记录时删除，保持没有该记录，目前支持Cloudflare和阿里云ESA<br />
+      添加 <code>?ipv6suffix=::1a2b:3cff:fe4d:5e6f</code> 时使用获取到的IPv6前缀(<code>ipv6prefixlen</code>，默认64)加上该后缀，用于发布路由器后的其它设备，目前支持阿里云ESA<br />
+      添加 <code>?missing=delete</code> 时连续3次未获取到该类型的IP则删除该域名的记录，目前支持Cloudflare和阿里云ESA<br />
+      阿里云ESA添加 <code>?BizName=api</code> 时只更新该业务场景的记录，不修改其它BizName的同名记录<br />
+      阿里云ESA添加 <code>?Data.Priority=10</code> 时在记录的Data中设置IP以外的字段，数字和true/false按原样发送，只有这些字段不同时也会更新记录<br />
       支持<a target="blank" href="https://github.com/jeessy2/ddns-go/wiki/传递自定义参数">自定义参数</a>
     `
   },
+  'Endpoint': {
+    'en': 'Endpoint',
+    'zh-cn': 'API地址'
+  },
+  'endpointHelp': {
+    'en': 'The API endpoint of the DNS provider, select the region of your account. Choose <code>Custom</code> to enter another endpoint',
+    'zh-cn': 'DNS服务商的API地址，请选择帐号所在的地域。选择<code>自定义</code>可填写其它地址'
+  },
+  'Manual IP': {
+    'en': 'Manual IP',
+    'zh-cn': '手动设置IP'
+  },
+  'ManualIPHelp': {
+    'en': 'Optional. When set, the IP is no longer detected and this IP is used for updates. Clear it to resume detection',
+    'zh-cn': '可选项。设置后将不再获取IP，直接使用该IP更新，清空后恢复获取IP'
+  },
   'Regular exp.': {
     'en': 'Regular exp.',
     'zh-cn': '匹配正则表达式'
@@ -152,18 +222,35 @@ const I18N_MAP = {
       >Click to get more info</a
       ><br />
       Support variables #{ipv4Addr}, #{ipv4Result},
-      #{ipv4Domains}, #{ipv6Addr}, #{ipv6Result}, #{ipv6Domains}
+      #{ipv4Domains}, #{ipv6Addr}, #{ipv6Result}, #{ipv6Domains}, #{source},
+      #{ipv4OldAddr}, #{ipv6OldAddr}, #{ipv4Error}, #{ipv6Error}
     `,
     'zh-cn': `
       <a target="blank" href="https://github.com/jeessy2/ddns-go#webhook">点击参考官方 Webhook 说明</a>
       <br />
-      支持的变量 #{ipv4Addr}, #{ipv4Result}, #{ipv4Domains}, #{ipv6Addr}, #{ipv6Result}, #{ipv6Domains}
+      支持的变量 #{ipv4Addr}, #{ipv4Result}, #{ipv4Domains}, #{ipv6Addr}, #{ipv6Result}, #{ipv6Domains}, #{source}, #{ipv4OldAddr}, #{ipv6OldAddr}, #{ipv4Error}, #{ipv6Error}
     `
   },
   'WebhookRequestBodyHelp': {
     'en': 'If RequestBody is empty, it is a GET request, otherwise it is a POST request. Supported variables are the same as above',
     'zh-cn': '如果 RequestBody 为空, 则为 GET 请求, 否则为 POST 请求。支持的变量同上'
   },
+  'WebhookRequestBodyEventHelp': {
+    'en': 'RequestBody used for this event only, the RequestBody above is used if empty. Supports the variable #{event}: changed, updateFailed, detectFailed',
+    'zh-cn': '仅在此事件时使用的 RequestBody, 为空时使用上面的 RequestBody。支持变量 #{event}: changed, updateFailed, detectFailed'
+  },
+  'Changed': {
+    'en': 'IP changed',
+    'zh-cn': 'IP已改变'
+  },
+  'UpdateFailed': {
+    'en': 'Update failed',
+    'zh-cn': '更新失败'
+  },
+  'DetectFailed': {
+    'en': 'Get IP failed',
+    'zh-cn': '获取IP失败'
+  },
   'WebhookHeadersHelp': {
     'en': 'One header per line, such as: Authorization: Bearer API_KEY',
     'zh-cn': '一行一个Header, 如: Authorization: Bearer API_KEY'
@@ -196,6 +283,10 @@ const I18N_MAP = {
     'en': "If you do not specify a matching regular expression, the first IPv6 address will be used by default",
     'zh-cn': "如不指定匹配正则表达式，将默认使用第一个 IPv6 地址"
   },
+  "DefaultRouteInterface": {
+    'en': "Default route (WAN)",
+    'zh-cn': "默认路由网卡 (WAN口)"
+  },
   "Ipv4CmdHelp": {
     'en': "Get IPv4 through command, only use the first matching IPv4 address of standard output(stdout). Such as: ip -4 addr show eth1",
     'zh-cn': `
@@ -7,7 +7,16 @@ import (
 )
 
 // AliyunSigner AliyunSigner
-func AliyunSigner(accessKeyID, accessSecret string, params *url.Values) {
+// accessKeyID 为 AliyunEcsRamRole 时使用ECS实例RAM角色的临时凭证
+func AliyunSigner(accessKeyID, accessSecret string, params *url.Values) error {
+	accessKeyID, accessSecret, securityToken, err := AliyunCredential(accessKeyID, accessSecret)
+	if err != nil {
+		return err
+	}
+	if securityToken != "" {
+		params.Set("SecurityToken", securityToken)
+	}
+
 	// 公共参数
 	params.Set("SignatureMethod", "HMAC-SHA1")
 	params.Set("SignatureNonce", strconv.FormatInt(time.Now().UnixNano(), 10))
@@ -15,6 +24,10 @@ func AliyunSigner(accessKeyID, accessSecret string, params *url.Values) {
 	params.Set("SignatureVersion", "1.0")
 	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
 	params.Set("Format", "JSON")
-	params.Set("Version", "2015-01-09")
+	// 未指定时使用云解析DNS的版本
+	if !params.Has("Version") {
+		params.Set("Version", "2015-01-09")
+	}
 	params.Set("Signature", HmacSignToB64("HMAC-SHA1", "GET", accessSecret, *params))
+	return nil
 }
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AliyunEcsRamRole AccessKey ID 填写为该值时, 使用ECS实例RAM角色的临时凭证, AccessKey Secret 可填写角色名称
+const AliyunEcsRamRole = "ecs-ram-role"
+
+const aliyunMetadataEndpoint = "http://100.100.100.200/latest"
+
+// aliyunCredential ECS实例RAM角色的临时凭证
+type aliyunCredential struct {
+	Code            string
+	AccessKeyId     string
+	AccessKeySecret string
+	SecurityToken   string
+	Expiration      time.Time
+}
+
+var aliyunCredentials = struct {
+	sync.Mutex
+	creds map[string]*aliyunCredential
+}{creds: map[string]*aliyunCredential{}}
+
+// AliyunCredential 返回签名使用的 AccessKey, 使用ECS实例RAM角色时从元数据服务获取临时凭证, 过期前5分钟刷新
+func AliyunCredential(accessKeyID, accessSecret string) (id, secret, securityToken string, err error) {
+	if accessKeyID != AliyunEcsRamRole {
+		return accessKeyID, accessSecret, "", nil
+	}
+
+	aliyunCredentials.Lock()
+	defer aliyunCredentials.Unlock()
+
+	roleName := accessSecret
+	cred, ok := aliyunCredentials.creds[roleName]
+	if !ok || time.Until(cred.Expiration) < 5*time.Minute {
+		cred, err = fetchAliyunCredential(roleName)
+		if err != nil {
+			return "", "", "", err
+		}
+		aliyunCredentials.creds[roleName] = cred
+	}
+	return cred.AccessKeyId, cred.AccessKeySecret, cred.SecurityToken, nil
+}
+
+// fetchAliyunCredential 从ECS元数据服务获取RAM角色的临时凭证, 角色名称为空时自动获取
+func fetchAliyunCredential(roleName string) (*aliyunCredential, error) {
+	client := CreateNoProxyHTTPClient("tcp4")
+	client.Timeout = 5 * time.Second
+
+	// 加固模式需要先获取 token, 普通模式下获取失败时忽略
+	var metadataToken string
+	req, _ := http.NewRequest(http.MethodPut, aliyunMetadataEndpoint+"/api/token", nil)
+	req.Header.Set("X-aliyun-ecs-metadata-token-ttl-seconds", "21600")
+	if body, err := GetHTTPResponseOrg(client.Do(req)); err == nil {
+		metadataToken = string(body)
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, aliyunMetadataEndpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if metadataToken != "" {
+			req.Header.Set("X-aliyun-ecs-metadata-token", metadataToken)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024000))
+		if err == nil && resp.StatusCode != http.StatusOK {
+			err = errors.New(LogStr("返回内容: %s ,返回状态码: %d", string(body), resp.StatusCode))
+		}
+		return body, err
+	}
+
+	if roleName == "" {
+		body, err := get("/meta-data/ram/security-credentials/")
+		if err != nil {
+			return nil, err
+		}
+		roleName = strings.TrimSpace(strings.SplitN(string(body), "\n", 2)[0])
+		if roleName == "" {
+			return nil, errors.New("no RAM role attached to the ECS instance")
+		}
+	}
+
+	body, err := get("/meta-data/ram/security-credentials/" + roleName)
+	if err != nil {
+		return nil, err
+	}
+	var cred aliyunCredential
+	if err = json.Unmarshal(body, &cred); err != nil {
+		return nil, err
+	}
+	if cred.Code != "Success" {
+		return nil, errors.New("failed to get the credential of RAM role " + roleName + ": " + cred.Code)
+	}
+	return &cred, nil
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"net/http"
+	"sync"
+)
+
+// authHints 按 host 保存鉴权/权限错误时的处理建议, 为国际化的key
+var authHints = struct {
+	sync.RWMutex
+	hints map[string]string
+}{hints: map[string]string{}}
+
+// SetAuthHint 设置该 host 返回 401/403 时提示的处理建议
+func SetAuthHint(host, hint string) {
+	authHints.Lock()
+	defer authHints.Unlock()
+	authHints.hints[host] = hint
+}
+
+// AuthHint 返回该 host 的处理建议, 用于在返回内容中报告鉴权错误的服务商
+func AuthHint(host string) string {
+	authHints.RLock()
+	defer authHints.RUnlock()
+	if hint, ok := authHints.hints[host]; ok {
+		return LogStr("提示: %s", LogStr(hint))
+	}
+	return ""
+}
+
+// getAuthHint 鉴权/权限错误时返回处理建议
+func getAuthHint(resp *http.Response) string {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return ""
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return AuthHint(resp.Request.URL.Hostname())
+}
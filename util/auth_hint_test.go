@@ -0,0 +1,29 @@
+package util
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGetAuthHint(t *testing.T) {
+	SetAuthHint("api.example.com", "check the token")
+	u, _ := url.Parse("https://api.example.com/records")
+
+	resp := &http.Response{StatusCode: http.StatusForbidden, Request: &http.Request{URL: u}}
+	if hint := getAuthHint(resp); !strings.Contains(hint, "check the token") {
+		t.Errorf("Expected hint for 403, got %q", hint)
+	}
+
+	resp.StatusCode = http.StatusBadRequest
+	if hint := getAuthHint(resp); hint != "" {
+		t.Errorf("Expected no hint for 400, got %q", hint)
+	}
+
+	u.Host = "other.example.com"
+	resp.StatusCode = http.StatusUnauthorized
+	if hint := getAuthHint(resp); hint != "" {
+		t.Errorf("Expected no hint for unknown host, got %q", hint)
+	}
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+)
+
+// ShellCommand 使用系统的 shell 执行命令
+// Windows 使用 powershell, 其它系统优先使用 bash, 不存在时使用 sh
+func ShellCommand(cmd string) *exec.Cmd {
+	return ShellCommandContext(context.Background(), cmd)
+}
+
+// ShellCommandContext 同 ShellCommand, ctx 结束时终止命令
+func ShellCommandContext(ctx context.Context, cmd string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "powershell", "-Command", cmd)
+	}
+	// If Bash does not exist, use sh
+	_, err := exec.LookPath("bash")
+	if err != nil {
+		return exec.CommandContext(ctx, "sh", "-c", cmd)
+	}
+	return exec.CommandContext(ctx, "bash", "-c", cmd)
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"net"
+	"strings"
+	"sync"
+)
+
+// connectOverrides 按域名覆盖连接的地址, 如经过内网网关访问DNS服务商的API
+// 只改变TCP连接的地址, 请求的 Host/TLS SNI 与签名仍使用原域名
+var connectOverrides = struct {
+	sync.RWMutex
+	hosts map[string]string
+}{}
+
+// SetConnectOverrides 设置按域名覆盖的连接地址, 如 alidns.aliyuncs.com: 10.0.0.1:8443, 未指定端口时使用原端口
+func SetConnectOverrides(overrides map[string]string) {
+	hosts := make(map[string]string, len(overrides))
+	for host, address := range overrides {
+		hosts[strings.ToLower(strings.TrimSpace(host))] = strings.TrimSpace(address)
+	}
+
+	connectOverrides.Lock()
+	defer connectOverrides.Unlock()
+	connectOverrides.hosts = hosts
+}
+
+// connectAddress 返回 host:port 实际连接的地址
+func connectAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	connectOverrides.RLock()
+	target, ok := connectOverrides.hosts[strings.ToLower(host)]
+	connectOverrides.RUnlock()
+	if !ok || target == "" {
+		return address
+	}
+
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	}
+	return net.JoinHostPort(strings.Trim(target, "[]"), port)
+}
+
+// dialAddress 拨号时使用的地址
+func dialAddress(address string) string {
+	return rootedAddress(connectAddress(address))
+}
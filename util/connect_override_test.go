@@ -0,0 +1,29 @@
+package util
+
+import "testing"
+
+// TestConnectAddress 测试按域名覆盖连接的地址
+func TestConnectAddress(t *testing.T) {
+	SetConnectOverrides(map[string]string{
+		"alidns.aliyuncs.com": "10.0.0.1:8443",
+		"API.Cloudflare.com":  "gateway.lan",
+		"dnsapi.cn":           "[fd00::1]",
+	})
+	defer SetConnectOverrides(nil)
+
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"alidns.aliyuncs.com:443", "10.0.0.1:8443"},
+		{"api.cloudflare.com:443", "gateway.lan:443"},
+		{"dnsapi.cn:443", "[fd00::1]:443"},
+		{"example.com:443", "example.com:443"},
+		{"example.com", "example.com"},
+	}
+	for _, tt := range tests {
+		if got := connectAddress(tt.address); got != tt.want {
+			t.Errorf("connectAddress(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
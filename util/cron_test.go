@@ -0,0 +1,54 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCronMatch 测试 cron 表达式的匹配
+func TestCronMatch(t *testing.T) {
+	// 2024-01-15 为星期一
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 15, hour, minute, 30, 0, time.Local)
+	}
+
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", monday(12, 0), true},
+		{"* 2-4 * * *", monday(3, 59), true},
+		{"* 2-4 * * *", monday(5, 0), false},
+		{"*/15 * * * *", monday(1, 45), true},
+		{"*/15 * * * *", monday(1, 46), false},
+		{"5/20 * * * *", monday(1, 25), true},
+		{"0,30 22 * * *", monday(22, 30), true},
+		{"* * * * MON-FRI", monday(9, 0), true},
+		{"* * * * sat,sun", monday(9, 0), false},
+		{"* * * * 7", time.Date(2024, 1, 14, 9, 0, 0, 0, time.Local), true},
+		{"* * * JAN *", monday(9, 0), true},
+		{"* * * 2-12 *", monday(9, 0), false},
+		// 日和周都有限制时满足其一即可
+		{"* * 1 * MON", monday(9, 0), true},
+		{"* * 1 * TUE", monday(9, 0), false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %s", tt.expr, err)
+		}
+		if got := c.Match(tt.t); got != tt.want {
+			t.Errorf("%q.Match(%s) = %v, want %v", tt.expr, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+// TestParseCronInvalid 测试无效的表达式
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 5-2 * * *", "*/0 * * * *", "* * * * funday"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) 应返回错误", expr)
+		}
+	}
+}
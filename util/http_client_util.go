@@ -15,9 +15,9 @@ var dialer = &net.Dialer{
 
 var defaultTransport = &http.Transport{
 	// from http.DefaultTransport
-	Proxy: http.ProxyFromEnvironment,
+	Proxy: proxyFunc,
 	DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
-		return dialer.DialContext(ctx, network, address)
+		return dialer.DialContext(ctx, network, dialAddress(address))
 	},
 	ForceAttemptHTTP2:     true,
 	MaxIdleConns:          100,
@@ -30,7 +30,7 @@ var defaultTransport = &http.Transport{
 func CreateHTTPClient() *http.Client {
 	return &http.Client{
 		Timeout:   30 * time.Second,
-		Transport: defaultTransport,
+		Transport: &rateLimitTransport{base: &traceTransport{base: defaultTransport}},
 	}
 }
 
@@ -40,7 +40,7 @@ var noProxyTcp4Transport = &http.Transport{
 	DisableKeepAlives: true,
 	// tcp4
 	DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
-		return dialer.DialContext(ctx, "tcp4", address)
+		return dialer.DialContext(ctx, "tcp4", dialAddress(address))
 	},
 	// from http.DefaultTransport
 	ForceAttemptHTTP2:     true,
@@ -56,7 +56,7 @@ var noProxyTcp6Transport = &http.Transport{
 	DisableKeepAlives: true,
 	// tcp6
 	DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
-		return dialer.DialContext(ctx, "tcp6", address)
+		return dialer.DialContext(ctx, "tcp6", dialAddress(address))
 	},
 	// from http.DefaultTransport
 	ForceAttemptHTTP2:     true,
@@ -66,7 +66,22 @@ var noProxyTcp6Transport = &http.Transport{
 	ExpectContinueTimeout: 1 * time.Second,
 }
 
-// CreateNoProxyHTTPClient Create NoProxy HTTP Client
+var noProxyTransport = &http.Transport{
+	// no proxy
+	// DisableKeepAlives
+	DisableKeepAlives: true,
+	DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, dialAddress(address))
+	},
+	// from http.DefaultTransport
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: 1 * time.Second,
+}
+
+// CreateNoProxyHTTPClient Create NoProxy HTTP Client, network: tcp4/tcp6/tcp
 func CreateNoProxyHTTPClient(network string) *http.Client {
 	if network == "tcp6" {
 		return &http.Client{
@@ -74,6 +89,12 @@ func CreateNoProxyHTTPClient(network string) *http.Client {
 			Transport: noProxyTcp6Transport,
 		}
 	}
+	if network == "tcp" {
+		return &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: noProxyTransport,
+		}
+	}
 
 	return &http.Client{
 		Timeout:   30 * time.Second,
@@ -83,7 +104,7 @@ func CreateNoProxyHTTPClient(network string) *http.Client {
 
 // SetInsecureSkipVerify 将所有 http.Transport 的 InsecureSkipVerify 设置为 true
 func SetInsecureSkipVerify() {
-	transports := []*http.Transport{defaultTransport, noProxyTcp4Transport, noProxyTcp6Transport}
+	transports := []*http.Transport{defaultTransport, noProxyTcp4Transport, noProxyTcp6Transport, noProxyTransport}
 
 	for _, transport := range transports {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
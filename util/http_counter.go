@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// HTTPCounter 单个DNS配置发送的请求的统计, 并发运行的配置互不影响
+type HTTPCounter struct {
+	serviceUnavailable atomic.Uint64
+
+	mu    sync.Mutex
+	stats HTTPStats
+}
+
+// Stats 返回请求的次数和耗时
+func (c *HTTPCounter) Stats() HTTPStats {
+	if c == nil {
+		return HTTPStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// add 累加一次请求的统计
+func (c *HTTPCounter) add(s HTTPStats) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.stats = c.stats.Add(s)
+	c.mu.Unlock()
+}
+
+// ServiceUnavailable 收到 503 Service Unavailable 的次数
+func (c *HTTPCounter) ServiceUnavailable() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.serviceUnavailable.Load()
+}
+
+type httpCounterKey struct{}
+
+// WithHTTPCounter 返回附加了 counter 的 context, 使用该 context 的请求计入 counter
+func WithHTTPCounter(ctx context.Context, counter *HTTPCounter) context.Context {
+	return context.WithValue(ctx, httpCounterKey{}, counter)
+}
+
+// HTTPCounterFrom 返回 ctx 附加的 counter, 没有时返回 nil
+func HTTPCounterFrom(ctx context.Context) *HTTPCounter {
+	if ctx == nil {
+		return nil
+	}
+	counter, _ := ctx.Value(httpCounterKey{}).(*HTTPCounter)
+	return counter
+}
+
+// requestCounter 返回请求附加的 counter
+func requestCounter(req *http.Request) *HTTPCounter {
+	if req == nil {
+		return nil
+	}
+	return HTTPCounterFrom(req.Context())
+}
+
+// counterTransport 将 counter 附加到未附加 counter 的请求
+type counterTransport struct {
+	counter *HTTPCounter
+	base    http.RoundTripper
+}
+
+func (t *counterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if requestCounter(req) == nil {
+		req = req.WithContext(WithHTTPCounter(req.Context(), t.counter))
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CreateCountedHTTPClient 与 CreateHTTPClient 相同, 发送的请求同时计入 counter
+func CreateCountedHTTPClient(counter *HTTPCounter) *http.Client {
+	client := CreateHTTPClient()
+	if counter != nil {
+		client.Transport = &counterTransport{counter: counter, base: client.Transport}
+	}
+	return client
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// proxySetting 当前使用的代理, 保存配置后替换, 正在发送的请求不受影响
+type proxySetting struct {
+	raw string
+	url *url.URL
+}
+
+var currentProxy atomic.Pointer[proxySetting]
+
+// proxyFunc defaultTransport 使用的代理, 未设置代理时使用环境变量 HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+func proxyFunc(req *http.Request) (*url.URL, error) {
+	if p := currentProxy.Load(); p != nil && p.url != nil {
+		return p.url, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// ParseProxy 解析代理地址, 支持 http/https/socks5, 为空时返回 nil
+func ParseProxy(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("%s", LogStr("代理地址不正确! 异常信息: %s", err))
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%s", LogStr("代理地址 %s 不正确, 应以 http://、https:// 或 socks5:// 开头", u.Redacted()))
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%s", LogStr("代理地址 %s 不正确, 没有主机名", u.Redacted()))
+	}
+	return u, nil
+}
+
+// SetProxy 设置 CreateHTTPClient 使用的代理, 为空时使用环境变量 HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+// 不正确时返回错误并继续使用之前的代理. 获取IP的请求不使用代理, 见 CreateNoProxyHTTPClient
+func SetProxy(proxy string) error {
+	u, err := ParseProxy(proxy)
+	if err != nil {
+		return err
+	}
+	if old := currentProxy.Load(); old != nil && old.raw == proxy {
+		return nil
+	}
+	currentProxy.Store(&proxySetting{raw: proxy, url: u})
+	// 已建立的连接可能经过之前的代理
+	defaultTransport.CloseIdleConnections()
+	if u != nil {
+		Log("请求将通过代理 %s", u.Redacted())
+	}
+	return nil
+}
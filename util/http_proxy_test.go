@@ -0,0 +1,111 @@
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestSetProxy 测试请求通过配置的HTTP代理发送
+func TestSetProxy(t *testing.T) {
+	t.Cleanup(func() { SetProxy("") })
+	var target string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.URL.String()
+		w.Write([]byte("ok"))
+	}))
+	defer proxy.Close()
+
+	if err := SetProxy(proxy.URL); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := CreateHTTPClient().Get("http://proxy-test.invalid/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if target != "http://proxy-test.invalid/path" {
+		t.Errorf("期待请求通过代理, 代理收到 %q", target)
+	}
+}
+
+// TestSetProxySocks5 测试使用socks5代理时连接代理的地址
+func TestSetProxySocks5(t *testing.T) {
+	t.Cleanup(func() { SetProxy("") })
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	dialed := make(chan struct{}, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		dialed <- struct{}{}
+		conn.Close()
+	}()
+
+	if err := SetProxy("socks5://" + listener.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	client := CreateHTTPClient()
+	client.Timeout = 5 * time.Second
+	if resp, err := client.Get("http://proxy-test.invalid/"); err == nil {
+		resp.Body.Close()
+	}
+	select {
+	case <-dialed:
+	default:
+		t.Error("期待连接socks5代理")
+	}
+}
+
+// TestSetProxyInvalid 测试代理地址不正确时返回错误
+func TestSetProxyInvalid(t *testing.T) {
+	t.Cleanup(func() { SetProxy("") })
+	for _, proxy := range []string{"://bad", "ftp://proxy.lan:21", "http://", "proxy.lan:8080"} {
+		if err := SetProxy(proxy); err == nil {
+			t.Errorf("代理地址 %q 应返回错误", proxy)
+		}
+	}
+	if err := SetProxy(""); err != nil {
+		t.Errorf("为空时应使用环境变量中的代理, 得到 %v", err)
+	}
+}
+
+// TestSetProxyChange 测试修改代理后立即生效, 不正确时继续使用之前的代理
+func TestSetProxyChange(t *testing.T) {
+	t.Cleanup(func() { SetProxy("") })
+	var first, second int
+	newProxy := func(count *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*count++
+		}))
+	}
+	proxy1, proxy2 := newProxy(&first), newProxy(&second)
+	defer proxy1.Close()
+	defer proxy2.Close()
+
+	get := func() {
+		if resp, err := CreateHTTPClient().Get("http://proxy-test.invalid/"); err == nil {
+			resp.Body.Close()
+		}
+	}
+	SetProxy(proxy1.URL)
+	get()
+	if err := SetProxy(proxy2.URL); err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if err := SetProxy("ftp://proxy.lan"); err == nil {
+		t.Error("代理地址不正确时应返回错误")
+	}
+	get()
+	if first != 1 || second != 2 {
+		t.Errorf("期待第1次请求经过代理1, 之后经过代理2, 实际 %d %d", first, second)
+	}
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HTTPStats HTTP请求的次数和耗时, 各阶段为所有请求的合计
+type HTTPStats struct {
+	Requests int
+	// Retries 服务商限流或暂时不可用时重试的次数
+	Retries int
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// Total 发送请求到收到响应头的时间
+	Total time.Duration
+}
+
+// Sub 与之前的快照 old 的差, 即这段时间内的请求
+func (s HTTPStats) Sub(old HTTPStats) HTTPStats {
+	return HTTPStats{
+		Requests: s.Requests - old.Requests,
+		Retries:  s.Retries - old.Retries,
+		DNS:      s.DNS - old.DNS,
+		Connect:  s.Connect - old.Connect,
+		TLS:      s.TLS - old.TLS,
+		Total:    s.Total - old.Total,
+	}
+}
+
+// Add 与 other 的和
+func (s HTTPStats) Add(other HTTPStats) HTTPStats {
+	return HTTPStats{
+		Requests: s.Requests + other.Requests,
+		Retries:  s.Retries + other.Retries,
+		DNS:      s.DNS + other.DNS,
+		Connect:  s.Connect + other.Connect,
+		TLS:      s.TLS + other.TLS,
+		Total:    s.Total + other.Total,
+	}
+}
+
+var httpStats = struct {
+	sync.Mutex
+	HTTPStats
+}{}
+
+// GetHTTPStats 返回启动后 CreateHTTPClient 发送的请求的统计, 两次调用相减得到这段时间内的统计
+func GetHTTPStats() HTTPStats {
+	httpStats.Lock()
+	defer httpStats.Unlock()
+	return httpStats.HTTPStats
+}
+
+// AddHTTPRetry 重试次数加1, 同时计入 ctx 附加的 HTTPCounter
+func AddHTTPRetry(ctx context.Context) {
+	addHTTPStats(HTTPCounterFrom(ctx), HTTPStats{Retries: 1})
+}
+
+// addHTTPStats 计入全局的统计和 counter
+func addHTTPStats(counter *HTTPCounter, s HTTPStats) {
+	httpStats.Lock()
+	httpStats.HTTPStats = httpStats.Add(s)
+	httpStats.Unlock()
+	counter.add(s)
+}
+
+// traceTransport 通过 httptrace 统计DNS解析、建立连接、TLS握手和总耗时的 http.RoundTripper
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		mu                            sync.Mutex
+		dnsStart, connStart, tlsStart time.Time
+		stats                         HTTPStats
+	)
+	// 同时连接多个地址时回调可能并发
+	since := func(start *time.Time, d *time.Duration) {
+		mu.Lock()
+		if !start.IsZero() {
+			*d += time.Since(*start)
+		}
+		mu.Unlock()
+	}
+	now := func(start *time.Time) {
+		mu.Lock()
+		*start = time.Now()
+		mu.Unlock()
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { now(&dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { since(&dnsStart, &stats.DNS) },
+		ConnectStart:      func(string, string) { now(&connStart) },
+		ConnectDone:       func(string, string, error) { since(&connStart, &stats.Connect) },
+		TLSHandshakeStart: func() { now(&tlsStart) },
+		TLSHandshakeDone:  func(_ tls.ConnectionState, _ error) { since(&tlsStart, &stats.TLS) },
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	total := time.Since(start)
+
+	mu.Lock()
+	stats.Requests = 1
+	stats.Total = total
+	addHTTPStats(requestCounter(req), stats)
+	mu.Unlock()
+	return resp, err
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestTraceTransport 测试统计请求次数和TLS握手的耗时
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	counter := &HTTPCounter{}
+	client := &http.Client{Transport: &counterTransport{counter: counter, base: &traceTransport{base: server.Client().Transport}}}
+	before := GetHTTPStats()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	AddHTTPRetry(WithHTTPCounter(context.Background(), counter))
+
+	stats := GetHTTPStats().Sub(before)
+	if stats.Requests != 1 || stats.Retries != 1 {
+		t.Errorf("期待1次请求1次重试, 得到 %+v", stats)
+	}
+	if stats.Connect <= 0 || stats.TLS <= 0 || stats.Total < stats.TLS {
+		t.Errorf("耗时不正确: %+v", stats)
+	}
+	if counter.Stats() != stats {
+		t.Errorf("counter 的统计 %+v 与全局的 %+v 不一致", counter.Stats(), stats)
+	}
+
+	// 未附加 counter 的请求不计入 counter
+	resp, err = server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if counter.Stats().Requests != 1 {
+		t.Errorf("期待 counter 只有1次请求, 得到 %+v", counter.Stats())
+	}
+}
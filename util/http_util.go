@@ -36,9 +36,26 @@ func GetHTTPResponseOrg(resp *http.Response, err error) ([]byte, error) {
 		return nil, err
 	}
 
+	// 503 一般为服务商正在维护, 由调用方推迟重试
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		// 计入请求所属配置的 counter, 用于判断DNS服务商是否正在维护
+		if counter := requestCounter(resp.Request); counter != nil {
+			counter.serviceUnavailable.Add(1)
+		}
+		host := ""
+		if resp.Request != nil && resp.Request.URL != nil {
+			host = resp.Request.URL.Host
+		}
+		return body, fmt.Errorf("%s", LogStr("服务商 %s 正在维护(503 Service Unavailable), 返回内容: %s", host, string(body)))
+	}
+
 	// 300及以上状态码都算异常
 	if resp.StatusCode >= 300 {
-		err = fmt.Errorf("%s", LogStr("返回内容: %s ,返回状态码: %d", string(body), resp.StatusCode))
+		msg := LogStr("返回内容: %s ,返回状态码: %d", string(body), resp.StatusCode)
+		if hint := getAuthHint(resp); hint != "" {
+			msg += " " + hint
+		}
+		err = fmt.Errorf("%s", msg)
 	}
 
 	return body, err
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGetHTTPResponseOrgServiceUnavailable(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/records")
+	counter := &HTTPCounter{}
+	req := (&http.Request{URL: u}).WithContext(WithHTTPCounter(context.Background(), counter))
+
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Body:       io.NopCloser(strings.NewReader("maintenance")),
+		Request:    req,
+	}
+	if _, err := GetHTTPResponseOrg(resp, nil); err == nil || !strings.Contains(err.Error(), "api.example.com") {
+		t.Errorf("Expected maintenance error, got %v", err)
+	}
+	if counter.ServiceUnavailable() != 1 {
+		t.Error("Expected 503 to be counted")
+	}
+
+	resp = &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}
+	if _, err := GetHTTPResponseOrg(resp, nil); err == nil || counter.ServiceUnavailable() != 1 {
+		t.Errorf("Expected 502 to fail without being counted, got %v", err)
+	}
+}
+
+func TestCreateCountedHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	a, b := &HTTPCounter{}, &HTTPCounter{}
+	resp, err := CreateCountedHTTPClient(a).Get(server.URL)
+	GetHTTPResponseOrg(resp, err)
+	resp, err = CreateCountedHTTPClient(a).Get(server.URL)
+	GetHTTPResponseOrg(resp, err)
+	resp, err = CreateCountedHTTPClient(b).Get(server.URL)
+	GetHTTPResponseOrg(resp, err)
+
+	if a.ServiceUnavailable() != 2 || b.ServiceUnavailable() != 1 {
+		t.Errorf("Expected 503 counted per counter, got %d and %d", a.ServiceUnavailable(), b.ServiceUnavailable())
+	}
+}
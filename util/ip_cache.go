@@ -12,6 +12,7 @@ type IpCache struct {
 	Addr          string // 缓存地址
 	Times         int    // 剩余次数
 	TimesFailedIP int    // 获取ip失败的次数
+	LocalOnly     bool   // 上次只获取到链路本地/ULA的IPv6地址
 }
 
 var ForceCompareGlobal = true
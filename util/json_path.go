@@ -0,0 +1,31 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath 取出JSON中 . 分隔的路径的值, 数组使用下标, 如 data.0.ip
+func JSONPath(body []byte, path string) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("%s not found", path)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("%s not found", path)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,19 @@
+package util
+
+import "testing"
+
+// TestJSONPath 测试取出对象和数组中的值
+func TestJSONPath(t *testing.T) {
+	body := []byte(`{"data":[{"ip":"1.1.1.1","code":0}]}`)
+	if v, err := JSONPath(body, "data.0.ip"); err != nil || v != "1.1.1.1" {
+		t.Errorf("期待 1.1.1.1, 得到 %v %v", v, err)
+	}
+	if v, err := JSONPath(body, "data.0.code"); err != nil || v != float64(0) {
+		t.Errorf("期待 0, 得到 %v %v", v, err)
+	}
+	for _, path := range []string{"data.1.ip", "data.x", "data.0.ip.v4"} {
+		if _, err := JSONPath(body, path); err == nil {
+			t.Errorf("%s 应不存在", path)
+		}
+	}
+}
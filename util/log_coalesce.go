@@ -0,0 +1,85 @@
+package util
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// maxLogRepeatEntries 记录的日志条数超过该值时清理已过期的
+const maxLogRepeatEntries = 1000
+
+// logRepeat 合并时间窗口内重复的日志
+var logRepeat = struct {
+	sync.Mutex
+	window  time.Duration
+	entries map[string]*logRepeatEntry
+}{entries: map[string]*logRepeatEntry{}}
+
+type logRepeatEntry struct {
+	first time.Time // 本窗口第一次输出的时间
+	count int       // 本窗口内未输出的次数
+}
+
+// SetLogRepeatWindow 相同的日志在 window 内只输出一次, 之后再输出或 FlushLogRepeat 时附带重复的次数, window <= 0 时不合并
+func SetLogRepeatWindow(window time.Duration) {
+	logRepeat.Lock()
+	defer logRepeat.Unlock()
+
+	if logRepeat.window != window {
+		logRepeat.window = window
+		logRepeat.entries = map[string]*logRepeatEntry{}
+	}
+}
+
+// coalesceLog 返回是否输出该日志, 以及上一个窗口内未输出的次数
+func coalesceLog(msg string, now time.Time) (ok bool, repeated int) {
+	logRepeat.Lock()
+	defer logRepeat.Unlock()
+
+	if logRepeat.window <= 0 {
+		return true, 0
+	}
+
+	if e, ok := logRepeat.entries[msg]; ok {
+		if now.Sub(e.first) < logRepeat.window {
+			e.count++
+			return false, 0
+		}
+		repeated = e.count
+	}
+
+	if len(logRepeat.entries) >= maxLogRepeatEntries {
+		for k, e := range logRepeat.entries {
+			if now.Sub(e.first) >= logRepeat.window {
+				delete(logRepeat.entries, k)
+			}
+		}
+	}
+	logRepeat.entries[msg] = &logRepeatEntry{first: now}
+	return true, repeated
+}
+
+// FlushLogRepeat 输出时间窗口已结束、之后未再出现的日志的重复次数, 每轮运行结束时调用
+func FlushLogRepeat() {
+	for _, msg := range flushLogRepeat(time.Now()) {
+		log.Println(msg)
+	}
+}
+
+// flushLogRepeat 清理时间窗口已结束的日志, 返回其中有未输出次数的日志
+func flushLogRepeat(now time.Time) (msgs []string) {
+	logRepeat.Lock()
+	defer logRepeat.Unlock()
+
+	for msg, e := range logRepeat.entries {
+		if now.Sub(e.first) < logRepeat.window {
+			continue
+		}
+		if e.count > 0 {
+			msgs = append(msgs, msg+" "+LogStr("(此前重复 %d 次)", e.count))
+		}
+		delete(logRepeat.entries, msg)
+	}
+	return
+}
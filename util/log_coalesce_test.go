@@ -0,0 +1,63 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCoalesceLog 测试时间窗口内重复的日志只输出一次
+func TestCoalesceLog(t *testing.T) {
+	SetLogRepeatWindow(time.Minute)
+	defer SetLogRepeatWindow(0)
+
+	now := time.Now()
+	steps := []struct {
+		msg      string
+		after    time.Duration
+		ok       bool
+		repeated int
+	}{
+		{"a", 0, true, 0},
+		{"a", 10 * time.Second, false, 0},
+		{"b", 20 * time.Second, true, 0},
+		{"a", 30 * time.Second, false, 0},
+		{"a", 61 * time.Second, true, 2},
+		{"a", 90 * time.Second, false, 0},
+		{"b", 3 * time.Minute, true, 0},
+	}
+	for i, s := range steps {
+		ok, repeated := coalesceLog(s.msg, now.Add(s.after))
+		if ok != s.ok || repeated != s.repeated {
+			t.Errorf("第 %d 条 %q = %v, %d, want %v, %d", i+1, s.msg, ok, repeated, s.ok, s.repeated)
+		}
+	}
+
+	SetLogRepeatWindow(0)
+	if ok, _ := coalesceLog("a", now); !ok {
+		t.Error("未设置时间窗口时应全部输出")
+	}
+}
+
+// TestFlushLogRepeat 测试不再重复的日志在时间窗口结束后输出重复次数
+func TestFlushLogRepeat(t *testing.T) {
+	SetLogRepeatWindow(time.Minute)
+	defer SetLogRepeatWindow(0)
+
+	now := time.Now()
+	coalesceLog("a", now)
+	coalesceLog("a", now.Add(10*time.Second))
+	coalesceLog("a", now.Add(20*time.Second))
+	coalesceLog("b", now)
+
+	if msgs := flushLogRepeat(now.Add(30 * time.Second)); len(msgs) != 0 {
+		t.Errorf("时间窗口内不应输出, 得到 %v", msgs)
+	}
+	msgs := flushLogRepeat(now.Add(2 * time.Minute))
+	if len(msgs) != 1 || !strings.HasPrefix(msgs[0], "a ") || !strings.Contains(msgs[0], "2") {
+		t.Errorf("期待输出 a 重复2次, 得到 %v", msgs)
+	}
+	if ok, repeated := coalesceLog("a", now.Add(3*time.Minute)); !ok || repeated != 0 {
+		t.Errorf("已输出的重复次数不应再次输出, 得到 %v, %d", ok, repeated)
+	}
+}
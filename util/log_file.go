@@ -0,0 +1,177 @@
+package util
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultLogMaxSizeMB 日志文件默认最大 10MB
+	defaultLogMaxSizeMB = 10
+	// defaultLogMaxBackups 默认保留 5 个旧日志文件
+	defaultLogMaxBackups = 5
+	// logBackupTimeFormat 旧日志文件的后缀, 按名称排序即按时间排序
+	logBackupTimeFormat = "20060102-150405.000"
+)
+
+// rotateFile 按大小/时间切分的日志文件
+type rotateFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	rotateTime time.Duration
+	maxBackups int
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// Write 写入日志, 超过大小或时间时先切分
+func (r *rotateFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err = r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.needRotate(len(p)) {
+		if err = r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotateFile) needRotate(n int) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+int64(n) > r.maxSize {
+		return true
+	}
+	return r.rotateTime > 0 && time.Since(r.openedAt) >= r.rotateTime
+}
+
+// open 以追加方式打开日志文件
+func (r *rotateFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	return nil
+}
+
+// rotate 将当前文件重命名为 path.时间, 打开新文件并删除多余的旧文件
+func (r *rotateFile) rotate() error {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	backup := r.path + "." + time.Now().Format(logBackupTimeFormat)
+	if err := os.Rename(r.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	r.removeOldBackups()
+	return r.open()
+}
+
+func (r *rotateFile) removeOldBackups() {
+	if r.maxBackups <= 0 {
+		return
+	}
+	backups, err := filepath.Glob(r.path + ".*")
+	if err != nil || len(backups) <= r.maxBackups {
+		return
+	}
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-r.maxBackups] {
+		os.Remove(backup)
+	}
+}
+
+func (r *rotateFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// logOutput 日志的输出位置, 默认为 stdout
+var logOutput = struct {
+	sync.RWMutex
+	w io.Writer
+}{w: os.Stdout}
+
+// LogWriter 日志输出, 默认写入 stdout, 通过 SetLogFile 改为写入文件
+var LogWriter io.Writer = logWriter{}
+
+type logWriter struct{}
+
+func (logWriter) Write(p []byte) (int, error) {
+	logOutput.RLock()
+	defer logOutput.RUnlock()
+	return logOutput.w.Write(p)
+}
+
+// CloseLogFile 退出前关闭日志文件, 之后的日志写入 stdout
+func CloseLogFile() {
+	SetLogFile("", 0, 0, 0)
+}
+
+// SetLogFile 日志改为写入文件 path, 文件超过 maxSizeMB 或已写入 rotateHours 小时后切分,
+// 保留 maxBackups 个旧文件。maxSizeMB/maxBackups 为 0 时使用默认值, path 为空时写入 stdout
+func SetLogFile(path string, maxSizeMB int, rotateHours int, maxBackups int) {
+	logOutput.Lock()
+	defer logOutput.Unlock()
+
+	if old, ok := logOutput.w.(*rotateFile); ok {
+		old.Close()
+	}
+	if path == "" {
+		logOutput.w = os.Stdout
+		return
+	}
+
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultLogMaxSizeMB
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultLogMaxBackups
+	}
+	absPath, err := filepath.Abs(path)
+	if err == nil {
+		path = absPath
+	}
+	logOutput.w = &rotateFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		rotateTime: time.Duration(rotateHours) * time.Hour,
+		maxBackups: maxBackups,
+	}
+}
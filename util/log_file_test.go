@@ -0,0 +1,73 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRotateFileSize 测试超过大小时切分并只保留指定个数的旧文件
+func TestRotateFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns-go.log")
+	r := &rotateFile{path: path, maxSize: 10, maxBackups: 2}
+	defer r.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := r.Write([]byte("123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+		// 旧文件名精确到毫秒
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("期待保留 2 个旧文件, 实际 %d 个: %v", len(backups), backups)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "123456789\n" {
+		t.Errorf("当前日志文件内容错误: %q", data)
+	}
+}
+
+// TestRotateFileTime 测试超过时间时切分
+func TestRotateFileTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns-go.log")
+	r := &rotateFile{path: path, rotateTime: time.Hour, maxBackups: 5}
+	defer r.Close()
+
+	r.Write([]byte("a\n"))
+	r.Write([]byte("b\n"))
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 0 {
+		t.Fatalf("未超过时间不应切分: %v", backups)
+	}
+
+	r.openedAt = time.Now().Add(-2 * time.Hour)
+	r.Write([]byte("c\n"))
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 1 {
+		t.Fatalf("超过时间应切分: %v", backups)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "c\n" {
+		t.Errorf("当前日志文件内容错误: %q", data)
+	}
+}
+
+// TestRotateFileAppend 测试重新打开时追加到已有文件
+func TestRotateFileAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns-go.log")
+	os.WriteFile(path, []byte("old\n"), 0644)
+
+	r := &rotateFile{path: path, maxSize: 1 << 20}
+	r.Write([]byte("new\n"))
+	r.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old\nnew\n" {
+		t.Errorf("期待追加到已有文件, 实际: %q", data)
+	}
+}
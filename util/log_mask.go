@@ -0,0 +1,72 @@
+package util
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minMaskLen 短于该长度的密钥不替换, 避免把日志中的普通字符替换掉
+const minMaskLen = 4
+
+// logMasker 将日志中的密钥替换为 ***
+var logMasker = struct {
+	sync.RWMutex
+	replacer *strings.Replacer
+}{}
+
+// SetLogSecrets 设置需要在日志中隐藏的密钥, 如 DNS.Secret
+func SetLogSecrets(secrets []string) {
+	var list []string
+	seen := map[string]bool{}
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if len(secret) < minMaskLen || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		list = append(list, secret)
+	}
+	// 先替换较长的密钥, 避免包含关系时只替换一部分
+	sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+
+	var replacer *strings.Replacer
+	if len(list) > 0 {
+		pairs := make([]string, 0, len(list)*2)
+		for _, secret := range list {
+			pairs = append(pairs, secret, "***")
+		}
+		replacer = strings.NewReplacer(pairs...)
+	}
+
+	logMasker.Lock()
+	defer logMasker.Unlock()
+	logMasker.replacer = replacer
+}
+
+// MaskSecrets 将 s 中的密钥替换为 ***
+func MaskSecrets(s string) string {
+	logMasker.RLock()
+	defer logMasker.RUnlock()
+	if logMasker.replacer == nil {
+		return s
+	}
+	return logMasker.replacer.Replace(s)
+}
+
+// MaskWriter 写入前将密钥替换为 ***
+func MaskWriter(w io.Writer) io.Writer {
+	return maskWriter{w}
+}
+
+type maskWriter struct {
+	w io.Writer
+}
+
+func (m maskWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(m.w, MaskSecrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
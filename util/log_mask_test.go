@@ -0,0 +1,42 @@
+package util
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+// TestMaskSecrets 测试日志中的密钥替换为 ***
+func TestMaskSecrets(t *testing.T) {
+	SetLogSecrets([]string{"abc", "token-123", "token-123456", " token-123 ", ""})
+	defer SetLogSecrets(nil)
+
+	tests := map[string]string{
+		"返回内容: {\"key\":\"token-123456\"}": "返回内容: {\"key\":\"***\"}",
+		"Bearer token-123, token-123":      "Bearer ***, ***",
+		// 较短的密钥不替换
+		"abc": "abc",
+	}
+	for msg, want := range tests {
+		if got := MaskSecrets(msg); got != want {
+			t.Errorf("MaskSecrets(%q) = %q, want %q", msg, got, want)
+		}
+	}
+
+	if got := LogStr("异常信息: %s", "invalid token-123"); got != LogStr("异常信息: %s", "invalid ***") {
+		t.Errorf("LogStr 未替换密钥: %s", got)
+	}
+}
+
+// TestMaskWriter 测试未经过 LogStr 的日志也会替换密钥
+func TestMaskWriter(t *testing.T) {
+	SetLogSecrets([]string{"secret-value"})
+	defer SetLogSecrets(nil)
+
+	var buf bytes.Buffer
+	logger := log.New(MaskWriter(&buf), "", 0)
+	logger.Println("provider returned: secret-value")
+	if buf.String() != "provider returned: ***\n" {
+		t.Errorf("期待替换密钥, 实际: %q", buf.String())
+	}
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// LookupAddrs 查询域名 name 的地址, network 为 ip4/ip6
+// server 为DNS服务器地址, 如 8.8.8.8 / [2001:db8::1]:53, 为空时向根域名 zone 的权威DNS服务器查询, 避免缓存的旧值
+func LookupAddrs(ctx context.Context, zone, name, network, server string) ([]netip.Addr, error) {
+	if server == "" {
+		resolver := dialer.Resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver
+		}
+		nss, err := resolver.LookupNS(ctx, strings.TrimSuffix(zone, ".")+".")
+		if err != nil {
+			return nil, err
+		}
+		if len(nss) == 0 {
+			return nil, errors.New("no name server found for " + zone)
+		}
+		server = strings.TrimSuffix(nss[0].Host, ".")
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+	return resolver.LookupNetIP(ctx, network, strings.TrimSuffix(name, ".")+".")
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"log"
 	"strings"
+	"time"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -19,23 +20,40 @@ func init() {
 	message.SetString(language.English, "配置文件已保存在: %s", "Config file has been saved to: %s")
 
 	message.SetString(language.English, "你的IP %s 没有变化, 域名 %s", "Your's IP %s has not changed! Domain: %s")
+	message.SetString(language.English, "你的IP %s 没有变化, 将强制更新域名 %s", "Your's IP %s has not changed, force updating domain %s")
 	message.SetString(language.English, "新增域名解析 %s 成功! IP: %s", "Added domain %s successfully! IP: %s")
 	message.SetString(language.English, "新增域名解析 %s 失败! 异常信息: %s", "Failed to add domain %s! Result: %s")
 
 	message.SetString(language.English, "更新域名解析 %s 成功! IP: %s", "Updated domain %s successfully! IP: %s")
 	message.SetString(language.English, "更新域名解析 %s 失败! 异常信息: %s", "Failed to updated domain %s! Result: %s")
+	message.SetString(language.English, "域名 %s 的IPv6后缀不正确! 异常信息: %s", "The IPv6 suffix of domain %s is invalid! Exception: %s")
+	message.SetString(language.English, "域名 %s 的解析已生效, IP: %s", "The record of domain %s has propagated, IP: %s")
+	message.SetString(language.English, "等待域名 %s 的解析生效超时, IP: %s", "Timed out waiting for the record of domain %s to propagate, IP: %s")
 
 	message.SetString(language.English, "你的IPv4未变化, 未触发 %s 请求", "Your's IPv4 has not changed, %s request has not been triggered")
 	message.SetString(language.English, "你的IPv6未变化, 未触发 %s 请求", "Your's IPv6 has not changed, %s request has not been triggered")
 	message.SetString(language.English, "Namecheap 不支持更新 IPv6", "Namecheap does not support IPv6")
 
 	message.SetString(language.English, "dynadot仅支持单域名配置，多个域名请添加更多配置", "dynadot only supports single domain configuration, please add more configurations")
+	message.SetString(language.English, "域名 %s 的权重 %s 不正确, 应为 0 到 100 的整数", "The weight %[2]s of domain %[1]s is invalid, it should be an integer from 0 to 100")
 
 	// http_util
 	message.SetString(language.English, "异常信息: %s", "Exception: %s")
 	message.SetString(language.English, "查询域名信息发生异常! %s", "Failed to query domain info! %s")
 	message.SetString(language.English, "返回内容: %s ,返回状态码: %d", "Response body: %s ,Response status code: %d")
 	message.SetString(language.English, "通过接口获取IPv4失败! 接口地址: %s", "Failed to get IPv4 from %s")
+	message.SetString(language.English, "通过接口获取IP失败! 接口地址: %s", "Failed to get IP from %s")
+	message.SetString(language.English, "权威接口 %s 获取IP失败, 将使用接口 %s 的结果", "Failed to get IP from the authoritative api %s, the result of %s will be used")
+	message.SetString(language.English, "未有超过半数的接口返回相同的IP, 将不会更新! 结果: %s", "No majority of the apis returned the same IP, will not update! Results: %s")
+	message.SetString(language.English, "接口 %s 返回的IP %s 与多数接口的结果 %s 不一致", "The api %s returned IP %s, which disagrees with the majority result %s")
+	message.SetString(language.English, "新IP %s 未通过健康检查, 不发布域名 %s 的记录. 异常信息: %s", "New IP %s failed health check, not publishing %s. Exception: %s")
+	message.SetString(language.English, "IPv6地址的选择方式 %s 不正确, 将使用第一个地址", "The IPv6 address selection %s is invalid, the first address will be used")
+	message.SetString(language.English, "域名 %s 的更新时间不正确, 将不限制更新时间. 异常信息: %s", "The update schedule of %s is invalid and is ignored. Exception: %s")
+	message.SetString(language.English, "当前不在域名 %s 允许更新的时间 %s 内, 将推迟更新", "Outside the update schedule %[2]s of %[1]s, the update is deferred")
+	message.SetString(language.English, "%s已恢复为之前使用过的 %s, 上次使用时间 %s, 可能是重新拨号而不是新的地址", "%s reverted to the previously used %s, last seen at %s. It may be a reconnect rather than a new lease")
+	message.SetString(language.English, "%s 为域名 %s 最近发布过的IP, 上次发布时间 %s, 将不会更新", "%s was recently published for %s at %s, it will not be published again")
+	message.SetString(language.English, "获取远程配置 %s 失败, 将使用本地配置文件 %s. 异常信息: %s", "Failed to fetch the remote configuration %s, the local configuration file %s will be used. Exception: %s")
+	message.SetString(language.English, "远程配置已更新, 已保存到 %s", "The remote configuration has changed and is saved to %s")
 	message.SetString(language.English, "通过接口获取IPv6失败! 接口地址: %s", "Failed to get IPv6 from %s")
 	message.SetString(language.English, "将不会触发Webhook, 仅在第 3 次失败时触发一次Webhook, 当前失败次数：%d", "Webhook will not be triggered, only trigger once when the third failure, current failure times: %d")
 	message.SetString(language.English, "在DNS服务商中未找到根域名: %s", "Root domain not found in DNS provider: %s")
@@ -45,13 +63,44 @@ func init() {
 	message.SetString(language.English, "Webhook中的 RequestBody JSON 无效", "Webhook RequestBody JSON is invalid")
 	message.SetString(language.English, "Webhook调用成功! 返回数据：%s", "Successfully called Webhook! Response body: %s")
 	message.SetString(language.English, "Webhook调用失败! 异常信息：%s", "Failed to call Webhook! Exception: %s")
+	message.SetString(language.English, "MQTT发布成功! Topic: %s", "MQTT published successfully! Topic: %s")
+	message.SetString(language.English, "MQTT发布失败! 异常信息：%s", "MQTT publish failed! Exception: %s")
 	message.SetString(language.English, "Webhook Header不正确: %s", "Webhook header is invalid: %s")
+
+	// ip output
+	message.SetString(language.English, "写入IP到文件 %s 失败! 异常信息: %s", "Failed to write IP to file %s! Exception: %s")
+	message.SetString(language.English, "已写入IP到文件 %s", "IP has been written to file %s")
+	message.SetString(language.English, "执行IP改变命令失败! 命令: %s, 错误: %s, 输出: %q", "Failed to run the IP change command! Command: %s, Error: %s, Output: %q")
+	message.SetString(language.English, "执行IP改变命令成功! 输出: %q", "Successfully ran the IP change command! Output: %q")
+	message.SetString(language.English, "不支持的反向解析服务商 %s", "Unsupported reverse DNS provider %s")
+	message.SetString(language.English, "更新 %s 的反向解析失败! 异常信息: %s", "Failed to update the reverse DNS of %s! Exception: %s")
+	message.SetString(language.English, "已更新 %s 的反向解析为 %s", "The reverse DNS of %s has been updated to %s")
+	message.SetString(language.English, "缓存的记录ID %s 已不存在, 重新查询域名 %s", "The cached record ID %s no longer exists, listing the records of domain %s again")
+	message.SetString(language.English, "转换IP %s 失败! 规则: %s, 异常信息: %s", "Failed to transform IP %s! Rule: %s, Exception: %s")
+	message.SetString(language.English, "IP %s 已转换为 %s", "IP %s has been transformed to %s")
+	message.SetString(language.English, "DNS服务商 %s 要求 %s 的TTL不小于 %d, 之后将使用该TTL", "DNS provider %s requires a TTL of at least %[3]d for %[2]s, it will be used from now on")
+	message.SetString(language.English, "收到外部触发: %s", "Received external trigger: %s")
+	message.SetString(language.English, "提示: %s", "Hint: %s")
+	message.SetString(language.English, "请确认RAM用户已授予 AliyunDNSFullAccess 权限", "Make sure the RAM user has the AliyunDNSFullAccess permission")
+	message.SetString(language.English, "请确认RAM用户已授予 AliyunESAFullAccess 权限", "Make sure the RAM user has the AliyunESAFullAccess permission")
+	message.SetString(language.English, "请确认Token拥有 Zone:DNS:Edit 权限, 负载均衡需要 Load Balancing: Monitors and Pools:Edit 权限", "Make sure the token has the Zone:DNS:Edit permission, the load balancer needs Load Balancing: Monitors and Pools:Edit")
+	message.SetString(language.English, "请确认IAM用户已授予 DNS FullAccess 权限", "Make sure the IAM user has the DNS FullAccess permission")
+	message.SetString(language.English, "请确认子用户已授予 QcloudDNSPodFullAccess 权限", "Make sure the sub-user has the QcloudDNSPodFullAccess permission")
+	message.SetString(language.English, "请确认子用户已授予 QcloudTEOFullAccess 权限", "Make sure the sub-user has the QcloudTEOFullAccess permission")
+	message.SetString(language.English, "请确认API Token的角色拥有修改DNS的权限", "Make sure the role of the API token is allowed to edit DNS")
+	message.SetString(language.English, "请确认已在Porkbun的域名设置中开启 API ACCESS", "Make sure API ACCESS is enabled in the Porkbun domain settings")
+	message.SetString(language.English, "请确认令牌拥有 gist 或 contents 写入权限", "Please make sure the token has gist or contents write permission")
+	message.SetString(language.English, "请确认使用的是 Production 环境的Key, GoDaddy 只允许域名数量达到要求的帐号使用API", "Make sure the key is for the Production environment, GoDaddy only allows accounts with enough domains to use the API")
 	message.SetString(language.English, "请输入Webhook的URL", "Please enter the Webhook url")
 
 	// callback
 	message.SetString(language.English, "Callback的URL不正确", "Callback url is incorrect")
 	message.SetString(language.English, "Callback调用成功, 域名: %s, IP: %s, 返回数据: %s", "Successfully called Callback! Domain: %s, IP: %s, Response body: %s")
 	message.SetString(language.English, "Callback调用失败, 异常信息: %s", "Failed to call Callback! Exception: %s")
+	message.SetString(language.English, "返回状态码 %d 不是成功的状态码 %v, 返回内容: %s", "The status code %d is not one of the success codes %v, response: %s")
+	message.SetString(language.English, "返回内容不匹配 %s, 返回内容: %s", "The response does not match %s, response: %s")
+	message.SetString(language.English, "返回内容中 %s 的值为 %s, 期待 %s", "The value of %s in the response is %s, expected %s")
+	message.SetString(language.English, "文件原有内容不是有效的JSON, 将被覆盖", "The existing content of the file is not valid JSON and will be overwritten")
 
 	// save
 	message.SetString(language.English, "必须输入用户名/密码", "Username/Password is required")
@@ -59,14 +108,26 @@ func init() {
 	message.SetString(language.English, "数据解析失败, 请刷新页面重试", "Data parsing failed, please refresh the page and try again")
 	message.SetString(language.English, "第 %s 个配置未填写域名", "The %s config does not fill in the domain")
 
+	// compare
+	message.SetString(language.English, "新增配置: %s", "Add config: %s")
+	message.SetString(language.English, "删除配置: %s", "Delete config: %s")
+	message.SetString(language.English, "修改配置: %s, DNS服务商设置已修改", "Modify config: %s, DNS provider settings changed")
+	message.SetString(language.English, "修改配置: %s, 获取%s方式已修改", "Modify config: %s, the way to get %s changed")
+	message.SetString(language.English, "修改配置: %s, 手动设置的%s: %q => %q", "Modify config: %s, manually set %s: %q => %q")
+	message.SetString(language.English, "修改配置: %s, 新增%s域名 %s", "Modify config: %s, add %s domain %s")
+	message.SetString(language.English, "修改配置: %s, 删除%s域名 %s", "Modify config: %s, delete %s domain %s")
+
 	// config
 	message.SetString(language.English, "从网卡获得IPv4失败", "Failed to get IPv4 from network card")
 	message.SetString(language.English, "从网卡中获得IPv4失败! 网卡名: %s", "Failed to get IPv4 from network card! Network card name: %s")
+	message.SetString(language.English, "未填写网卡名, 将使用网卡 %s", "No network card is set, using network card %s")
 	message.SetString(language.English, "获取IPv4结果失败! 接口: %s ,返回值: %s", "Failed to get IPv4 result! Interface: %s ,Result: %s")
 	message.SetString(language.English, "获取%s结果失败! 未能成功执行命令：%s, 错误：%q, 退出状态码：%s", "Failed to get %s result! Command: %s, Error: %q, Exit status code: %s")
 	message.SetString(language.English, "获取%s结果失败! 命令: %s, 标准输出: %q", "Failed to get %s result! Command: %s, Stdout: %q")
 	message.SetString(language.English, "从网卡获得IPv6失败", "Failed to get IPv6 from network card")
 	message.SetString(language.English, "从网卡中获得IPv6失败! 网卡名: %s", "Failed to get IPv6 from network card! Network card name: %s")
+	message.SetString(language.English, "获取默认路由所在的网卡失败! 异常信息: %s", "Failed to get the network card of the default route! Exception: %s")
+	message.SetString(language.English, "网卡 %s 未分配全局IPv6地址, 将使用路由通告前缀得到的地址: %s", "Network card %s has no global IPv6 address, will use the address from router advertisement prefix: %s")
 	message.SetString(language.English, "获取IPv6结果失败! 接口: %s ,返回值: %s", "Failed to get IPv6 result! Interface: %s ,Result: %s")
 	message.SetString(language.English, "未找到第 %d 个IPv6地址! 将使用第一个IPv6地址", "%dth IPv6 address not found! Will use the first IPv6 address")
 	message.SetString(language.English, "IPv6匹配表达式 %s 不正确! 最小从1开始", "IPv6 match expression %s is incorrect! Minimum start from 1")
@@ -75,10 +136,16 @@ func init() {
 	message.SetString(language.English, "没有匹配到任何一个IPv6地址, 将使用第一个地址", "No IPv6 address matched, will use the first address")
 	message.SetString(language.English, "未能获取IPv4地址, 将不会更新", "Failed to get IPv4 address, will not update")
 	message.SetString(language.English, "未能获取IPv6地址, 将不会更新", "Failed to get IPv6 address, will not update")
+	message.SetString(language.English, "IPv4使用手动设置的IP: %s", "IPv4 uses the manually set IP: %s")
+	message.SetString(language.English, "IPv6使用手动设置的IP: %s", "IPv6 uses the manually set IP: %s")
+	message.SetString(language.English, "手动设置的IPv4 %s 不正确", "The manually set IPv4 %s is incorrect")
+	message.SetString(language.English, "手动设置的IPv6 %s 不正确", "The manually set IPv6 %s is incorrect")
+	message.SetString(language.English, "网卡 %s 只有链路本地/ULA的IPv6地址, 将跳过IPv6更新", "Network card %s only has link-local/ULA IPv6 addresses, IPv6 update will be skipped")
 
 	// domains
 	message.SetString(language.English, "域名: %s 不正确", "The domain %s is incorrect")
 	message.SetString(language.English, "域名: %s 解析失败", "The domain %s resolution failed")
+	message.SetString(language.English, "域名 %s 的 %s 记录本轮已推送过, 将跳过重复的更新", "The %[2]s record of domain %[1]s has already been pushed in this round, the duplicate update will be skipped")
 	message.SetString(language.English, "域名 %s 解析未找到，且因添加了参数 %s=%s 导致无法创建。本次更新已被忽略", "DNS resolution for domain %s was not found, and the creation failed due to the added parameter %s=%s. This update has been ignored.")
 	message.SetString(language.English, "IPv6未改变, 将等待 %d 次后与DNS服务商进行比对", "IPv6 has not changed, will wait %d times to compare with DNS provider")
 	message.SetString(language.English, "IPv4未改变, 将等待 %d 次后与DNS服务商进行比对", "IPv4 has not changed, will wait %d times to compare with DNS provider")
@@ -87,6 +154,10 @@ func init() {
 	message.SetString(language.English, "等待网络连接: %s", "Waiting for network connection: %s")
 	message.SetString(language.English, "%s 后重试...", "Retry after %s")
 	message.SetString(language.English, "网络已连接", "The network is connected")
+	message.SetString(language.English, "代理地址不正确! 异常信息: %s", "Invalid proxy URL! Exception: %s")
+	message.SetString(language.English, "代理地址 %s 不正确, 应以 http://、https:// 或 socks5:// 开头", "Invalid proxy URL %s, it should start with http://, https:// or socks5://")
+	message.SetString(language.English, "代理地址 %s 不正确, 没有主机名", "Invalid proxy URL %s, the host is missing")
+	message.SetString(language.English, "请求将通过代理 %s", "Requests will be sent through the proxy %s")
 
 	// main
 	message.SetString(language.English, "监听端口发生异常, 请检查端口是否被占用! %s", "Port listening failed, please check if the port is occupied! %s")
@@ -99,10 +170,66 @@ func init() {
 	message.SetString(language.English, "启动 ddns-go 服务成功", "started ddns-go service successfully")
 	message.SetString(language.English, "ddns-go 服务未安装, 请先安装服务", "ddns-go service is not installed, please install the service first")
 
+	message.SetString(language.English, "(此前重复 %d 次)", "(repeated %d times before)")
+
+	// 状态页
+	message.SetString(language.English, "正在运行", "Running")
+	message.SetString(language.English, "上次运行", "Last run")
+	message.SetString(language.English, "下次运行", "Next run")
+	message.SetString(language.English, "DNS服务商", "DNS provider")
+	message.SetString(language.English, "域名", "Domain")
+	message.SetString(language.English, "类型", "Type")
+	message.SetString(language.English, "状态", "Status")
+	message.SetString(language.English, "时间", "Time")
+	message.SetString(language.English, "需要修改配置", "check the config")
+	message.SetString(language.English, "下次运行时重试", "retrying on the next run")
+
 	// webhook通知
 	message.SetString(language.English, "未改变", "no changed")
 	message.SetString(language.English, "失败", "failed")
 	message.SetString(language.English, "成功", "success")
+	message.SetString(language.English, "维护中", "under maintenance")
+	message.SetString(language.English, "预演", "dry run")
+	message.SetString(language.English, "已更新, 等待生效", "updated, propagation pending")
+	message.SetString(language.English, "已删除", "deleted")
+	message.SetString(language.English, "重复的记录 %s %s 不是由ddns-go管理的, 不删除", "The duplicate record %s %s is not managed by ddns-go and is kept")
+	message.SetString(language.English, "将删除重复的记录 %s %s, 设置 confirm 后才会删除", "Would delete the duplicate record %s %s, set confirm to delete it")
+	message.SetString(language.English, "删除重复的记录 %s %s 失败! 异常信息: %s", "Failed to delete the duplicate record %s %s! Exception: %s")
+	message.SetString(language.English, "删除重复的记录 %s %s 成功!", "Deleted the duplicate record %s %s successfully!")
+	message.SetString(language.English, "备用DNS配置 %s 的主DNS配置 %s 不存在, 将每次都更新", "The primary DNS config %[2]s of the failover DNS config %[1]s does not exist, it is always updated")
+	message.SetString(language.English, "主DNS配置 %s 更新失败, 将使用备用DNS配置 %s 更新", "The primary DNS config %s failed to update, the failover DNS config %s will be used")
+	message.SetString(language.English, "备用DNS配置 %s 也更新失败", "The failover DNS config %s also failed to update")
+	message.SetString(language.English, "已由备用DNS配置 %s (%s) 更新", "Updated by the failover DNS config %s (%s)")
+	message.SetString(language.English, "域名 %s 更新失败, 需要检查配置(如AccessKey、权限、Endpoint), 重试不会成功", "Failed to update %s, check the config (e.g. AccessKey, permissions, endpoint), retrying will not help")
+	message.SetString(language.English, "域名 %s 的记录与配置的属性不同, 将更新记录", "The record of %s differs from the configured attributes and will be updated")
+	message.SetString(language.English, "确认域名 %s 的记录时查询失败! 异常信息: %s", "Failed to list the records of %s for verification! Exception: %s")
+	message.SetString(language.English, "更新后查询到域名 %s 的 %s 记录为 %v, 不是 %s", "After the update, %s has %s records %v instead of %s")
+	message.SetString(language.English, "预演: 将新增 %s 记录 %s -> %s", "Dry run: would create %s record %s -> %s")
+	message.SetString(language.English, "预演: 将更新 %s 记录 %s %s -> %s", "Dry run: would update %s record %s %s -> %s")
+	message.SetString(language.English, "预演: 将删除 %s 记录 %s -> %s", "Dry run: would delete %s record %s -> %s")
+	message.SetString(language.English, "通知渠道 %s 未配置", "The notification channel %s is not configured")
+	message.SetString(language.English, "不支持的通知渠道 %s", "Unsupported notification channel %s")
+	message.SetString(language.English, "阿里云API被限流或暂时不可用, 将在 %s 后重试: %s", "Alibaba Cloud API is throttled or temporarily unavailable, retrying after %s: %s")
+	message.SetString(language.English, "启动时IPv4先使用临时IP %s, 获取到IP后将更正", "Publishing the grace IPv4 %s on startup, it will be corrected once the IP is detected")
+	message.SetString(language.English, "启动时IPv6先使用临时IP %s, 获取到IP后将更正", "Publishing the grace IPv6 %s on startup, it will be corrected once the IP is detected")
+	message.SetString(language.English, "未获取到%s地址, 将保留%s记录", "No %s address, the %s records will be kept")
+	message.SetString(language.English, "未获取到%s地址, 将删除%s记录", "No %s address, the %s records will be deleted")
+	message.SetString(language.English, "未获取到%s地址, 将跳过本次更新", "No %s address, skipping this update")
+	message.SetString(language.English, "未获取到%s地址, 将删除添加了 missing=delete 的域名的%s记录", "No %s address, the %s records of the domains with missing=delete will be deleted")
+	message.SetString(language.English, "只获取到IPv4/IPv6其中一个时的处理方式 %s 不正确, 可选 keep/delete/skip", "Invalid partial policy %s, available: keep/delete/skip")
+	message.SetString(language.English, "DNS服务商 %s 不支持删除记录, 将保留记录", "DNS provider %s does not support deleting records, the records will be kept")
+	message.SetString(language.English, "DNS服务商 %s 不支持删除记录, 不能使用 state=absent", "DNS provider %s does not support deleting records, state=absent cannot be used")
+	message.SetString(language.English, "DNS服务商 %s 不支持删除记录, 将跳过 state=absent 的域名", "DNS provider %s does not support deleting records, domains with state=absent are skipped")
+	message.SetString(language.English, "删除域名解析 %s 失败! 异常信息: %s", "Failed to delete domain %s! Exception: %s")
+	message.SetString(language.English, "删除域名解析 %s 成功! 类型: %s, IP: %s", "Deleted domain %s successfully! Type: %s, IP: %s")
+	message.SetString(language.English, "查询CNAME记录 %s 失败! 异常信息: %s", "Failed to query CNAME record %s! Exception: %s")
+	message.SetString(language.English, "设置CNAME记录 %s 指向 %s 失败! 异常信息: %s", "Failed to point CNAME record %s to %s! Exception: %s")
+	message.SetString(language.English, "已设置CNAME记录 %s 指向 %s", "CNAME record %s now points to %s")
+	message.SetString(language.English, "服务商 %s 正在维护(503 Service Unavailable), 返回内容: %s", "Provider %s is under maintenance (503 Service Unavailable), response: %s")
+	message.SetString(language.English, "DNS服务商 %s 正在维护, 将在 %s 后重试", "DNS provider %s is under maintenance, will retry after %s")
+	message.SetString(language.English, "DNS服务商 %s 已结束维护", "DNS provider %s is no longer under maintenance")
+	message.SetString(language.English, "DNS服务商 %s 维护已超过 %s, 将视为更新失败", "DNS provider %s has been under maintenance for more than %s, treating it as an update failure")
+	message.SetString(language.English, "DNS服务商 %s 正在维护, 将在 %s 后重试, 超过 %s 仍未恢复时才通知失败", "DNS provider %s is under maintenance, will retry after %s. The failure is only notified if it lasts more than %s")
 
 	// Login
 	message.SetString(language.English, "%q 配置文件为空, 超过3小时禁止从公网访问", "%q configuration file is empty, public network access is prohibited for more than 3 hours")
@@ -118,11 +245,19 @@ func init() {
 }
 
 func Log(key string, args ...interface{}) {
-	log.Println(LogStr(key, args...))
+	msg := LogStr(key, args...)
+	ok, repeated := coalesceLog(msg, time.Now())
+	if !ok {
+		return
+	}
+	if repeated > 0 {
+		msg += " " + LogStr("(此前重复 %d 次)", repeated)
+	}
+	log.Println(msg)
 }
 
 func LogStr(key string, args ...interface{}) string {
-	return logPrinter.Sprintf(key, args...)
+	return MaskSecrets(logPrinter.Sprintf(key, args...))
 }
 
 func InitLogLang(lang string) string {
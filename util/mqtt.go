@@ -0,0 +1,181 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// mqttTimeout 连接/发布的超时时间
+const mqttTimeout = 10 * time.Second
+
+// MQTT 3.1.1 控制报文类型
+// http://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+const (
+	mqttConnect    byte = 0x10
+	mqttConnack    byte = 0x20
+	mqttPublish    byte = 0x30
+	mqttPuback     byte = 0x40
+	mqttDisconnect byte = 0xE0
+)
+
+// MQTTPublish 连接到 broker 并以 QoS 1 发布一条消息, 收到 PUBACK 后断开
+// broker 如 tcp://192.168.1.2:1883, ssl://broker.example.com:8883, 支持 tcp/mqtt/ssl/tls/mqtts
+func MQTTPublish(broker string, username string, password string, topic string, payload []byte, retain bool) error {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return err
+	}
+
+	useTLS := false
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+		port = "8883"
+	default:
+		return fmt.Errorf("unsupported MQTT scheme: %s", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	address := net.JoinHostPort(u.Hostname(), port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), mqttTimeout)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", dialAddress(address))
+	if err != nil {
+		return err
+	}
+	if useTLS {
+		tlsConf := &tls.Config{ServerName: u.Hostname()}
+		// 与 -skipVerify 一致
+		if defaultTransport.TLSClientConfig != nil {
+			tlsConf.InsecureSkipVerify = defaultTransport.TLSClientConfig.InsecureSkipVerify
+		}
+		conn = tls.Client(conn, tlsConf)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(mqttTimeout))
+
+	r := bufio.NewReader(conn)
+
+	// CONNECT
+	if _, err = conn.Write(mqttConnectPacket("ddns-go-"+strconv.FormatInt(time.Now().UnixNano(), 36), username, password)); err != nil {
+		return err
+	}
+	header, body, err := mqttReadPacket(r)
+	if err != nil {
+		return err
+	}
+	if header&0xF0 != mqttConnack || len(body) != 2 {
+		return errors.New("MQTT: invalid CONNACK")
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("MQTT: connection refused, return code %d", body[1])
+	}
+
+	// PUBLISH
+	const packetID = 1
+	if _, err = conn.Write(mqttPublishPacket(topic, payload, retain, packetID)); err != nil {
+		return err
+	}
+	header, body, err = mqttReadPacket(r)
+	if err != nil {
+		return err
+	}
+	if header&0xF0 != mqttPuback || len(body) != 2 || binary.BigEndian.Uint16(body) != packetID {
+		return errors.New("MQTT: invalid PUBACK")
+	}
+
+	// DISCONNECT
+	conn.Write([]byte{mqttDisconnect, 0})
+	return nil
+}
+
+// mqttConnectPacket CONNECT 报文, clean session, keep alive 60s
+func mqttConnectPacket(clientID string, username string, password string) []byte {
+	var flags byte = 0x02
+	payload := mqttString(clientID)
+	if username != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(username)...)
+		if password != "" {
+			flags |= 0x40
+			payload = append(payload, mqttString(password)...)
+		}
+	}
+
+	variable := append(mqttString("MQTT"), 4, flags, 0, 60)
+	return mqttPacket(mqttConnect, append(variable, payload...))
+}
+
+// mqttPublishPacket QoS 1 的 PUBLISH 报文
+func mqttPublishPacket(topic string, payload []byte, retain bool, packetID uint16) []byte {
+	header := mqttPublish | 0x02
+	if retain {
+		header |= 0x01
+	}
+	body := mqttString(topic)
+	body = binary.BigEndian.AppendUint16(body, packetID)
+	return mqttPacket(header, append(body, payload...))
+}
+
+// mqttPacket 添加固定报头
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	// 剩余长度, 每字节7位
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// mqttString 2字节长度前缀的UTF-8字符串
+func mqttString(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
+
+// mqttReadPacket 读取一个报文, 返回固定报头的第一个字节和剩余部分
+func mqttReadPacket(r *bufio.Reader) (header byte, body []byte, err error) {
+	header, err = r.ReadByte()
+	if err != nil {
+		return
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("MQTT: malformed remaining length")
+		}
+		var b byte
+		if b, err = r.ReadByte(); err != nil {
+			return
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body = make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return
+}
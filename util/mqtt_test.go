@@ -0,0 +1,68 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+)
+
+// TestMQTTPublish 测试与模拟的 broker 完成 CONNECT/PUBLISH
+func TestMQTTPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+
+	type received struct {
+		connect []byte
+		publish []byte
+		header  byte
+	}
+	ch := make(chan received, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var got received
+		_, got.connect, _ = mqttReadPacket(r)
+		conn.Write([]byte{mqttConnack, 2, 0, 0})
+		got.header, got.publish, _ = mqttReadPacket(r)
+		conn.Write([]byte{mqttPuback, 2, 0, 1})
+		mqttReadPacket(r)
+		ch <- got
+	}()
+
+	err = MQTTPublish("tcp://"+ln.Addr().String(), "user", "pass", "ddns-go/ip", []byte(`{"ip":"1.2.3.4"}`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-ch
+	if !bytes.Contains(got.connect, []byte("user")) || !bytes.Contains(got.connect, []byte("pass")) {
+		t.Errorf("CONNECT 中没有用户名密码: %q", got.connect)
+	}
+	if got.header != mqttPublish|0x02|0x01 {
+		t.Errorf("PUBLISH 报头错误: %#x", got.header)
+	}
+	want := append(append(mqttString("ddns-go/ip"), 0, 1), `{"ip":"1.2.3.4"}`...)
+	if !bytes.Equal(got.publish, want) {
+		t.Errorf("PUBLISH 内容错误: %q, want %q", got.publish, want)
+	}
+}
+
+// TestMQTTPacketLength 测试剩余长度的编码
+func TestMQTTPacketLength(t *testing.T) {
+	packet := mqttPacket(mqttPublish, make([]byte, 321))
+	if !bytes.Equal(packet[:3], []byte{mqttPublish, 0xC1, 0x02}) {
+		t.Errorf("剩余长度编码错误: % x", packet[:3])
+	}
+	header, body, err := mqttReadPacket(bufio.NewReader(bytes.NewReader(packet)))
+	if err != nil || header != mqttPublish || len(body) != 321 {
+		t.Errorf("mqttReadPacket = %#x, %d, %v", header, len(body), err)
+	}
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
@@ -42,3 +43,16 @@ func GetRequestIPStr(r *http.Request) (addr string) {
 	}
 	return addr
 }
+
+// SameIP 两个IP是否相同, 忽略IPv6的大小写、压缩格式和区域(如 %eth0), 无法解析时按字符串比较
+func SameIP(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ipA, errA := netip.ParseAddr(strings.TrimSpace(a))
+	ipB, errB := netip.ParseAddr(strings.TrimSpace(b))
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ipA.WithZone("").Unmap() == ipB.WithZone("").Unmap()
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"sync/atomic"
 
 	"golang.org/x/text/language"
 )
@@ -24,34 +25,69 @@ func InitBackupDNS(customDNS, lang string) {
 
 }
 
-// SetDNS sets the dialer.Resolver to use the given DNS server.
+// SetDNS sets the dialer.Resolver to use the given DNS servers.
+// Multiple servers are separated by commas and used in turn, e.g. "tcp://8.8.8.8, 1.1.1.1".
+// Names are then looked up as absolute names, without the local search domains,
+// so a split-horizon network can be pinned to the external view.
 func SetDNS(dns string) {
-
-	if !strings.Contains(dns, "://") {
-		dns = "udp://" + dns
+	type server struct {
+		network string
+		address string
 	}
-	svrParse, _ := url.Parse(dns)
+	var servers []server
 
-	var network string
-	switch strings.ToLower(svrParse.Scheme) {
-	case "tcp":
-		network = "tcp"
-	default:
-		network = "udp"
-	}
+	for _, s := range strings.Split(dns, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "://") {
+			s = "udp://" + s
+		}
+		svrParse, err := url.Parse(s)
+		if err != nil {
+			continue
+		}
+
+		network := "udp"
+		if strings.ToLower(svrParse.Scheme) == "tcp" {
+			network = "tcp"
+		}
 
-	if svrParse.Port() == "" {
-		dns = net.JoinHostPort(svrParse.Host, "53")
-	} else {
-		dns = svrParse.Host
+		address := svrParse.Host
+		if svrParse.Port() == "" {
+			address = net.JoinHostPort(svrParse.Host, "53")
+		}
+		servers = append(servers, server{network: network, address: address})
+	}
+	if len(servers) == 0 {
+		return
 	}
 
+	var next atomic.Uint32
 	dialer.Resolver = &net.Resolver{
 		PreferGo: true,
 		Dial: func(ctx context.Context, _, address string) (net.Conn, error) {
-			return net.Dial(network, dns)
+			svr := servers[int(next.Add(1)-1)%len(servers)]
+			return net.Dial(svr.network, svr.address)
 		},
 	}
+	rootedLookup.Store(true)
+}
+
+// rootedLookup 使用自定义DNS时不追加本机的搜索域
+var rootedLookup atomic.Bool
+
+// rootedAddress 将 host:port 中的域名转为绝对域名, 如 example.com:443 => example.com.:443
+func rootedAddress(address string) string {
+	if !rootedLookup.Load() {
+		return address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil || !strings.Contains(host, ".") || strings.HasSuffix(host, ".") {
+		return address
+	}
+	return net.JoinHostPort(host+".", port)
 }
 
 // LookupHost looks up the host based on the given URL using the dialer.Resolver.
@@ -36,3 +36,20 @@ func TestLookupHost(t *testing.T) {
 		}
 	})
 }
+
+func TestRootedAddress(t *testing.T) {
+	SetDNS("tcp://1.1.1.1, 8.8.8.8:53")
+
+	tests := map[string]string{
+		"example.com:443":  "example.com.:443",
+		"example.com.:443": "example.com.:443",
+		"localhost:80":     "localhost:80",
+		"1.2.3.4:80":       "1.2.3.4:80",
+		"[2001:db8::1]:80": "[2001:db8::1]:80",
+	}
+	for address, want := range tests {
+		if got := rootedAddress(address); got != want {
+			t.Errorf("rootedAddress(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
@@ -42,3 +42,25 @@ func TestGetRequestIPStr(t *testing.T) {
 		t.Errorf("GetRequestIPStr failed")
 	}
 }
+
+// TestSameIP 测试忽略IPv6的表示方式比较IP
+func TestSameIP(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001", true},
+		{"2001:DB8::1", "2001:db8::1", true},
+		{"fe80::1%eth0", "fe80::1", true},
+		{"::ffff:1.2.3.4", "1.2.3.4", true},
+		{"1.2.3.4", "1.2.3.4", true},
+		{"2001:db8::1", "2001:db8::2", false},
+		{"1.2.3.4", "", false},
+		{"example.com", "example.com", true},
+	}
+	for _, tt := range tests {
+		if got := SameIP(tt.a, tt.b); got != tt.same {
+			t.Errorf("SameIP(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package util
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// opcodeUpdate RFC 2136 DNS UPDATE
+const opcodeUpdate = 5
+
+// typeTSIG RFC 8945 TSIG
+const typeTSIG dnsmessage.Type = 250
+
+// tsigFudge 允许的时间误差(秒)
+const tsigFudge = 300
+
+// tsigAlgorithms 支持的TSIG算法, key 为 nsupdate 中使用的名称
+var tsigAlgorithms = map[string]struct {
+	name string
+	hash func() hash.Hash
+}{
+	"hmac-md5":    {"hmac-md5.sig-alg.reg.int.", md5.New},
+	"hmac-sha1":   {"hmac-sha1.", sha1.New},
+	"hmac-sha224": {"hmac-sha224.", sha256.New224},
+	"hmac-sha256": {"hmac-sha256.", sha256.New},
+	"hmac-sha384": {"hmac-sha384.", sha512.New384},
+	"hmac-sha512": {"hmac-sha512.", sha512.New},
+}
+
+// rcodeNames RFC 2136 等中的应答码
+var rcodeNames = map[dnsmessage.RCode]string{
+	6:  "YXDOMAIN",
+	7:  "YXRRSET",
+	8:  "NXRRSET",
+	9:  "NOTAUTH",
+	10: "NOTZONE",
+}
+
+// TSIGKey TSIG密钥, Algorithm 为空时使用 hmac-sha256
+type TSIGKey struct {
+	Name      string
+	Algorithm string
+	// Secret base64编码的密钥
+	Secret string
+}
+
+// DNSUpdate 通过 RFC 2136 动态更新替换 name 的 A/AAAA 记录, key 不为空时使用TSIG签名
+// server 为权威DNS服务器, 未指定端口时使用53。使用UDP发送, 应答被截断时使用TCP重试
+func DNSUpdate(server, zone, name string, ip netip.Addr, ttl uint32, key *TSIGKey) error {
+	id := uint16(rand.N(1 << 16))
+	msg, err := packDNSUpdate(id, zone, name, ip, ttl)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if msg, err = signTSIG(msg, id, key, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := exchangeDNS(ctx, "udp", server, msg)
+	if err == nil && len(resp) > 2 && resp[2]&0x02 != 0 {
+		// TC 被截断
+		resp, err = exchangeDNS(ctx, "tcp", server, msg)
+	}
+	if err != nil {
+		return err
+	}
+	return checkDNSUpdateResponse(resp, id)
+}
+
+// packDNSUpdate 生成更新消息: 删除 name 该类型的所有记录, 再添加新的记录
+func packDNSUpdate(id uint16, zone, name string, ip netip.Addr, ttl uint32) ([]byte, error) {
+	zoneName, err := dnsmessage.NewName(fqdn(zone))
+	if err != nil {
+		return nil, err
+	}
+	recordName, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, err
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opcodeUpdate})
+	// Zone 节
+	if err = b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err = b.Question(dnsmessage.Question{Name: zoneName, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	// Prerequisite 节为空, Update 节
+	if err = b.StartAuthorities(); err != nil {
+		return nil, err
+	}
+	recordType := dnsmessage.TypeA
+	if ip.Is6() && !ip.Is4In6() {
+		recordType = dnsmessage.TypeAAAA
+	}
+	// 删除RRset: CLASS 为 ANY, TTL 为 0, 没有数据
+	err = b.UnknownResource(
+		dnsmessage.ResourceHeader{Name: recordName, Class: dnsmessage.ClassANY},
+		dnsmessage.UnknownResource{Type: recordType},
+	)
+	if err != nil {
+		return nil, err
+	}
+	header := dnsmessage.ResourceHeader{Name: recordName, Class: dnsmessage.ClassINET, TTL: ttl}
+	if recordType == dnsmessage.TypeA {
+		err = b.AResource(header, dnsmessage.AResource{A: ip.Unmap().As4()})
+	} else {
+		err = b.AAAAResource(header, dnsmessage.AAAAResource{AAAA: ip.As16()})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// signTSIG 在消息末尾添加TSIG记录
+func signTSIG(msg []byte, id uint16, key *TSIGKey, now time.Time) ([]byte, error) {
+	algorithm := strings.ToLower(strings.TrimSuffix(key.Algorithm, "."))
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	alg, ok := tsigAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q", key.Algorithm)
+	}
+	secret, err := base64.StdEncoding.DecodeString(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TSIG secret: %s", err)
+	}
+	keyName, err := packCanonicalName(key.Name)
+	if err != nil {
+		return nil, err
+	}
+	algName, _ := packCanonicalName(alg.name)
+
+	timeSigned := make([]byte, 8)
+	binary.BigEndian.PutUint64(timeSigned, uint64(now.Unix()))
+	timeSigned = timeSigned[2:]
+
+	// TSIG 变量, 见 RFC 8945 4.3.3
+	mac := hmac.New(alg.hash, secret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write([]byte{0, byte(dnsmessage.ClassANY), 0, 0, 0, 0})
+	mac.Write(algName)
+	mac.Write(timeSigned)
+	mac.Write([]byte{tsigFudge >> 8, tsigFudge & 0xff, 0, 0, 0, 0})
+	sum := mac.Sum(nil)
+
+	rdata := append([]byte{}, algName...)
+	rdata = append(rdata, timeSigned...)
+	rdata = binary.BigEndian.AppendUint16(rdata, tsigFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = binary.BigEndian.AppendUint16(rdata, id)
+	// Error, Other Len
+	rdata = append(rdata, 0, 0, 0, 0)
+
+	signed := append([]byte{}, msg...)
+	signed = append(signed, keyName...)
+	signed = binary.BigEndian.AppendUint16(signed, uint16(typeTSIG))
+	signed = binary.BigEndian.AppendUint16(signed, uint16(dnsmessage.ClassANY))
+	signed = binary.BigEndian.AppendUint32(signed, 0)
+	signed = binary.BigEndian.AppendUint16(signed, uint16(len(rdata)))
+	signed = append(signed, rdata...)
+	// ARCOUNT + 1
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed, nil
+}
+
+// packCanonicalName 未压缩、小写的域名
+func packCanonicalName(name string) ([]byte, error) {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(strings.ToLower(name), "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0), nil
+}
+
+// exchangeDNS 发送DNS消息并读取应答, TCP 时消息前有2字节的长度
+func exchangeDNS(ctx context.Context, network, server string, msg []byte) ([]byte, error) {
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if network == "tcp" {
+		msg = append(binary.BigEndian.AppendUint16(nil, uint16(len(msg))), msg...)
+	}
+	if _, err = conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	if network == "tcp" {
+		var length [2]byte
+		if _, err = io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+		_, err = io.ReadFull(conn, resp)
+		return resp, err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	return buf[:n], err
+}
+
+// checkDNSUpdateResponse 检查更新的应答码
+func checkDNSUpdateResponse(resp []byte, id uint16) error {
+	var p dnsmessage.Parser
+	header, err := p.Start(resp)
+	if err != nil {
+		return err
+	}
+	if header.ID != id || !header.Response {
+		return errors.New("dns response id mismatch")
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		if name, ok := rcodeNames[header.RCode]; ok {
+			return errors.New("dns update failed: " + name)
+		}
+		return errors.New("dns update failed: " + header.RCode.String())
+	}
+	return nil
+}
+
+// fqdn 以 . 结尾的域名
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
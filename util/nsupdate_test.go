@@ -0,0 +1,123 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSUpdate(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	key := &TSIGKey{Name: "ddns-key", Secret: base64.StdEncoding.EncodeToString(secret)}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rcode atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte{}, buf[:n]...)
+			if err := verifyUpdate(req, secret); err != nil {
+				t.Error(err)
+			}
+			resp := append([]byte{}, req[:12]...)
+			resp[2] |= 0x80
+			resp[3] = byte(rcode.Load())
+			resp = append(resp[:4], 0, 0, 0, 0, 0, 0, 0, 0)
+			conn.WriteTo(resp, addr)
+		}
+	}()
+	// 测试返回前关闭连接并等待服务端退出
+	defer func() {
+		conn.Close()
+		<-done
+	}()
+
+	server := conn.LocalAddr().String()
+	if err := DNSUpdate(server, "example.com", "home.example.com", netip.MustParseAddr("1.2.3.4"), 600, key); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+
+	rcode.Store(9)
+	err = DNSUpdate(server, "example.com", "home.example.com", netip.MustParseAddr("2001:db8::1"), 600, key)
+	if err == nil || !strings.Contains(err.Error(), "NOTAUTH") {
+		t.Errorf("Expected NOTAUTH, got %v", err)
+	}
+}
+
+// verifyUpdate 检查更新消息的内容和TSIG签名
+func verifyUpdate(req []byte, secret []byte) error {
+	var p dnsmessage.Parser
+	header, err := p.Start(req)
+	if err != nil {
+		return err
+	}
+	if header.OpCode != opcodeUpdate {
+		return fmt.Errorf("opcode %d", header.OpCode)
+	}
+	q, err := p.Question()
+	if err != nil || q.Name.String() != "example.com." || q.Type != dnsmessage.TypeSOA {
+		return fmt.Errorf("zone %v, %v", q, err)
+	}
+	p.SkipAllQuestions()
+	p.SkipAllAnswers()
+
+	del, err := p.AuthorityHeader()
+	if err != nil || del.Class != dnsmessage.ClassANY || del.Length != 0 || del.Name.String() != "home.example.com." {
+		return fmt.Errorf("delete %v, %v", del, err)
+	}
+	p.SkipAuthority()
+	add, err := p.AuthorityHeader()
+	if err != nil || add.Class != dnsmessage.ClassINET || add.TTL != 600 || add.Type != del.Type {
+		return fmt.Errorf("add %v, %v", add, err)
+	}
+	p.SkipAllAuthorities()
+
+	tsig, err := p.AdditionalHeader()
+	if err != nil || tsig.Type != typeTSIG || tsig.Name.String() != "ddns-key." {
+		return fmt.Errorf("tsig %v, %v", tsig, err)
+	}
+	rr, _ := p.UnknownResource()
+
+	// 去掉TSIG记录后重新计算签名
+	keyName, _ := packCanonicalName("ddns-key")
+	start := len(req) - len(keyName) - 10 - int(tsig.Length)
+	msg := append([]byte{}, req[:start]...)
+	binary.BigEndian.PutUint16(msg[10:], binary.BigEndian.Uint16(msg[10:])-1)
+
+	algName, _ := packCanonicalName("hmac-sha256.")
+	data := rr.Data
+	timeSigned := data[len(algName) : len(algName)+6]
+	macSize := int(binary.BigEndian.Uint16(data[len(algName)+8:]))
+	sum := data[len(algName)+10 : len(algName)+10+macSize]
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write([]byte{0, 255, 0, 0, 0, 0})
+	mac.Write(algName)
+	mac.Write(timeSigned)
+	mac.Write([]byte{1, 44, 0, 0, 0, 0})
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return fmt.Errorf("invalid TSIG MAC")
+	}
+	return nil
+}
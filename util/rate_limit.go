@@ -0,0 +1,104 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	mu     sync.Mutex
+	qps    float64
+	tokens float64
+	last   time.Time
+}
+
+// take 取出一个令牌, 返回需要等待的时间
+func (b *tokenBucket) take() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	// 桶容量为 1 秒的请求数, 至少为 1
+	burst := b.qps
+	if burst < 1 {
+		burst = 1
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * b.qps
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.qps * float64(time.Second))
+}
+
+var rateLimiters = struct {
+	sync.RWMutex
+	buckets map[string]*tokenBucket
+}{buckets: map[string]*tokenBucket{}}
+
+// SetRateLimit 设置访问 host 的每秒请求数, qps <= 0 时不限流
+func SetRateLimit(host string, qps float64) {
+	rateLimiters.Lock()
+	defer rateLimiters.Unlock()
+
+	if qps <= 0 {
+		delete(rateLimiters.buckets, host)
+		return
+	}
+	if b, ok := rateLimiters.buckets[host]; ok {
+		b.mu.Lock()
+		b.qps = qps
+		b.mu.Unlock()
+		return
+	}
+	rateLimiters.buckets[host] = &tokenBucket{qps: qps}
+}
+
+// waitRateLimit 等待直到允许访问 host, ctx 取消时返回 ctx 的错误
+func waitRateLimit(ctx context.Context, host string) error {
+	rateLimiters.RLock()
+	b, ok := rateLimiters.buckets[host]
+	rateLimiters.RUnlock()
+	if !ok {
+		return nil
+	}
+	d := b.take()
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitTransport 按 host 限流的 http.RoundTripper
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := waitRateLimit(req.Context(), req.URL.Hostname()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestTokenBucket 测试令牌桶限流
+func TestTokenBucket(t *testing.T) {
+	b := &tokenBucket{qps: 2}
+
+	// 桶满时前 2 个请求无需等待
+	for i := 0; i < 2; i++ {
+		if d := b.take(); d != 0 {
+			t.Errorf("第 %d 个请求期待无需等待, 得到 %s", i+1, d)
+		}
+	}
+
+	// 第 3 个请求需要等待约 0.5 秒
+	if d := b.take(); d < 400*time.Millisecond || d > 500*time.Millisecond {
+		t.Errorf("第 3 个请求期待等待约 500ms, 得到 %s", d)
+	}
+}
+
+// TestWaitRateLimitCanceled 测试等待限流时 context 取消后立即返回
+func TestWaitRateLimitCanceled(t *testing.T) {
+	host := "ratelimit-cancel.example.com"
+	SetRateLimit(host, 0.1)
+	defer SetRateLimit(host, 0)
+
+	if err := waitRateLimit(context.Background(), host); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := waitRateLimit(ctx, host); err != context.DeadlineExceeded {
+		t.Errorf("期待 context.DeadlineExceeded, 得到 %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("context 取消后应立即返回, 等待了 %s", d)
+	}
+}
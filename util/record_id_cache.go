@@ -0,0 +1,91 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RecordIdCacheEntry 已知的域名记录, 用于跳过查询记录列表
+type RecordIdCacheEntry struct {
+	ZoneID   string // 根域名/站点ID
+	RecordID string // 记录ID
+	Value    string // 上次更新的值
+}
+
+var recordIdCache = struct {
+	sync.Mutex
+	entries map[string]RecordIdCacheEntry
+}{}
+
+// GetRecordIdCacheFilePath 记录ID缓存文件与配置文件在同一目录
+func GetRecordIdCacheFilePath() string {
+	return filepath.Join(filepath.Dir(GetConfigFilePath()), ".ddns_go_record_ids.json")
+}
+
+// loadRecordIdCache 首次使用时从文件加载
+func loadRecordIdCache() {
+	if recordIdCache.entries != nil {
+		return
+	}
+	recordIdCache.entries = map[string]RecordIdCacheEntry{}
+	byt, err := os.ReadFile(GetRecordIdCacheFilePath())
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(byt, &recordIdCache.entries); err != nil {
+		Log("异常信息: %s", err)
+	}
+}
+
+// saveRecordIdCache 保存到文件
+func saveRecordIdCache() {
+	byt, err := json.Marshal(recordIdCache.entries)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(GetRecordIdCacheFilePath(), byt, 0600); err != nil {
+		Log("异常信息: %s", err)
+	}
+}
+
+// GetRecordIdCache 获得缓存的记录
+func GetRecordIdCache(key string) (entry RecordIdCacheEntry, ok bool) {
+	recordIdCache.Lock()
+	defer recordIdCache.Unlock()
+	loadRecordIdCache()
+	entry, ok = recordIdCache.entries[key]
+	return
+}
+
+// SetRecordIdCache 缓存记录
+func SetRecordIdCache(key string, entry RecordIdCacheEntry) {
+	recordIdCache.Lock()
+	defer recordIdCache.Unlock()
+	loadRecordIdCache()
+	if recordIdCache.entries[key] == entry {
+		return
+	}
+	recordIdCache.entries[key] = entry
+	saveRecordIdCache()
+}
+
+// DeleteRecordIdCache 记录不存在时删除缓存
+func DeleteRecordIdCache(key string) {
+	recordIdCache.Lock()
+	defer recordIdCache.Unlock()
+	loadRecordIdCache()
+	if _, ok := recordIdCache.entries[key]; !ok {
+		return
+	}
+	delete(recordIdCache.entries, key)
+	saveRecordIdCache()
+}
+
+// ReloadRecordIdCache 恢复备份后重新从文件加载
+func ReloadRecordIdCache() {
+	recordIdCache.Lock()
+	defer recordIdCache.Unlock()
+	recordIdCache.entries = nil
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+	"gopkg.in/yaml.v3"
+)
+
+// 备份文件中的文件名
+const (
+	backupConfigName    = "config.yaml"
+	backupRecordIdsName = "record_ids.json"
+	backupHistoryName   = "update_history.json"
+)
+
+// Backup 下载包含配置文件、记录ID缓存和更新历史的备份, redact=true 时不包含密钥和密码, 见 config.Config.Redacted
+func Backup(writer http.ResponseWriter, request *http.Request) {
+	conf, _ := config.GetConfigCached()
+	if request.URL.Query().Get("redact") == "true" {
+		conf = conf.Redacted()
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	byt, err := yaml.Marshal(conf)
+	if err == nil {
+		err = writeZipFile(zw, backupConfigName, byt)
+	}
+	if err == nil {
+		if byt, readErr := os.ReadFile(util.GetRecordIdCacheFilePath()); readErr == nil {
+			err = writeZipFile(zw, backupRecordIdsName, byt)
+		}
+	}
+	if err == nil {
+		if byt, err = json.Marshal(config.GetUpdateHistory()); err == nil {
+			err = writeZipFile(zw, backupHistoryName, byt)
+		}
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		returnError(writer, err.Error())
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/zip")
+	writer.Header().Set("Content-Disposition", "attachment; filename=ddns-go-backup-"+time.Now().Format("20060102150405")+".zip")
+	writer.Write(buf.Bytes())
+}
+
+func writeZipFile(zw *zip.Writer, name string, byt []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(byt)
+	return err
+}
+
+// Restore 从备份中恢复配置、记录ID缓存和更新历史
+// 备份中密钥/密码为空时, 保留当前的值, DNS配置的 Secret 按服务商和 ID 对应
+func Restore(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	result := restore(request)
+	if result != "ok" {
+		returnError(writer, result)
+		return
+	}
+	returnOK(writer, result, nil)
+}
+
+func restore(request *http.Request) string {
+	body, err := io.ReadAll(io.LimitReader(request.Body, 10*1024*1024))
+	if err != nil {
+		return err.Error()
+	}
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return util.LogStr("数据解析失败, 请刷新页面重试") + ": " + err.Error()
+	}
+
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return err.Error()
+		}
+		byt, err := io.ReadAll(io.LimitReader(rc, 10*1024*1024))
+		rc.Close()
+		if err != nil {
+			return err.Error()
+		}
+		files[filepath.Base(f.Name)] = byt
+	}
+
+	byt, ok := files[backupConfigName]
+	if !ok {
+		return util.LogStr("数据解析失败, 请刷新页面重试") + ": " + backupConfigName + " not found"
+	}
+	var conf config.Config
+	if err = yaml.Unmarshal(byt, &conf); err != nil {
+		return util.LogStr("数据解析失败, 请刷新页面重试") + ": " + err.Error()
+	}
+
+	// 未包含密钥/密码的备份, 保留当前的值
+	oldConf, _ := config.GetConfigCached()
+	if conf.Password == "" {
+		conf.Password = oldConf.Password
+	}
+	conf.KeepSecrets(&oldConf)
+
+	var history []config.UpdateHistory
+	if byt, ok := files[backupHistoryName]; ok {
+		if err = json.Unmarshal(byt, &history); err != nil {
+			return util.LogStr("数据解析失败, 请刷新页面重试") + ": " + err.Error()
+		}
+	}
+
+	if result := validateConfig(&conf); result != "" {
+		return result
+	}
+	// 配置保存成功后再恢复记录ID缓存和更新历史
+	err = conf.SaveConfig()
+	if byt, ok := files[backupRecordIdsName]; ok && err == nil {
+		if err = os.WriteFile(util.GetRecordIdCacheFilePath(), byt, 0600); err == nil {
+			util.ReloadRecordIdCache()
+		}
+	}
+	if _, ok := files[backupHistoryName]; ok && err == nil {
+		config.SetUpdateHistory(history)
+	}
+
+	runOnceForced()
+
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
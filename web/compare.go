@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// Compare 保存前与当前运行的配置比较, 返回将要发生的变化
+func Compare(writer http.ResponseWriter, request *http.Request) {
+	conf, _ := config.GetConfigCached()
+
+	var data saveData
+	err := json.NewDecoder(request.Body).Decode(&data)
+	if err != nil {
+		returnError(writer, util.LogStr("数据解析失败, 请刷新页面重试"))
+		return
+	}
+
+	// 国际化
+	util.InitLogLang(request.Header.Get("Accept-Language"))
+
+	returnOK(writer, "", diffDnsConf(conf.DnsConf, dnsConfFromJS(&conf, data.DnsConf)))
+}
+
+// diffDnsConf 比较新旧配置, newConf 与页面中的序号一一对应, 已删除的配置为 nil
+func diffDnsConf(oldConf []config.DnsConfig, newConf []*config.DnsConfig) (changes []string) {
+	changes = []string{}
+	for k, n := range newConf {
+		if k >= len(oldConf) {
+			if n != nil {
+				changes = append(changes, util.LogStr("新增配置: %s", confName(k, n)))
+			}
+			continue
+		}
+
+		o := &oldConf[k]
+		if n == nil {
+			changes = append(changes, util.LogStr("删除配置: %s", confName(k, o)))
+			continue
+		}
+
+		name := confName(k, n)
+		if o.DNS != n.DNS || o.TTL != n.TTL {
+			changes = append(changes, util.LogStr("修改配置: %s, DNS服务商设置已修改", name))
+		}
+		if o.Ipv4.Enable != n.Ipv4.Enable || o.Ipv4.GetType != n.Ipv4.GetType || o.Ipv4.URL != n.Ipv4.URL ||
+			o.Ipv4.NetInterface != n.Ipv4.NetInterface || o.Ipv4.Cmd != n.Ipv4.Cmd {
+			changes = append(changes, util.LogStr("修改配置: %s, 获取%s方式已修改", name, "IPv4"))
+		}
+		if o.Ipv6.Enable != n.Ipv6.Enable || o.Ipv6.GetType != n.Ipv6.GetType || o.Ipv6.URL != n.Ipv6.URL ||
+			o.Ipv6.NetInterface != n.Ipv6.NetInterface || o.Ipv6.Cmd != n.Ipv6.Cmd || o.Ipv6.Ipv6Reg != n.Ipv6.Ipv6Reg {
+			changes = append(changes, util.LogStr("修改配置: %s, 获取%s方式已修改", name, "IPv6"))
+		}
+		if o.Ipv4.ManualIP != n.Ipv4.ManualIP {
+			changes = append(changes, util.LogStr("修改配置: %s, 手动设置的%s: %q => %q", name, "IPv4", o.Ipv4.ManualIP, n.Ipv4.ManualIP))
+		}
+		if o.Ipv6.ManualIP != n.Ipv6.ManualIP {
+			changes = append(changes, util.LogStr("修改配置: %s, 手动设置的%s: %q => %q", name, "IPv6", o.Ipv6.ManualIP, n.Ipv6.ManualIP))
+		}
+		changes = append(changes, diffDomains(name, "IPv4", o.Ipv4.Domains, n.Ipv4.Domains)...)
+		changes = append(changes, diffDomains(name, "IPv6", o.Ipv6.Domains, n.Ipv6.Domains)...)
+	}
+
+	// 页面中不存在的旧配置
+	for k := len(newConf); k < len(oldConf); k++ {
+		changes = append(changes, util.LogStr("删除配置: %s", confName(k, &oldConf[k])))
+	}
+	return
+}
+
+// diffDomains 比较新旧域名列表
+func diffDomains(name, ipType string, oldDomains, newDomains []string) (changes []string) {
+	oldSet := domainSet(oldDomains)
+	newSet := domainSet(newDomains)
+	for _, d := range newDomains {
+		d = strings.TrimSpace(d)
+		if d != "" && !oldSet[d] {
+			changes = append(changes, util.LogStr("修改配置: %s, 新增%s域名 %s", name, ipType, d))
+		}
+	}
+	for _, d := range oldDomains {
+		d = strings.TrimSpace(d)
+		if d != "" && !newSet[d] {
+			changes = append(changes, util.LogStr("修改配置: %s, 删除%s域名 %s", name, ipType, d))
+		}
+	}
+	return
+}
+
+func domainSet(domains []string) map[string]bool {
+	set := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		set[strings.TrimSpace(d)] = true
+	}
+	return set
+}
+
+// confName 配置名称, 与页面中的显示一致
+func confName(k int, conf *config.DnsConfig) string {
+	if conf.Name != "" {
+		return conf.Name
+	}
+	return util.WriteString(strconv.Itoa(k+1), " - ", conf.DNS.Name)
+}
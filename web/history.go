@@ -0,0 +1,39 @@
+package web
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// History 下载CSV格式的更新历史
+func History(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer.Header().Set("Content-Disposition", "attachment; filename=ddns-go-history-"+time.Now().Format("20060102150405")+".csv")
+
+	w := csv.NewWriter(writer)
+	w.Write([]string{"time", "provider", "domain", "type", "old_ip", "new_ip", "status",
+		"requests", "retries", "dns_ms", "connect_ms", "tls_ms", "total_ms"})
+	for _, h := range config.GetUpdateHistory() {
+		w.Write([]string{
+			h.Time.Format(time.RFC3339),
+			h.Provider,
+			h.Domain,
+			h.RecordType,
+			h.OldIP,
+			h.NewIP,
+			util.LogStr(string(h.Status)),
+			strconv.Itoa(h.HTTP.Requests),
+			strconv.Itoa(h.HTTP.Retries),
+			strconv.FormatInt(h.HTTP.DNS.Milliseconds(), 10),
+			strconv.FormatInt(h.HTTP.Connect.Milliseconds(), 10),
+			strconv.FormatInt(h.HTTP.TLS.Milliseconds(), 10),
+			strconv.FormatInt(h.HTTP.Total.Milliseconds(), 10),
+		})
+	}
+	w.Flush()
+}
@@ -5,7 +5,8 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
+
+	"github.com/jeessy2/ddns-go/v6/util"
 )
 
 // MemoryLogs 内存中的日志
@@ -27,7 +28,8 @@ var mlogs = &MemoryLogs{MaxNum: 50}
 
 // 初始化日志
 func init() {
-	log.SetOutput(io.MultiWriter(mlogs, os.Stdout))
+	// 日志中的密钥替换为 ***
+	log.SetOutput(util.MaskWriter(io.MultiWriter(mlogs, util.LogWriter)))
 	// log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 }
 
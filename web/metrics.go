@@ -0,0 +1,72 @@
+package web
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jeessy2/ddns-go/v6/dns"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// metricLabelReplacer 转义 Prometheus 标签值
+var metricLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics 以 Prometheus 文本格式输出记录操作的统计, 只允许内网访问
+func Metrics(writer http.ResponseWriter, request *http.Request) {
+	if !util.IsPrivateNetwork(request.RemoteAddr) {
+		writer.WriteHeader(http.StatusForbidden)
+		util.Log("%q 被禁止从公网访问", util.GetRequestIPStr(request))
+		return
+	}
+
+	m := dns.GetMetrics()
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(writer, "# HELP ddns_go_record_operations_total Record operations since start.")
+	fmt.Fprintln(writer, "# TYPE ddns_go_record_operations_total counter")
+	for _, key := range sortedMetricKeys(m.Total) {
+		fmt.Fprintf(writer, "ddns_go_record_operations_total{%s} %d\n", metricLabels(key), m.Total[key])
+	}
+
+	fmt.Fprintln(writer, "# HELP ddns_go_record_operations_last_run Record operations in the last run.")
+	fmt.Fprintln(writer, "# TYPE ddns_go_record_operations_last_run gauge")
+	for _, key := range sortedMetricKeys(m.LastRun) {
+		fmt.Fprintf(writer, "ddns_go_record_operations_last_run{%s} %d\n", metricLabels(key), m.LastRun[key])
+	}
+
+	fmt.Fprintln(writer, "# HELP ddns_go_last_success_timestamp_seconds Time of the last successful update of the record.")
+	fmt.Fprintln(writer, "# TYPE ddns_go_last_success_timestamp_seconds gauge")
+	for _, key := range sortedMetricKeys(m.LastSuccess) {
+		fmt.Fprintf(writer, "ddns_go_last_success_timestamp_seconds{%s} %d\n", metricLabels(key), m.LastSuccess[key].Unix())
+	}
+}
+
+// sortedMetricKeys 按服务商、域名、记录类型、操作排序, 输出的顺序固定
+func sortedMetricKeys[V any](m map[dns.MetricKey]V) []dns.MetricKey {
+	keys := make([]dns.MetricKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b dns.MetricKey) int {
+		return cmp.Or(
+			cmp.Compare(a.Provider, b.Provider),
+			cmp.Compare(a.Domain, b.Domain),
+			cmp.Compare(a.RecordType, b.RecordType),
+			cmp.Compare(a.Action, b.Action),
+		)
+	})
+	return keys
+}
+
+// metricLabels 统计的维度转为 Prometheus 标签, Action 为空时不输出
+func metricLabels(key dns.MetricKey) string {
+	labels := fmt.Sprintf(`provider="%s",domain="%s",type="%s"`,
+		metricLabelReplacer.Replace(key.Provider), metricLabelReplacer.Replace(key.Domain), key.RecordType)
+	if key.Action != "" {
+		labels += fmt.Sprintf(`,action="%s"`, key.Action)
+	}
+	return labels
+}
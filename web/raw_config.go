@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// RawConfig 以JSON显示/编辑完整的配置, ID/Secret 隐藏显示, 不显示密码和其它密钥, 见 config.Config.Redacted
+func RawConfig(writer http.ResponseWriter, request *http.Request) {
+	if request.Method == http.MethodPost {
+		result := saveRawConfig(request)
+		if result != "ok" {
+			returnError(writer, result)
+			return
+		}
+		returnOK(writer, result, nil)
+		return
+	}
+
+	conf, _ := config.GetConfigCached()
+	redacted := conf.Redacted()
+	for i := range redacted.DnsConf {
+		redacted.DnsConf[i].DNS.ID, redacted.DnsConf[i].DNS.Secret = getHideIDSecret(&conf.DnsConf[i])
+	}
+
+	byt, _ := json.MarshalIndent(redacted, "", "  ")
+	returnOK(writer, "", string(byt))
+}
+
+// saveRawConfig 保存页面提交的原始配置
+func saveRawConfig(request *http.Request) string {
+	oldConf, _ := config.GetConfigCached()
+
+	var conf config.Config
+	err := json.NewDecoder(request.Body).Decode(&conf)
+	if err != nil {
+		return util.LogStr("数据解析失败, 请刷新页面重试") + ": " + err.Error()
+	}
+
+	// 国际化
+	conf.Lang = util.InitLogLang(request.Header.Get("Accept-Language"))
+
+	// 未修改隐藏显示的ID/Secret时, 使用原来的值
+	for k := range conf.DnsConf {
+		if k >= len(oldConf.DnsConf) {
+			break
+		}
+		c := &oldConf.DnsConf[k]
+		idHide, secretHide := getHideIDSecret(c)
+		if conf.DnsConf[k].DNS.ID == idHide {
+			conf.DnsConf[k].DNS.ID = c.DNS.ID
+		}
+		if conf.DnsConf[k].DNS.Secret == secretHide {
+			conf.DnsConf[k].DNS.Secret = c.DNS.Secret
+		}
+	}
+
+	// 未显示的密钥为空时, 使用原来的值
+	conf.KeepSecrets(&oldConf)
+
+	// 密码为空时不修改, 否则与页面中一样检查强度
+	if conf.Password == "" {
+		conf.Password = oldConf.Password
+	} else {
+		hashedPwd, err := conf.CheckPassword(conf.Password)
+		if err != nil {
+			return err.Error()
+		}
+		conf.Password = hashedPwd
+	}
+
+	return validateAndSave(&conf)
+}
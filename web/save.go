@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"strings"
 
@@ -23,19 +24,26 @@ func Save(writer http.ResponseWriter, request *http.Request) {
 	writer.Write(byt)
 }
 
+// saveData 保存时从页面提交的数据
+type saveData struct {
+	Username           string       `json:"Username"`
+	Password           string       `json:"Password"`
+	NotAllowWanAccess  bool         `json:"NotAllowWanAccess"`
+	WebhookURL         string       `json:"WebhookURL"`
+	WebhookRequestBody string       `json:"WebhookRequestBody"`
+	WebhookHeaders     string       `json:"WebhookHeaders"`
+	DnsConf            []dnsConf4JS `json:"DnsConf"`
+
+	WebhookRequestBodyChanged      string `json:"WebhookRequestBodyChanged"`
+	WebhookRequestBodyUpdateFailed string `json:"WebhookRequestBodyUpdateFailed"`
+	WebhookRequestBodyDetectFailed string `json:"WebhookRequestBodyDetectFailed"`
+}
+
 func checkAndSave(request *http.Request) string {
 	conf, _ := config.GetConfigCached()
 
 	// 从请求中读取 JSON 数据
-	var data struct {
-		Username           string       `json:"Username"`
-		Password           string       `json:"Password"`
-		NotAllowWanAccess  bool         `json:"NotAllowWanAccess"`
-		WebhookURL         string       `json:"WebhookURL"`
-		WebhookRequestBody string       `json:"WebhookRequestBody"`
-		WebhookHeaders     string       `json:"WebhookHeaders"`
-		DnsConf            []dnsConf4JS `json:"DnsConf"`
-	}
+	var data saveData
 
 	// 解析请求中的 JSON 数据
 	err := json.NewDecoder(request.Body).Decode(&data)
@@ -53,6 +61,9 @@ func checkAndSave(request *http.Request) string {
 	conf.WebhookURL = strings.TrimSpace(data.WebhookURL)
 	conf.WebhookRequestBody = strings.TrimSpace(data.WebhookRequestBody)
 	conf.WebhookHeaders = strings.TrimSpace(data.WebhookHeaders)
+	conf.WebhookRequestBodyChanged = strings.TrimSpace(data.WebhookRequestBodyChanged)
+	conf.WebhookRequestBodyUpdateFailed = strings.TrimSpace(data.WebhookRequestBodyUpdateFailed)
+	conf.WebhookRequestBodyDetectFailed = strings.TrimSpace(data.WebhookRequestBodyDetectFailed)
 
 	// 如果新密码不为空则检查是否够强, 内/外网要求强度不同
 	conf.Username = usernameNew
@@ -64,13 +75,75 @@ func checkAndSave(request *http.Request) string {
 		conf.Password = hashedPwd
 	}
 
+	var dnsConfArray []config.DnsConfig
+	for _, dnsConf := range dnsConfFromJS(&conf, data.DnsConf) {
+		if dnsConf != nil {
+			dnsConfArray = append(dnsConfArray, *dnsConf)
+		}
+	}
+	conf.DnsConf = dnsConfArray
+
+	return validateAndSave(&conf)
+}
+
+// validateAndSave 校验并保存配置, 页面表单与原始配置共用
+func validateAndSave(conf *config.Config) string {
+	if result := validateConfig(conf); result != "" {
+		return result
+	}
+
+	// 保存到用户目录
+	err := conf.SaveConfig()
+
+	runOnceForced()
+
+	// 回写错误信息
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+// validateConfig 校验配置, 正确时返回空字符串
+func validateConfig(conf *config.Config) string {
 	// 帐号密码不能为空
 	if conf.Username == "" || conf.Password == "" {
 		return util.LogStr("必须输入用户名/密码")
 	}
 
-	dnsConfFromJS := data.DnsConf
-	var dnsConfArray []config.DnsConfig
+	// 代理不正确时启动会失败
+	if _, err := util.ParseProxy(conf.Proxy); err != nil {
+		return err.Error()
+	}
+
+	for k, dnsConf := range conf.DnsConf {
+		if strings.Join(dnsConf.Ipv4.Domains, "") == "" && strings.Join(dnsConf.Ipv6.Domains, "") == "" {
+			util.Log("第 %s 个配置未填写域名", util.Ordinal(k+1, conf.Lang))
+		}
+		// 手动设置的IP需与类型一致
+		if ip := net.ParseIP(dnsConf.Ipv4.ManualIP); dnsConf.Ipv4.ManualIP != "" && (ip == nil || ip.To4() == nil) {
+			return util.LogStr("手动设置的IPv4 %s 不正确", dnsConf.Ipv4.ManualIP)
+		}
+		if ip := net.ParseIP(dnsConf.Ipv6.ManualIP); dnsConf.Ipv6.ManualIP != "" && (ip == nil || ip.To4() != nil) {
+			return util.LogStr("手动设置的IPv6 %s 不正确", dnsConf.Ipv6.ManualIP)
+		}
+		if dnsConf.HasAbsentDomains() && !dns.SupportsDelete(dnsConf.DNS.Name) {
+			return util.LogStr("DNS服务商 %s 不支持删除记录, 不能使用 state=absent", dnsConf.DNS.Name)
+		}
+	}
+	return ""
+}
+
+// runOnceForced 保存配置后按新配置运行一次
+func runOnceForced() {
+	util.ForceCompareGlobal = true
+	go dns.RunOnce()
+}
+
+// dnsConfFromJS 将页面中的dns配置转换为 config.DnsConfig
+// 返回的切片与页面中的序号一一对应, 已删除的配置为 nil
+func dnsConfFromJS(conf *config.Config, dnsConfFromJS []dnsConf4JS) []*config.DnsConfig {
+	dnsConfArray := make([]*config.DnsConfig, len(dnsConfFromJS))
 	empty := dnsConf4JS{}
 	for k, v := range dnsConfFromJS {
 		if v == empty {
@@ -82,16 +155,14 @@ func checkAndSave(request *http.Request) string {
 		dnsConf.DNS.ID = strings.TrimSpace(v.DnsID)
 		dnsConf.DNS.Secret = strings.TrimSpace(v.DnsSecret)
 		dnsConf.DNS.ExtParam = strings.TrimSpace(v.DnsExtParam)
-
-		if v.Ipv4Domains == "" && v.Ipv6Domains == "" {
-			util.Log("第 %s 个配置未填写域名", util.Ordinal(k+1, conf.Lang))
-		}
+		dnsConf.DNS.Endpoint = strings.TrimSpace(v.DnsEndpoint)
 
 		dnsConf.Ipv4.Enable = v.Ipv4Enable
 		dnsConf.Ipv4.GetType = v.Ipv4GetType
 		dnsConf.Ipv4.URL = strings.TrimSpace(v.Ipv4Url)
 		dnsConf.Ipv4.NetInterface = v.Ipv4NetInterface
 		dnsConf.Ipv4.Cmd = strings.TrimSpace(v.Ipv4Cmd)
+		dnsConf.Ipv4.ManualIP = strings.TrimSpace(v.Ipv4ManualIP)
 		dnsConf.Ipv4.Domains = util.SplitLines(v.Ipv4Domains)
 
 		dnsConf.Ipv6.Enable = v.Ipv6Enable
@@ -100,6 +171,7 @@ func checkAndSave(request *http.Request) string {
 		dnsConf.Ipv6.NetInterface = v.Ipv6NetInterface
 		dnsConf.Ipv6.Cmd = strings.TrimSpace(v.Ipv6Cmd)
 		dnsConf.Ipv6.Ipv6Reg = strings.TrimSpace(v.Ipv6Reg)
+		dnsConf.Ipv6.ManualIP = strings.TrimSpace(v.Ipv6ManualIP)
 		dnsConf.Ipv6.Domains = util.SplitLines(v.Ipv6Domains)
 
 		if k < len(conf.DnsConf) {
@@ -111,22 +183,36 @@ func checkAndSave(request *http.Request) string {
 			if dnsConf.DNS.Secret == secretHide {
 				dnsConf.DNS.Secret = c.DNS.Secret
 			}
+			// 页面中没有的配置保持不变
+			dnsConf.RecordIdCache = c.RecordIdCache
+			dnsConf.Retry = c.Retry
+			dnsConf.RetryDelay = c.RetryDelay
+			dnsConf.HTTPTimeout = c.HTTPTimeout
+			dnsConf.ProviderTTL = c.ProviderTTL
+			dnsConf.ExpandSubDomains = c.ExpandSubDomains
+			dnsConf.Ipv4.Transform = c.Ipv4.Transform
+			dnsConf.Ipv6.Transform = c.Ipv6.Transform
+			dnsConf.RecordName = c.RecordName
+			dnsConf.Ipv6.AllowULA = c.Ipv6.AllowULA
+			dnsConf.Ipv6.FromRA = c.Ipv6.FromRA
+			dnsConf.Ipv6.Select = c.Ipv6.Select
+			dnsConf.Ipv4.Consensus = c.Ipv4.Consensus
+			dnsConf.Ipv6.Consensus = c.Ipv6.Consensus
+			dnsConf.Partial = c.Partial
+			dnsConf.DryRun = c.DryRun
+			dnsConf.VerifyRecord = c.VerifyRecord
+			dnsConf.Failover = c.Failover
+			dnsConf.Cleanup = c.Cleanup
+			dnsConf.RecentIP = c.RecentIP
+			dnsConf.DomainConcurrency = c.DomainConcurrency
+			dnsConf.Callback = c.Callback
+			dnsConf.Propagation = c.Propagation
+			dnsConf.Ipv4.GraceIP = c.Ipv4.GraceIP
+			dnsConf.Ipv6.GraceIP = c.Ipv6.GraceIP
+			dnsConf.Combined = c.Combined
 		}
 
-		dnsConfArray = append(dnsConfArray, dnsConf)
+		dnsConfArray[k] = &dnsConf
 	}
-	conf.DnsConf = dnsConfArray
-
-	// 保存到用户目录
-	err = conf.SaveConfig()
-
-	// 只运行一次
-	util.ForceCompareGlobal = true
-	go dns.RunOnce()
-
-	// 回写错误信息
-	if err != nil {
-		return err.Error()
-	}
-	return "ok"
+	return dnsConfArray
 }
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/jeessy2/ddns-go/v6/config"
+	"github.com/jeessy2/ddns-go/v6/dns"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+//go:embed status.html
+var statusEmbedFile embed.FS
+
+// configStatus 每个配置的下次运行时间, 目前所有配置使用相同的间隔
+type configStatus struct {
+	Name    string
+	NextRun time.Time
+}
+
+// statusSnapshot /status 和不需要JavaScript的状态页共用的运行状态
+type statusSnapshot struct {
+	dns.RunStatus
+	NextRunIn int
+	Configs   []configStatus
+	Domains   []dns.DomainStatus
+}
+
+// getStatusSnapshot 获得当前的运行状态
+func getStatusSnapshot() statusSnapshot {
+	status := dns.GetRunStatus()
+
+	// 距下次运行的秒数, 避免浏览器与服务器的时间不一致
+	nextRunIn := 0
+	if !status.NextRun.IsZero() {
+		nextRunIn = max(int(time.Until(status.NextRun).Seconds()), 0)
+	}
+
+	conf, _ := config.GetConfigCached()
+	configs := make([]configStatus, 0, len(conf.DnsConf))
+	for _, dc := range conf.DnsConf {
+		configs = append(configs, configStatus{Name: dc.Name, NextRun: status.NextRun})
+	}
+
+	return statusSnapshot{status, nextRunIn, configs, dns.GetDomainStatuses()}
+}
+
+// Status 定时任务的运行状态, 页面中显示下次运行的倒计时
+func Status(writer http.ResponseWriter, request *http.Request) {
+	returnOK(writer, "", getStatusSnapshot())
+}
+
+// StatusPage 服务端生成的状态页, 不需要JavaScript, 用于路由器/NAS的简易浏览器或 curl, 只允许内网访问
+func StatusPage(writer http.ResponseWriter, request *http.Request) {
+	if !util.IsPrivateNetwork(request.RemoteAddr) {
+		writer.WriteHeader(http.StatusForbidden)
+		util.Log("%q 被禁止从公网访问", util.GetRequestIPStr(request))
+		return
+	}
+
+	tmpl, err := template.New("status.html").Funcs(template.FuncMap{
+		"t": func(key string) string { return util.LogStr(key) },
+	}).ParseFS(statusEmbedFile, "status.html")
+	if err != nil {
+		fmt.Println("Error happened..")
+		fmt.Println(err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err = tmpl.Execute(writer, getStatusSnapshot()); err != nil {
+		fmt.Println("Error happened..")
+		fmt.Println(err)
+	}
+}
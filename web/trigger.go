@@ -0,0 +1,20 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/jeessy2/ddns-go/v6/dns"
+	"github.com/jeessy2/ddns-go/v6/util"
+)
+
+// Trigger 外部通知IP可能已改变, 如 PPP 拨号脚本中调用, 只允许内网访问
+func Trigger(writer http.ResponseWriter, request *http.Request) {
+	if !util.IsPrivateNetwork(request.RemoteAddr) {
+		writer.WriteHeader(http.StatusForbidden)
+		util.Log("%q 被禁止从公网访问", util.GetRequestIPStr(request))
+		return
+	}
+
+	dns.Trigger("HTTP " + util.GetRequestIPStr(request))
+	returnOK(writer, "ok", nil)
+}
@@ -13,42 +13,58 @@ func WebhookTest(writer http.ResponseWriter, request *http.Request) {
 		URL         string `json:"URL"`
 		RequestBody string `json:"RequestBody"`
 		Headers     string `json:"Headers"`
+
+		RequestBodyChanged string `json:"RequestBodyChanged"`
 	}
 	err := json.NewDecoder(request.Body).Decode(&data)
 	if err != nil {
-		util.Log("数据解析失败, 请刷新页面重试")
+		returnError(writer, util.LogStr("数据解析失败, 请刷新页面重试"))
 		return
 	}
 
-	url := data.URL
-	requestBody := data.RequestBody
-	headers := data.Headers
-
-	if url == "" {
-		util.Log("请输入Webhook的URL")
+	if data.URL == "" {
+		returnError(writer, util.LogStr("请输入Webhook的URL"))
 		return
 	}
 
-	var domains = make([]*config.Domain, 1)
-	domains[0] = &config.Domain{}
-	domains[0].DomainName = "example.com"
-	domains[0].SubDomain = "test"
-	domains[0].UpdateStatus = config.UpdatedSuccess
-
-	fakeDomains := &config.Domains{
-		Ipv4Addr:    "127.0.0.1",
-		Ipv4Domains: domains,
-		Ipv6Addr:    "::1",
-		Ipv6Domains: domains,
-	}
-
 	fakeConfig := &config.Config{
 		Webhook: config.Webhook{
-			WebhookURL:         url,
-			WebhookRequestBody: requestBody,
-			WebhookHeaders:     headers,
+			WebhookURL:         data.URL,
+			WebhookRequestBody: data.RequestBody,
+			WebhookHeaders:     data.Headers,
+
+			WebhookRequestBodyChanged: data.RequestBodyChanged,
 		},
 	}
 
-	config.ExecWebhook(fakeDomains, fakeConfig)
+	if err = config.SendTestNotification(fakeConfig, "webhook"); err != nil {
+		returnError(writer, err.Error())
+		return
+	}
+	returnOK(writer, "ok", nil)
+}
+
+// NotifyTest 使用页面提交的配置, 通过指定的渠道(webhook/mqtt/shutdown)发送测试消息, 返回发送结果
+func NotifyTest(writer http.ResponseWriter, request *http.Request) {
+	var data struct {
+		Channel string
+		config.Config
+	}
+	err := json.NewDecoder(request.Body).Decode(&data)
+	if err != nil {
+		returnError(writer, util.LogStr("数据解析失败, 请刷新页面重试")+": "+err.Error())
+		return
+	}
+
+	// 页面中不显示MQTT密码, 为空时使用保存的值
+	if data.MQTTPassword == "" {
+		oldConf, _ := config.GetConfigCached()
+		data.MQTTPassword = oldConf.MQTTPassword
+	}
+
+	if err = config.SendTestNotification(&data.Config, data.Channel); err != nil {
+		returnError(writer, err.Error())
+		return
+	}
+	returnOK(writer, "ok", nil)
 }
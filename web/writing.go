@@ -24,12 +24,14 @@ type dnsConf4JS struct {
 	DnsID            string
 	DnsSecret        string
 	DnsExtParam      string
+	DnsEndpoint      string
 	TTL              string
 	Ipv4Enable       bool
 	Ipv4GetType      string
 	Ipv4Url          string
 	Ipv4NetInterface string
 	Ipv4Cmd          string
+	Ipv4ManualIP     string
 	Ipv4Domains      string
 	Ipv6Enable       bool
 	Ipv6GetType      string
@@ -37,6 +39,7 @@ type dnsConf4JS struct {
 	Ipv6NetInterface string
 	Ipv6Cmd          string
 	Ipv6Reg          string
+	Ipv6ManualIP     string
 	Ipv6Domains      string
 }
 
@@ -71,8 +74,8 @@ func Writing(writer http.ResponseWriter, request *http.Request) {
 		Username:          conf.User.Username,
 		Webhook:           conf.Webhook,
 		Version:           os.Getenv(VersionEnv),
-		Ipv4:              ipv4,
-		Ipv6:              ipv6,
+		Ipv4:              conf.PreferNetInterfaces(ipv4),
+		Ipv6:              conf.PreferNetInterfaces(ipv6),
 	})
 	if err != nil {
 		fmt.Println("Error happened..")
@@ -91,12 +94,14 @@ func getDnsConfStr(dnsConf []config.DnsConfig) string {
 			DnsID:            idHide,
 			DnsSecret:        secretHide,
 			DnsExtParam:      conf.DNS.ExtParam,
+			DnsEndpoint:      conf.DNS.Endpoint,
 			TTL:              conf.TTL,
 			Ipv4Enable:       conf.Ipv4.Enable,
 			Ipv4GetType:      conf.Ipv4.GetType,
 			Ipv4Url:          conf.Ipv4.URL,
 			Ipv4NetInterface: conf.Ipv4.NetInterface,
 			Ipv4Cmd:          conf.Ipv4.Cmd,
+			Ipv4ManualIP:     conf.Ipv4.ManualIP,
 			Ipv4Domains:      strings.Join(conf.Ipv4.Domains, "\r\n"),
 			Ipv6Enable:       conf.Ipv6.Enable,
 			Ipv6GetType:      conf.Ipv6.GetType,
@@ -104,6 +109,7 @@ func getDnsConfStr(dnsConf []config.DnsConfig) string {
 			Ipv6NetInterface: conf.Ipv6.NetInterface,
 			Ipv6Cmd:          conf.Ipv6.Cmd,
 			Ipv6Reg:          conf.Ipv6.Ipv6Reg,
+			Ipv6ManualIP:     conf.Ipv6.ManualIP,
 			Ipv6Domains:      strings.Join(conf.Ipv6.Domains, "\r\n"),
 		})
 	}
@@ -116,7 +122,8 @@ const displayCount int = 3
 
 // hideIDSecret 隐藏真实的ID、Secret
 func getHideIDSecret(conf *config.DnsConfig) (idHide string, secretHide string) {
-	if len(conf.DNS.ID) > displayCount && conf.DNS.Name != "callback" {
+	// 外部程序的ID为命令, nsupdate 的ID为密钥名称, 不隐藏
+	if len(conf.DNS.ID) > displayCount && conf.DNS.Name != "callback" && conf.DNS.Name != "external" && conf.DNS.Name != "nsupdate" {
 		idHide = conf.DNS.ID[:displayCount] + strings.Repeat("*", len(conf.DNS.ID)-displayCount)
 	} else {
 		idHide = conf.DNS.ID